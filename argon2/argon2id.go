@@ -1,8 +1,11 @@
-// Package argon2 contains the Argon2id hasher implementation.
+// Package argon2 contains the Argon2id hasher implementation and an in-module
+// RFC 9106 engine supporting the optional secret value and associated data.
 package argon2
 
 import (
 	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base64"
 	"fmt"
 	"strconv"
 
@@ -14,13 +17,42 @@ import (
 	"github.com/allisson/go-pwdhash/internal/zero"
 )
 
+// Backend selects the Argon2 implementation used by Argon2idHasher.
+type Backend int
+
+const (
+	// BackendXCrypto derives keys with golang.org/x/crypto/argon2.
+	BackendXCrypto Backend = iota
+	// BackendNative derives keys with the in-module engine, which supports a
+	// secret value and associated data and wipes its block memory after use.
+	BackendNative
+)
+
 // Argon2idHasher wraps parameterized Argon2id hashing operations.
+//
+// Secret and Data map to the Argon2 secret value K and associated data X. They
+// require BackendNative and are recorded through the PHC keyid and data
+// parameters; the secret itself is never written into the hash.
+//
+// Format controls the rendering of new hashes; hashes in any supported format
+// are accepted by Verify and NeedsRehash.
+//
+// Normalization is applied to the password before hashing and recorded as the
+// n PHC parameter. Verify applies whatever the stored hash records, so hashes
+// made without normalization keep verifying over the raw bytes and are
+// reported by NeedsRehash.
 type Argon2idHasher struct {
-	Memory      uint32
-	Iterations  uint32
-	Parallelism uint8
-	SaltLength  uint32
-	KeyLength   uint32
+	Memory        uint32
+	Iterations    uint32
+	Parallelism   uint8
+	SaltLength    uint32
+	KeyLength     uint32
+	Backend       Backend
+	Secret        []byte
+	KeyID         []byte
+	Data          []byte
+	Format        Format
+	Normalization Normalization
 }
 
 // Default returns an Argon2idHasher configured with library defaults.
@@ -41,12 +73,50 @@ func (a *Argon2idHasher) ID() string {
 
 // Hash derives an Argon2id key, zeroizes inputs, and returns the PHC string.
 func (a *Argon2idHasher) Hash(password []byte) (string, error) {
+	return a.hash(password, nil)
+}
+
+// HashWithAD derives an Argon2id key bound to ad, such as an account identifier.
+//
+// The associated data is mixed into the salt and recorded as the ad=1 PHC
+// parameter, so the resulting hash only verifies through VerifyWithAD with the
+// same associated data.
+func (a *Argon2idHasher) HashWithAD(password, ad []byte) (string, error) {
+	if ad == nil {
+		ad = []byte{}
+	}
+
+	return a.hash(password, ad)
+}
+
+// Verify recomputes the Argon2id hash, zeroizes temporaries, and compares it in constant time.
+func (a *Argon2idHasher) Verify(password []byte, encoded string) (bool, error) {
+	return a.verify(password, nil, encoded)
+}
+
+// VerifyWithAD checks a hash produced by HashWithAD against the same associated data.
+func (a *Argon2idHasher) VerifyWithAD(password, ad []byte, encoded string) (bool, error) {
+	if ad == nil {
+		ad = []byte{}
+	}
+
+	return a.verify(password, ad, encoded)
+}
+
+func (a *Argon2idHasher) hash(password, ad []byte) (string, error) {
 	if err := a.validate(); err != nil {
 		return "", err
 	}
 
 	defer zero.Bytes(password)
 
+	normalized, err := a.Normalization.normalize(password)
+	if err != nil {
+		return "", err
+	}
+
+	defer zero.Bytes(normalized)
+
 	salt := make([]byte, a.SaltLength)
 	if _, err := rand.Read(salt); err != nil {
 		return "", err
@@ -54,14 +124,24 @@ func (a *Argon2idHasher) Hash(password []byte) (string, error) {
 
 	defer zero.Bytes(salt)
 
-	key := argon2.IDKey(
-		password,
-		salt,
-		a.Iterations,
-		a.Memory,
-		a.Parallelism,
-		a.KeyLength,
-	)
+	bound := bindSalt(salt, ad)
+	defer zero.Bytes(bound)
+
+	key, err := a.derive(Input{
+		Variant:     Argon2id,
+		Version:     Version13,
+		Password:    normalized,
+		Salt:        bound,
+		Secret:      a.Secret,
+		Data:        a.Data,
+		Memory:      a.Memory,
+		Iterations:  a.Iterations,
+		Parallelism: a.Parallelism,
+		KeyLength:   a.KeyLength,
+	})
+	if err != nil {
+		return "", err
+	}
 
 	defer zero.Bytes(key)
 
@@ -77,18 +157,30 @@ func (a *Argon2idHasher) Hash(password []byte) (string, error) {
 		Hash: key,
 	}
 
-	return enc.String(), nil
+	if ad != nil {
+		enc.Params["ad"] = "1"
+	}
+	if param, ok := normalizationParams[a.Normalization]; ok {
+		enc.Params["n"] = param
+	}
+	if len(a.KeyID) > 0 {
+		enc.Params["keyid"] = base64.RawStdEncoding.EncodeToString(a.KeyID)
+	}
+	if len(a.Data) > 0 {
+		enc.Params["data"] = base64.RawStdEncoding.EncodeToString(a.Data)
+	}
+
+	return a.Format.encode(enc), nil
 }
 
-// Verify recomputes the Argon2id hash, zeroizes temporaries, and compares it in constant time.
-func (a *Argon2idHasher) Verify(password []byte, encoded string) (bool, error) {
+func (a *Argon2idHasher) verify(password, ad []byte, encoded string) (bool, error) {
 	if err := a.validate(); err != nil {
 		return false, err
 	}
 
 	defer zero.Bytes(password)
 
-	parsed, err := encoding.Parse(encoded)
+	parsed, err := encoding.Parse(unwrapFormat(encoded))
 	if err != nil {
 		return false, err
 	}
@@ -100,38 +192,67 @@ func (a *Argon2idHasher) Verify(password []byte, encoded string) (bool, error) {
 		return false, nil
 	}
 
-	if parsed.Version != argon2.Version {
-		return false, fmt.Errorf("unsupported argon2 version")
+	version, err := versionOf(parsed)
+	if err != nil {
+		return false, err
 	}
 
-	mem, err := cast.ConvertStringToUint32(parsed.Params["m"])
+	_, bound := parsed.Params["ad"]
+	if bound && ad == nil {
+		return false, fmt.Errorf("argon2 hash requires associated data")
+	}
+	if !bound && ad != nil {
+		return false, fmt.Errorf("argon2 hash is not bound to associated data")
+	}
+
+	in, err := costOf(parsed)
 	if err != nil {
 		return false, err
 	}
 
-	it, err := cast.ConvertStringToUint32(parsed.Params["t"])
+	if err := checkLimits(in); err != nil {
+		return false, err
+	}
+
+	var secret []byte
+	if keyID, ok := parsed.Params["keyid"]; ok {
+		if len(a.Secret) == 0 || keyID != base64.RawStdEncoding.EncodeToString(a.KeyID) {
+			return false, fmt.Errorf("unknown argon2 key id")
+		}
+		secret = a.Secret
+	}
+
+	data, err := dataOf(parsed)
 	if err != nil {
 		return false, err
 	}
 
-	par, err := cast.ConvertStringToUint8(parsed.Params["p"])
+	normalization, err := normalizationOf(parsed.Params)
 	if err != nil {
 		return false, err
 	}
 
-	keyLen, err := cast.ConvertIntToUint32(len(parsed.Hash))
+	normalized, err := normalization.normalize(password)
 	if err != nil {
 		return false, err
 	}
 
-	key := argon2.IDKey(
-		password,
-		parsed.Salt,
-		it,
-		mem,
-		par,
-		keyLen,
-	)
+	defer zero.Bytes(normalized)
+
+	salt := bindSalt(parsed.Salt, ad)
+	defer zero.Bytes(salt)
+
+	in.Variant = Argon2id
+	in.Version = version
+	in.Password = normalized
+	in.Salt = salt
+	in.Secret = secret
+	in.Data = data
+
+	key, err := a.derive(in)
+	if err != nil {
+		return false, err
+	}
 
 	defer zero.Bytes(key)
 
@@ -140,7 +261,7 @@ func (a *Argon2idHasher) Verify(password []byte, encoded string) (bool, error) {
 
 // NeedsRehash reports whether the encoded parameters diverge from the current configuration.
 func (a *Argon2idHasher) NeedsRehash(encoded string) (bool, error) {
-	parsed, err := encoding.Parse(encoded)
+	parsed, err := encoding.Parse(unwrapFormat(encoded))
 	if err != nil {
 		return false, err
 	}
@@ -149,6 +270,10 @@ func (a *Argon2idHasher) NeedsRehash(encoded string) (bool, error) {
 		return true, nil
 	}
 
+	if parsed.Version != Version13 {
+		return true, nil
+	}
+
 	if parsed.Params["m"] != fmt.Sprint(a.Memory) {
 		return true, nil
 	}
@@ -157,9 +282,32 @@ func (a *Argon2idHasher) NeedsRehash(encoded string) (bool, error) {
 		return true, nil
 	}
 
+	if parsed.Params["keyid"] != base64.RawStdEncoding.EncodeToString(a.KeyID) {
+		return true, nil
+	}
+
+	if parsed.Params["data"] != base64.RawStdEncoding.EncodeToString(a.Data) {
+		return true, nil
+	}
+
+	if parsed.Params["n"] != normalizationParams[a.Normalization] {
+		return true, nil
+	}
+
 	return false, nil
 }
 
+// derive computes the Argon2id key with x/crypto when possible, falling back to
+// the native engine when it is selected or the input needs a secret, associated
+// data, or the legacy 0x10 version.
+func (a *Argon2idHasher) derive(in Input) ([]byte, error) {
+	if a.Backend == BackendXCrypto && in.Version == Version13 && len(in.Secret) == 0 && len(in.Data) == 0 {
+		return argon2.IDKey(in.Password, in.Salt, in.Iterations, in.Memory, in.Parallelism, in.KeyLength), nil
+	}
+
+	return Derive(in)
+}
+
 func (a *Argon2idHasher) validate() error {
 	if a.Memory < MinMemory {
 		return fmt.Errorf("argon2 memory too low")
@@ -179,5 +327,76 @@ func (a *Argon2idHasher) validate() error {
 	if a.Parallelism > MaxParallelism {
 		return fmt.Errorf("argon2 parallelism too high")
 	}
+	if (len(a.Secret) > 0 || len(a.Data) > 0) && a.Backend != BackendNative {
+		return fmt.Errorf("argon2 secret and data require the native backend")
+	}
+	if len(a.Secret) > 0 && len(a.KeyID) == 0 {
+		return fmt.Errorf("argon2 secret requires a key id")
+	}
 	return nil
 }
+
+// bindSalt appends a digest of ad to a copy of salt; a nil ad leaves the salt unbound.
+func bindSalt(salt, ad []byte) []byte {
+	bound := append([]byte(nil), salt...)
+	if ad == nil {
+		return bound
+	}
+
+	digest := sha256.Sum256(ad)
+
+	return append(bound, digest[:]...)
+}
+
+// versionOf maps the PHC version to an engine version; a missing v= segment
+// denotes the original 0x10 release.
+func versionOf(parsed *encoding.EncodedHash) (uint32, error) {
+	switch parsed.Version {
+	case 0, Version10:
+		return Version10, nil
+	case Version13:
+		return Version13, nil
+	}
+
+	return 0, fmt.Errorf("unsupported argon2 version")
+}
+
+// costOf extracts the memory, iteration, parallelism, and key length inputs.
+func costOf(parsed *encoding.EncodedHash) (Input, error) {
+	mem, err := cast.ConvertStringToUint32(parsed.Params["m"])
+	if err != nil {
+		return Input{}, err
+	}
+
+	it, err := cast.ConvertStringToUint32(parsed.Params["t"])
+	if err != nil {
+		return Input{}, err
+	}
+
+	par, err := cast.ConvertStringToUint8(parsed.Params["p"])
+	if err != nil {
+		return Input{}, err
+	}
+
+	keyLen, err := cast.ConvertIntToUint32(len(parsed.Hash))
+	if err != nil {
+		return Input{}, err
+	}
+
+	return Input{
+		Memory:      mem,
+		Iterations:  it,
+		Parallelism: par,
+		KeyLength:   keyLen,
+	}, nil
+}
+
+// dataOf decodes the optional PHC data parameter.
+func dataOf(parsed *encoding.EncodedHash) ([]byte, error) {
+	encoded, ok := parsed.Params["data"]
+	if !ok {
+		return nil, nil
+	}
+
+	return base64.RawStdEncoding.DecodeString(encoded)
+}
@@ -130,6 +130,27 @@ func TestArgon2idHasher_VerifyErrors(t *testing.T) {
 			},
 			expectErr: true,
 		},
+		{
+			name: "excessiveMemory",
+			mutator: func(s string) string {
+				return strings.Replace(s, "m=65536", "m=4194304", 1)
+			},
+			expectErr: true,
+		},
+		{
+			name: "excessiveIterations",
+			mutator: func(s string) string {
+				return strings.Replace(s, "t=3", "t=1000", 1)
+			},
+			expectErr: true,
+		},
+		{
+			name: "excessiveParallelism",
+			mutator: func(s string) string {
+				return strings.Replace(s, "p=4", "p=255", 1)
+			},
+			expectErr: true,
+		},
 		{
 			name: "invalidHashLength",
 			mutator: func(s string) string {
@@ -162,6 +183,169 @@ func TestArgon2idHasher_VerifyErrors(t *testing.T) {
 	}
 }
 
+func TestVerify_RejectsInvalidParams(t *testing.T) {
+	const (
+		salt = "c2FsdHNhbHRzYWx0"
+		hash = "AAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAA"
+	)
+
+	tests := []struct {
+		name    string
+		params  string
+		hash    string
+		wantErr string
+	}{
+		{name: "zeroParallelism", params: "m=65536,t=3,p=0", hash: hash, wantErr: "parallelism too low"},
+		{name: "zeroIterations", params: "m=65536,t=0,p=4", hash: hash, wantErr: "iterations too low"},
+		{name: "memoryBelowLanes", params: "m=16,t=3,p=4", hash: hash, wantErr: "memory too low"},
+		{name: "emptyHash", params: "m=65536,t=3,p=4", hash: "", wantErr: "hash too short"},
+		{name: "shortHash", params: "m=65536,t=3,p=4", hash: "ZGVmZGVm", wantErr: "hash too short"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			encoded := "$argon2id$v=19$" + tt.params + "$" + salt + "$" + tt.hash
+			_, err := Default().Verify([]byte("password"), encoded)
+			require.ErrorContains(t, err, tt.wantErr)
+
+			native := Default()
+			native.Backend = BackendNative
+			_, err = native.Verify([]byte("password"), encoded)
+			require.ErrorContains(t, err, tt.wantErr)
+
+			encoded = "$argon2i$v=19$" + tt.params + "$" + salt + "$" + tt.hash
+			_, err = NewLegacyVerifier(Argon2i).Verify([]byte("password"), encoded)
+			require.ErrorContains(t, err, tt.wantErr)
+		})
+	}
+}
+
+func TestArgon2idHasher_HashWithADBindsHash(t *testing.T) {
+	hasher := newTestHasher()
+
+	encoded, err := hasher.HashWithAD([]byte("password"), []byte("user-1"))
+	require.NoError(t, err)
+	require.Contains(t, encoded, "ad=1")
+
+	ok, err := hasher.VerifyWithAD([]byte("password"), []byte("user-1"), encoded)
+	require.NoError(t, err)
+	require.True(t, ok)
+
+	ok, err = hasher.VerifyWithAD([]byte("password"), []byte("admin"), encoded)
+	require.NoError(t, err)
+	require.False(t, ok)
+
+	ok, err = hasher.Verify([]byte("password"), encoded)
+	require.Error(t, err)
+	require.False(t, ok)
+}
+
+func TestArgon2idHasher_VerifyWithADRejectsUnboundHash(t *testing.T) {
+	hasher := newTestHasher()
+
+	encoded, err := hasher.Hash([]byte("password"))
+	require.NoError(t, err)
+
+	ok, err := hasher.VerifyWithAD([]byte("password"), []byte("user-1"), encoded)
+	require.Error(t, err)
+	require.False(t, ok)
+}
+
+func TestArgon2idHasher_NativeBackendMatchesXCrypto(t *testing.T) {
+	hasher := newTestHasher()
+
+	encoded, err := hasher.Hash([]byte("password"))
+	require.NoError(t, err)
+
+	native := newTestHasher()
+	native.Backend = BackendNative
+
+	ok, err := native.Verify([]byte("password"), encoded)
+	require.NoError(t, err)
+	require.True(t, ok)
+}
+
+func TestArgon2idHasher_SecretAndData(t *testing.T) {
+	hasher := newTestHasher()
+	hasher.Backend = BackendNative
+	hasher.Secret = []byte("pepper-v1")
+	hasher.KeyID = []byte("v1")
+	hasher.Data = []byte("tenant-a")
+
+	encoded, err := hasher.Hash([]byte("password"))
+	require.NoError(t, err)
+	require.Contains(t, encoded, "keyid=djE")
+	require.Contains(t, encoded, "data=dGVuYW50LWE")
+	require.NotContains(t, encoded, "cGVwcGVy")
+
+	ok, err := hasher.Verify([]byte("password"), encoded)
+	require.NoError(t, err)
+	require.True(t, ok)
+
+	needs, err := hasher.NeedsRehash(encoded)
+	require.NoError(t, err)
+	require.False(t, needs)
+
+	rotated := newTestHasher()
+	rotated.Backend = BackendNative
+	rotated.Secret = []byte("pepper-v2")
+	rotated.KeyID = []byte("v2")
+
+	_, err = rotated.Verify([]byte("password"), encoded)
+	require.Error(t, err)
+
+	needs, err = rotated.NeedsRehash(encoded)
+	require.NoError(t, err)
+	require.True(t, needs)
+
+	wrongSecret := newTestHasher()
+	wrongSecret.Backend = BackendNative
+	wrongSecret.Secret = []byte("guessed")
+	wrongSecret.KeyID = []byte("v1")
+
+	ok, err = wrongSecret.Verify([]byte("password"), encoded)
+	require.NoError(t, err)
+	require.False(t, ok)
+}
+
+func TestArgon2idHasher_SecretValidation(t *testing.T) {
+	tests := []struct {
+		name   string
+		mutate func(*Argon2idHasher)
+	}{
+		{
+			name: "secretWithXCrypto",
+			mutate: func(h *Argon2idHasher) {
+				h.Secret = []byte("pepper")
+				h.KeyID = []byte("v1")
+			},
+		},
+		{
+			name: "dataWithXCrypto",
+			mutate: func(h *Argon2idHasher) {
+				h.Data = []byte("tenant")
+			},
+		},
+		{
+			name: "secretWithoutKeyID",
+			mutate: func(h *Argon2idHasher) {
+				h.Backend = BackendNative
+				h.Secret = []byte("pepper")
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			hasher := newTestHasher()
+			tt.mutate(hasher)
+
+			_, err := hasher.Hash([]byte("password"))
+			require.Error(t, err)
+		})
+	}
+}
+
 func TestArgon2idHasher_NeedsRehashParameterChange(t *testing.T) {
 	hasher := newTestHasher()
 
@@ -192,6 +376,132 @@ func TestArgon2idHasher_NeedsRehashAlgorithmChange(t *testing.T) {
 	require.True(t, needs)
 }
 
+func TestArgon2idHasher_Formats(t *testing.T) {
+	tests := []struct {
+		name   string
+		format Format
+		prefix string
+	}{
+		{name: "phc", format: FormatPHC, prefix: "$argon2id$v=19$m=65536,p=4,t=3$"},
+		{name: "php", format: FormatPHP, prefix: "$argon2id$v=19$m=65536,t=3,p=4$"},
+		{name: "django", format: FormatDjango, prefix: "argon2$argon2id$v=19$m=65536,t=3,p=4$"},
+		{name: "spring", format: FormatSpring, prefix: "{argon2}$argon2id$v=19$m=65536,t=3,p=4$"},
+		{name: "dovecot", format: FormatDovecot, prefix: "{ARGON2ID}$argon2id$v=19$m=65536,t=3,p=4$"},
+		{name: "openldap", format: FormatOpenLDAP, prefix: "{ARGON2}$argon2id$v=19$m=65536,t=3,p=4$"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			hasher := newTestHasher()
+			hasher.Format = tt.format
+
+			encoded, err := hasher.Hash([]byte("password"))
+			require.NoError(t, err)
+			require.True(t, strings.HasPrefix(encoded, tt.prefix), encoded)
+			require.True(t, hasher.Recognize(encoded))
+
+			// A hasher emitting plain PHC still accepts every format.
+			reader := newTestHasher()
+
+			ok, err := reader.Verify([]byte("password"), encoded)
+			require.NoError(t, err)
+			require.True(t, ok)
+
+			ok, err = reader.Verify([]byte("wrong"), encoded)
+			require.NoError(t, err)
+			require.False(t, ok)
+
+			needs, err := reader.NeedsRehash(encoded)
+			require.NoError(t, err)
+			require.False(t, needs)
+		})
+	}
+}
+
+func TestArgon2idHasher_Recognize(t *testing.T) {
+	hasher := newTestHasher()
+
+	require.False(t, hasher.Recognize("argon2$argon2i$v=19$m=65536,t=2,p=1$c29tZXNhbHQ$wWKIMhR9lyDFvRz9YTZweHKfbftvj+qf+YFY4NeBbtA"))
+	require.False(t, hasher.Recognize("{SSHA}yrht1iYXEIkejLVu42JWkadd80RzYWx0c2FsdA=="))
+	require.False(t, hasher.Recognize("argon2"))
+	require.True(t, NewLegacyVerifier(Argon2i).Recognize("argon2$argon2i$v=19$m=65536,t=2,p=1$c29tZXNhbHQ$wWKIMhR9lyDFvRz9YTZweHKfbftvj+qf+YFY4NeBbtA"))
+}
+
+func TestArgon2idHasher_Normalization(t *testing.T) {
+	composed := "caf\u00e9 \u00c5ngstr\u00f6m"
+	decomposed := "cafe\u0301 A\u030angstro\u0308m"
+
+	tests := []struct {
+		name          string
+		normalization Normalization
+		param         string
+		typed         string
+	}{
+		{name: "nfkc", normalization: NormalizationNFKC, param: "n=nfkc", typed: "\uff43af\u00e9 \u00c5ngstr\u00f6m"},
+		{name: "opaque", normalization: NormalizationOpaqueString, param: "n=opaque", typed: "cafe\u0301\u00a0A\u030angstro\u0308m"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			hasher := newTestHasher()
+			hasher.Normalization = tt.normalization
+
+			encoded, err := hasher.Hash([]byte(composed))
+			require.NoError(t, err)
+			require.Contains(t, encoded, tt.param)
+
+			// The stored parameter drives verification, not the verifier's setting.
+			for _, password := range []string{composed, decomposed, tt.typed} {
+				ok, err := newTestHasher().Verify([]byte(password), encoded)
+				require.NoError(t, err)
+				require.True(t, ok, password)
+			}
+
+			needs, err := hasher.NeedsRehash(encoded)
+			require.NoError(t, err)
+			require.False(t, needs)
+
+			needs, err = newTestHasher().NeedsRehash(encoded)
+			require.NoError(t, err)
+			require.True(t, needs)
+		})
+	}
+}
+
+func TestArgon2idHasher_NormalizationRawFallback(t *testing.T) {
+	raw, err := newTestHasher().Hash([]byte("cafe\u0301"))
+	require.NoError(t, err)
+
+	hasher := newTestHasher()
+	hasher.Normalization = NormalizationNFKC
+
+	ok, err := hasher.Verify([]byte("cafe\u0301"), raw)
+	require.NoError(t, err)
+	require.True(t, ok)
+
+	ok, err = hasher.Verify([]byte("caf\u00e9"), raw)
+	require.NoError(t, err)
+	require.False(t, ok)
+
+	needs, err := hasher.NeedsRehash(raw)
+	require.NoError(t, err)
+	require.True(t, needs)
+}
+
+func TestArgon2idHasher_NormalizationErrors(t *testing.T) {
+	hasher := newTestHasher()
+	hasher.Normalization = NormalizationOpaqueString
+
+	_, err := hasher.Hash([]byte("tab\tseparated"))
+	require.Error(t, err)
+
+	encoded, err := newTestHasher().Hash([]byte("password"))
+	require.NoError(t, err)
+
+	_, err = hasher.Verify([]byte("password"), strings.Replace(encoded, "$m=", "$n=rot13,m=", 1))
+	require.Error(t, err)
+}
+
 func TestArgon2idHasher_HashZeroizesPassword(t *testing.T) {
 	hasher := newTestHasher()
 	password := []byte("topsecret")
@@ -0,0 +1,400 @@
+package argon2
+
+import (
+	"encoding/binary"
+	"errors"
+	"hash"
+	"sync"
+
+	"golang.org/x/crypto/blake2b"
+
+	"github.com/allisson/go-pwdhash/internal/zero"
+)
+
+// Variant identifies the Argon2 flavour defined by RFC 9106.
+type Variant uint32
+
+const (
+	// Argon2d uses data-dependent memory access.
+	Argon2d Variant = 0
+	// Argon2i uses data-independent memory access.
+	Argon2i Variant = 1
+	// Argon2id mixes data-independent and data-dependent memory access.
+	Argon2id Variant = 2
+)
+
+// String reports the PHC algorithm identifier for the variant.
+func (v Variant) String() string {
+	switch v {
+	case Argon2d:
+		return "argon2d"
+	case Argon2i:
+		return "argon2i"
+	case Argon2id:
+		return "argon2id"
+	}
+
+	return "unknown"
+}
+
+const (
+	// Version10 is the original Argon2 release (0x10), which overwrites blocks on later passes.
+	Version10 = 0x10
+	// Version13 is the current Argon2 release (0x13) standardized by RFC 9106.
+	Version13 = 0x13
+)
+
+const (
+	blockWords = 128
+	syncPoints = 4
+)
+
+type block [blockWords]uint64
+
+// Input collects the Argon2 inputs defined by RFC 9106 section 3.1.
+//
+// Secret is the optional secret value K and Data the optional associated data X;
+// neither is exposed by golang.org/x/crypto/argon2.
+type Input struct {
+	Variant     Variant
+	Version     uint32
+	Password    []byte
+	Salt        []byte
+	Secret      []byte
+	Data        []byte
+	Memory      uint32
+	Iterations  uint32
+	Parallelism uint8
+	KeyLength   uint32
+}
+
+// Derive runs the in-module Argon2 engine and wipes its block memory before returning.
+func Derive(in Input) ([]byte, error) {
+	if in.Variant > Argon2id {
+		return nil, errors.New("argon2 variant unknown")
+	}
+	if in.Version != Version10 && in.Version != Version13 {
+		return nil, errors.New("unsupported argon2 version")
+	}
+	if in.Iterations < 1 {
+		return nil, errors.New("argon2 iterations too low")
+	}
+	if in.Parallelism < 1 {
+		return nil, errors.New("argon2 parallelism too low")
+	}
+	if in.KeyLength < 4 {
+		return nil, errors.New("argon2 key length too short")
+	}
+
+	lanes := uint32(in.Parallelism)
+
+	memory := in.Memory / (syncPoints * lanes) * (syncPoints * lanes)
+	if memory < 2*syncPoints*lanes {
+		memory = 2 * syncPoints * lanes
+	}
+
+	h0 := initialHash(in)
+	defer zero.Bytes(h0[:])
+
+	blocks := make([]block, memory)
+	defer wipeBlocks(blocks)
+
+	e := engine{
+		blocks:     blocks,
+		variant:    in.Variant,
+		version:    in.Version,
+		iterations: in.Iterations,
+		memory:     memory,
+		lanes:      lanes,
+		laneLength: memory / lanes,
+	}
+	e.segmentLength = e.laneLength / syncPoints
+
+	e.fillFirstBlocks(&h0)
+	e.fillMemory()
+
+	return e.finalize(in.KeyLength), nil
+}
+
+type engine struct {
+	blocks        []block
+	variant       Variant
+	version       uint32
+	iterations    uint32
+	memory        uint32
+	lanes         uint32
+	laneLength    uint32
+	segmentLength uint32
+}
+
+func initialHash(in Input) [blake2b.Size + 8]byte {
+	var h0 [blake2b.Size + 8]byte
+
+	h, _ := blake2b.New512(nil)
+	writeUint32(h, uint32(in.Parallelism))
+	writeUint32(h, in.KeyLength)
+	writeUint32(h, in.Memory)
+	writeUint32(h, in.Iterations)
+	writeUint32(h, in.Version)
+	writeUint32(h, uint32(in.Variant))
+	for _, field := range [][]byte{in.Password, in.Salt, in.Secret, in.Data} {
+		writeUint32(h, uint32(len(field))) // #nosec G115 -- inputs are bounded by memory
+		h.Write(field)
+	}
+	h.Sum(h0[:0])
+
+	return h0
+}
+
+func (e *engine) fillFirstBlocks(h0 *[blake2b.Size + 8]byte) {
+	var buf [blockWords * 8]byte
+	defer zero.Bytes(buf[:])
+
+	for lane := uint32(0); lane < e.lanes; lane++ {
+		start := lane * e.laneLength
+		binary.LittleEndian.PutUint32(h0[blake2b.Size+4:], lane)
+
+		for i := uint32(0); i < 2; i++ {
+			binary.LittleEndian.PutUint32(h0[blake2b.Size:], i)
+			variableHash(buf[:], h0[:])
+			for w := range e.blocks[start+i] {
+				e.blocks[start+i][w] = binary.LittleEndian.Uint64(buf[w*8:])
+			}
+		}
+	}
+}
+
+func (e *engine) fillMemory() {
+	for pass := uint32(0); pass < e.iterations; pass++ {
+		for slice := uint32(0); slice < syncPoints; slice++ {
+			var wg sync.WaitGroup
+			for lane := uint32(0); lane < e.lanes; lane++ {
+				wg.Add(1)
+				go func(lane uint32) {
+					defer wg.Done()
+					e.fillSegment(pass, slice, lane)
+				}(lane)
+			}
+			wg.Wait()
+		}
+	}
+}
+
+func (e *engine) dataIndependent(pass, slice uint32) bool {
+	return e.variant == Argon2i || (e.variant == Argon2id && pass == 0 && slice < syncPoints/2)
+}
+
+func (e *engine) fillSegment(pass, slice, lane uint32) {
+	var addresses, input, empty block
+	defer wipeBlock(&addresses)
+	defer wipeBlock(&input)
+
+	independent := e.dataIndependent(pass, slice)
+	if independent {
+		input[0] = uint64(pass)
+		input[1] = uint64(lane)
+		input[2] = uint64(slice)
+		input[3] = uint64(e.memory)
+		input[4] = uint64(e.iterations)
+		input[5] = uint64(e.variant)
+	}
+
+	index := uint32(0)
+	if pass == 0 && slice == 0 {
+		index = 2
+		if independent {
+			nextAddresses(&addresses, &input, &empty)
+		}
+	}
+
+	offset := lane*e.laneLength + slice*e.segmentLength + index
+	for ; index < e.segmentLength; index, offset = index+1, offset+1 {
+		prev := offset - 1
+		if index == 0 && slice == 0 {
+			prev += e.laneLength
+		}
+
+		var random uint64
+		if independent {
+			if index%blockWords == 0 {
+				nextAddresses(&addresses, &input, &empty)
+			}
+			random = addresses[index%blockWords]
+		} else {
+			random = e.blocks[prev][0]
+		}
+
+		ref := e.referenceIndex(random, pass, slice, lane, index)
+		xor := pass > 0 && e.version == Version13
+		compress(&e.blocks[offset], &e.blocks[prev], &e.blocks[ref], xor)
+	}
+}
+
+// referenceIndex maps the pseudo-random value to a block per RFC 9106 section 3.4.1.2.
+func (e *engine) referenceIndex(random uint64, pass, slice, lane, index uint32) uint32 {
+	refLane := uint32(random>>32) % e.lanes
+	if pass == 0 && slice == 0 {
+		refLane = lane
+	}
+
+	area, start := 3*e.segmentLength, ((slice+1)%syncPoints)*e.segmentLength
+	if lane == refLane {
+		area += index
+	}
+	if pass == 0 {
+		area, start = slice*e.segmentLength, 0
+		if slice == 0 || lane == refLane {
+			area += index
+		}
+	}
+	if index == 0 || lane == refLane {
+		area--
+	}
+
+	x := random & 0xFFFFFFFF
+	x = (x * x) >> 32
+	x = (x * uint64(area)) >> 32
+	relative := (uint64(start) + uint64(area) - (x + 1)) % uint64(e.laneLength)
+
+	return refLane*e.laneLength + uint32(relative) // #nosec G115 -- bounded by laneLength
+}
+
+func (e *engine) finalize(keyLength uint32) []byte {
+	last := e.blocks[e.laneLength-1]
+	defer wipeBlock(&last)
+	for lane := uint32(1); lane < e.lanes; lane++ {
+		for i, w := range e.blocks[lane*e.laneLength+e.laneLength-1] {
+			last[i] ^= w
+		}
+	}
+
+	var buf [blockWords * 8]byte
+	for i, w := range last {
+		binary.LittleEndian.PutUint64(buf[i*8:], w)
+	}
+
+	key := make([]byte, keyLength)
+	variableHash(key, buf[:])
+
+	zero.Bytes(buf[:])
+
+	return key
+}
+
+func nextAddresses(addresses, input, empty *block) {
+	input[6]++
+	compress(addresses, empty, input, false)
+	compress(addresses, empty, addresses, false)
+}
+
+// compress implements the Argon2 compression function G, optionally XORing into out.
+func compress(out, x, y *block, xor bool) {
+	var r, q block
+	for i := range r {
+		r[i] = x[i] ^ y[i]
+	}
+	q = r
+
+	for i := 0; i < blockWords; i += 16 {
+		permute(&q, i, i+1, i+2, i+3, i+4, i+5, i+6, i+7,
+			i+8, i+9, i+10, i+11, i+12, i+13, i+14, i+15)
+	}
+	for i := 0; i < blockWords/8; i += 2 {
+		permute(&q, i, i+1, i+16, i+17, i+32, i+33, i+48, i+49,
+			i+64, i+65, i+80, i+81, i+96, i+97, i+112, i+113)
+	}
+
+	if xor {
+		for i := range out {
+			out[i] ^= r[i] ^ q[i]
+		}
+		return
+	}
+	for i := range out {
+		out[i] = r[i] ^ q[i]
+	}
+}
+
+// permute applies the BlaMka round P to the sixteen words at the given indices.
+func permute(b *block, i0, i1, i2, i3, i4, i5, i6, i7, i8, i9, i10, i11, i12, i13, i14, i15 int) {
+	mix(b, i0, i4, i8, i12)
+	mix(b, i1, i5, i9, i13)
+	mix(b, i2, i6, i10, i14)
+	mix(b, i3, i7, i11, i15)
+	mix(b, i0, i5, i10, i15)
+	mix(b, i1, i6, i11, i12)
+	mix(b, i2, i7, i8, i13)
+	mix(b, i3, i4, i9, i14)
+}
+
+func mix(b *block, a, c, d, e int) {
+	b[a] = blamka(b[a], b[c])
+	b[e] = rotr(b[e]^b[a], 32)
+	b[d] = blamka(b[d], b[e])
+	b[c] = rotr(b[c]^b[d], 24)
+	b[a] = blamka(b[a], b[c])
+	b[e] = rotr(b[e]^b[a], 16)
+	b[d] = blamka(b[d], b[e])
+	b[c] = rotr(b[c]^b[d], 63)
+}
+
+func blamka(x, y uint64) uint64 {
+	return x + y + 2*uint64(uint32(x))*uint64(uint32(y))
+}
+
+func rotr(x uint64, n uint) uint64 {
+	return x>>n | x<<(64-n)
+}
+
+// variableHash implements H' from RFC 9106 section 3.3.
+func variableHash(out, in []byte) {
+	var h hash.Hash
+	if len(out) < blake2b.Size {
+		h, _ = blake2b.New(len(out), nil)
+	} else {
+		h, _ = blake2b.New512(nil)
+	}
+
+	writeUint32(h, uint32(len(out))) // #nosec G115 -- output lengths are bounded
+	h.Write(in)
+
+	if len(out) <= blake2b.Size {
+		h.Sum(out[:0])
+		return
+	}
+
+	var v [blake2b.Size]byte
+	defer zero.Bytes(v[:])
+
+	h.Sum(v[:0])
+	copy(out, v[:32])
+	rest := out[32:]
+
+	for len(rest) > blake2b.Size {
+		h, _ = blake2b.New512(nil)
+		h.Write(v[:])
+		h.Sum(v[:0])
+		copy(rest, v[:32])
+		rest = rest[32:]
+	}
+
+	h, _ = blake2b.New(len(rest), nil)
+	h.Write(v[:])
+	h.Sum(rest[:0])
+}
+
+func writeUint32(h hash.Hash, v uint32) {
+	var buf [4]byte
+	binary.LittleEndian.PutUint32(buf[:], v)
+	h.Write(buf[:])
+}
+
+func wipeBlocks(blocks []block) {
+	for i := range blocks {
+		wipeBlock(&blocks[i])
+	}
+}
+
+func wipeBlock(b *block) {
+	*b = block{}
+}
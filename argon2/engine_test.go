@@ -0,0 +1,100 @@
+package argon2
+
+import (
+	"bytes"
+	"encoding/hex"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+	xargon2 "golang.org/x/crypto/argon2"
+)
+
+func TestDerive_RFC9106Vectors(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name    string
+		variant Variant
+		want    string
+	}{
+		{name: "argon2d", variant: Argon2d, want: "512b391b6f1162975371d30919734294f868e3be3984f3c1a13a4db9fabe4acb"},
+		{name: "argon2i", variant: Argon2i, want: "c814d9d1dc7f37aa13f0d77f2494bda1c8de6b016dd388d29952a4c4672b6ce8"},
+		{name: "argon2id", variant: Argon2id, want: "0d640df58d78766c08c037a34a8b53c9d01ef0452d75b65eb52520e96b01e659"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			key, err := Derive(Input{
+				Variant:     tt.variant,
+				Version:     Version13,
+				Password:    bytes.Repeat([]byte{0x01}, 32),
+				Salt:        bytes.Repeat([]byte{0x02}, 16),
+				Secret:      bytes.Repeat([]byte{0x03}, 8),
+				Data:        bytes.Repeat([]byte{0x04}, 12),
+				Memory:      32,
+				Iterations:  3,
+				Parallelism: 4,
+				KeyLength:   32,
+			})
+			require.NoError(t, err)
+			require.Equal(t, tt.want, hex.EncodeToString(key))
+		})
+	}
+}
+
+func TestDerive_Version10(t *testing.T) {
+	key, err := Derive(Input{
+		Variant:     Argon2i,
+		Version:     Version10,
+		Password:    []byte("password"),
+		Salt:        []byte("somesalt"),
+		Memory:      64 * 1024,
+		Iterations:  2,
+		Parallelism: 1,
+		KeyLength:   32,
+	})
+	require.NoError(t, err)
+	require.Equal(t, "f6c4db4a54e2a370627aff3db6176b94a2a209a62c8e36152711802f7b30c694", hex.EncodeToString(key))
+}
+
+func TestDerive_MatchesXCrypto(t *testing.T) {
+	password := []byte("password")
+	salt := []byte("0123456789abcdef")
+
+	for _, lanes := range []uint8{1, 3, 4} {
+		key, err := Derive(Input{
+			Variant:     Argon2id,
+			Version:     Version13,
+			Password:    password,
+			Salt:        salt,
+			Memory:      1024,
+			Iterations:  3,
+			Parallelism: lanes,
+			KeyLength:   100,
+		})
+		require.NoError(t, err)
+		require.Equal(t, xargon2.IDKey(password, salt, 3, 1024, lanes, 100), key)
+	}
+}
+
+func TestDerive_InvalidInput(t *testing.T) {
+	tests := []struct {
+		name  string
+		input Input
+	}{
+		{name: "variant", input: Input{Variant: 7, Version: Version13, Iterations: 1, Parallelism: 1, KeyLength: 32}},
+		{name: "version", input: Input{Variant: Argon2id, Version: 0x12, Iterations: 1, Parallelism: 1, KeyLength: 32}},
+		{name: "iterations", input: Input{Variant: Argon2id, Version: Version13, Parallelism: 1, KeyLength: 32}},
+		{name: "parallelism", input: Input{Variant: Argon2id, Version: Version13, Iterations: 1, KeyLength: 32}},
+		{name: "keyLength", input: Input{Variant: Argon2id, Version: Version13, Iterations: 1, Parallelism: 1}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := Derive(tt.input)
+			require.Error(t, err)
+		})
+	}
+}
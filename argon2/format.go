@@ -0,0 +1,72 @@
+package argon2
+
+import (
+	"strings"
+
+	"github.com/allisson/go-pwdhash/internal/encoding"
+)
+
+// Format selects how Argon2idHasher renders its output for storage shared with
+// other frameworks.
+type Format int
+
+const (
+	// FormatPHC emits a PHC string with parameters in alphabetical order.
+	FormatPHC Format = iota
+	// FormatPHP emits a PHC string with parameters in libargon2's m,t,p order,
+	// which PHP's password_verify and other libargon2 decoders require.
+	FormatPHP
+	// FormatDjango emits Django's argon2$argon2id$... encoding.
+	FormatDjango
+	// FormatSpring emits Spring Security's {argon2} DelegatingPasswordEncoder prefix.
+	FormatSpring
+	// FormatDovecot emits Dovecot's {ARGON2ID} scheme prefix.
+	FormatDovecot
+	// FormatOpenLDAP emits the {ARGON2} scheme prefix of OpenLDAP's argon2 module.
+	FormatOpenLDAP
+)
+
+// libargon2Order is the parameter order written by libargon2 and libsodium.
+var libargon2Order = []string{"m", "t", "p", "keyid", "data"}
+
+// formatPrefixes maps each wrapping format to the marker placed before the PHC string.
+var formatPrefixes = map[Format]string{
+	FormatDjango:   "argon2",
+	FormatSpring:   "{argon2}",
+	FormatDovecot:  "{ARGON2ID}",
+	FormatOpenLDAP: "{ARGON2}",
+}
+
+// encode renders enc in the format. Every format except FormatPHC uses the
+// libargon2 parameter order, since the foreign parsers are built on it.
+func (f Format) encode(enc encoding.EncodedHash) string {
+	if f == FormatPHC {
+		return enc.String()
+	}
+
+	return formatPrefixes[f] + enc.StringInOrder(libargon2Order)
+}
+
+// unwrapFormat strips any known framework marker so the PHC body can be parsed.
+func unwrapFormat(encoded string) string {
+	for _, prefix := range formatPrefixes {
+		if rest, ok := strings.CutPrefix(encoded, prefix); ok && strings.HasPrefix(rest, "$argon2") {
+			return rest
+		}
+	}
+
+	return encoded
+}
+
+// Recognize reports whether encoded is an Argon2id hash in any supported format.
+func (a *Argon2idHasher) Recognize(encoded string) bool {
+	parsed, err := encoding.Parse(unwrapFormat(encoded))
+	return err == nil && parsed.Algorithm == a.ID()
+}
+
+// Recognize reports whether encoded is a hash of the legacy variant in any
+// supported format, such as the argon2i hashes older Django releases wrote.
+func (l *LegacyVerifier) Recognize(encoded string) bool {
+	parsed, err := encoding.Parse(unwrapFormat(encoded))
+	return err == nil && parsed.Algorithm == l.ID()
+}
@@ -0,0 +1,160 @@
+package argon2
+
+import (
+	"fmt"
+
+	"github.com/allisson/go-pwdhash/internal/encoding"
+	"github.com/allisson/go-pwdhash/internal/subtle"
+	"github.com/allisson/go-pwdhash/internal/zero"
+)
+
+// LegacyVerifier checks argon2i and argon2d hashes left behind by other
+// libraries, and argon2id hashes in FIPS migration mode. It never produces
+// hashes and always reports that a rehash is needed, so accounts move to the
+// current hasher on their next successful login.
+type LegacyVerifier struct {
+	variant Variant
+}
+
+// NewLegacyVerifier returns a verify-only checker for the given variant.
+func NewLegacyVerifier(variant Variant) *LegacyVerifier {
+	return &LegacyVerifier{variant: variant}
+}
+
+// ID reports the PHC algorithm identifier.
+func (l *LegacyVerifier) ID() string {
+	return l.variant.String()
+}
+
+// Verify recomputes the legacy Argon2 hash with the in-module engine and compares it in constant time.
+func (l *LegacyVerifier) Verify(password []byte, encoded string) (bool, error) {
+	return l.verify(password, nil, encoded)
+}
+
+// VerifyWithAD checks a hash bound to associated data, such as an argon2id
+// hash produced by Argon2idHasher.HashWithAD and verified in FIPS migration mode.
+func (l *LegacyVerifier) VerifyWithAD(password, ad []byte, encoded string) (bool, error) {
+	if ad == nil {
+		ad = []byte{}
+	}
+
+	return l.verify(password, ad, encoded)
+}
+
+func (l *LegacyVerifier) verify(password, ad []byte, encoded string) (bool, error) {
+	defer zero.Bytes(password)
+
+	parsed, err := encoding.Parse(unwrapFormat(encoded))
+	if err != nil {
+		return false, err
+	}
+
+	defer zero.Bytes(parsed.Salt)
+	defer zero.Bytes(parsed.Hash)
+
+	if parsed.Algorithm != l.ID() {
+		return false, nil
+	}
+
+	version, err := versionOf(parsed)
+	if err != nil {
+		return false, err
+	}
+
+	_, bound := parsed.Params["ad"]
+	if bound && ad == nil {
+		return false, fmt.Errorf("argon2 hash requires associated data")
+	}
+	if !bound && ad != nil {
+		return false, fmt.Errorf("argon2 hash is not bound to associated data")
+	}
+
+	if _, ok := parsed.Params["keyid"]; ok {
+		return false, fmt.Errorf("argon2 secret keys are not supported for legacy hashes")
+	}
+
+	in, err := costOf(parsed)
+	if err != nil {
+		return false, err
+	}
+
+	if err := checkLimits(in); err != nil {
+		return false, err
+	}
+
+	data, err := dataOf(parsed)
+	if err != nil {
+		return false, err
+	}
+
+	normalization, err := normalizationOf(parsed.Params)
+	if err != nil {
+		return false, err
+	}
+
+	normalized, err := normalization.normalize(password)
+	if err != nil {
+		return false, err
+	}
+
+	defer zero.Bytes(normalized)
+
+	salt := bindSalt(parsed.Salt, ad)
+	defer zero.Bytes(salt)
+
+	in.Variant = l.variant
+	in.Version = version
+	in.Password = normalized
+	in.Salt = salt
+	in.Data = data
+
+	key, err := Derive(in)
+	if err != nil {
+		return false, err
+	}
+
+	defer zero.Bytes(key)
+
+	return subtle.ConstantTimeCompare(key, parsed.Hash), nil
+}
+
+// NeedsRehash always reports true for well-formed hashes of the legacy variant.
+func (l *LegacyVerifier) NeedsRehash(encoded string) (bool, error) {
+	if _, err := encoding.Parse(unwrapFormat(encoded)); err != nil {
+		return false, err
+	}
+
+	return true, nil
+}
+
+// minKeyLength is the shortest stored hash accepted for verification. Argon2
+// allows 4-byte tags, but nothing this short is written in practice and a
+// truncated tag makes false matches likely.
+const minKeyLength = 16
+
+// checkLimits rejects stored parameters that Argon2 does not allow or that
+// would make verification unreasonably expensive, before either backend runs.
+func checkLimits(in Input) error {
+	if in.Iterations < 1 {
+		return fmt.Errorf("argon2 iterations too low")
+	}
+	if in.Parallelism < 1 {
+		return fmt.Errorf("argon2 parallelism too low")
+	}
+	if in.Memory < 8*uint32(in.Parallelism) {
+		return fmt.Errorf("argon2 memory too low")
+	}
+	if in.Memory > MaxMemory {
+		return fmt.Errorf("argon2 memory too high")
+	}
+	if in.Iterations > MaxIterations {
+		return fmt.Errorf("argon2 iterations too high")
+	}
+	if in.Parallelism > MaxParallelism {
+		return fmt.Errorf("argon2 parallelism too high")
+	}
+	if in.KeyLength < minKeyLength {
+		return fmt.Errorf("argon2 hash too short")
+	}
+	return nil
+}
@@ -0,0 +1,135 @@
+package argon2
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/allisson/go-pwdhash/internal/encoding"
+)
+
+func legacyHash(t *testing.T, variant Variant, version int, password string) string {
+	t.Helper()
+
+	salt := []byte("0123456789abcdef")
+	engineVersion := uint32(Version10)
+	if version == Version13 {
+		engineVersion = Version13
+	}
+
+	key, err := Derive(Input{
+		Variant:     variant,
+		Version:     engineVersion,
+		Password:    []byte(password),
+		Salt:        salt,
+		Memory:      MinMemory,
+		Iterations:  2,
+		Parallelism: 2,
+		KeyLength:   32,
+	})
+	require.NoError(t, err)
+
+	enc := encoding.EncodedHash{
+		Algorithm: variant.String(),
+		Version:   version,
+		Params:    map[string]string{"m": "32768", "t": "2", "p": "2"},
+		Salt:      salt,
+		Hash:      key,
+	}
+
+	return enc.String()
+}
+
+func TestLegacyVerifier_ReferenceVectors(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name    string
+		encoded string
+	}{
+		{
+			name:    "argon2iVersion19",
+			encoded: "$argon2i$v=19$m=65536,t=2,p=1$c29tZXNhbHQ$wWKIMhR9lyDFvRz9YTZweHKfbftvj+qf+YFY4NeBbtA",
+		},
+		{
+			name:    "argon2iWithoutVersion",
+			encoded: "$argon2i$m=65536,t=2,p=1$c29tZXNhbHQ$9sTbSlTio3Biev89thdrlKKiCaYsjjYVJxGAL3swxpQ",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			verifier := NewLegacyVerifier(Argon2i)
+
+			ok, err := verifier.Verify([]byte("password"), tt.encoded)
+			require.NoError(t, err)
+			require.True(t, ok)
+
+			ok, err = verifier.Verify([]byte("wrong"), tt.encoded)
+			require.NoError(t, err)
+			require.False(t, ok)
+
+			needs, err := verifier.NeedsRehash(tt.encoded)
+			require.NoError(t, err)
+			require.True(t, needs)
+		})
+	}
+}
+
+func TestLegacyVerifier_Argon2d(t *testing.T) {
+	encoded := legacyHash(t, Argon2d, Version10, "password")
+	verifier := NewLegacyVerifier(Argon2d)
+	require.Equal(t, "argon2d", verifier.ID())
+
+	ok, err := verifier.Verify([]byte("password"), encoded)
+	require.NoError(t, err)
+	require.True(t, ok)
+
+	ok, err = NewLegacyVerifier(Argon2i).Verify([]byte("password"), encoded)
+	require.NoError(t, err)
+	require.False(t, ok)
+}
+
+func TestLegacyVerifier_RejectsExcessiveCost(t *testing.T) {
+	verifier := NewLegacyVerifier(Argon2i)
+
+	_, err := verifier.Verify([]byte("password"), "$argon2i$v=19$m=4194304,t=2,p=1$c29tZXNhbHQ$ZGVm")
+	require.ErrorContains(t, err, "memory too high")
+}
+
+func TestArgon2idHasher_VerifyVersion16(t *testing.T) {
+	hasher := newTestHasher()
+
+	for _, version := range []int{0, Version10} {
+		encoded := legacyHash(t, Argon2id, version, "password")
+
+		ok, err := hasher.Verify([]byte("password"), encoded)
+		require.NoError(t, err)
+		require.True(t, ok)
+
+		needs, err := hasher.NeedsRehash(encoded)
+		require.NoError(t, err)
+		require.True(t, needs)
+	}
+}
+
+func TestLegacyVerifier_Normalization(t *testing.T) {
+	hasher := newTestHasher()
+	hasher.Normalization = NormalizationNFKC
+
+	encoded, err := hasher.Hash([]byte("cafe\u0301"))
+	require.NoError(t, err)
+
+	verifier := NewLegacyVerifier(Argon2id)
+	for _, password := range []string{"cafe\u0301", "caf\u00e9"} {
+		ok, err := verifier.Verify([]byte(password), encoded)
+		require.NoError(t, err)
+		require.True(t, ok, password)
+	}
+
+	ok, err := verifier.Verify([]byte("cafe"), encoded)
+	require.NoError(t, err)
+	require.False(t, ok)
+}
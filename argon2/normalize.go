@@ -0,0 +1,64 @@
+package argon2
+
+import (
+	"fmt"
+
+	"golang.org/x/text/secure/precis"
+	"golang.org/x/text/unicode/norm"
+)
+
+// Normalization selects the Unicode normalization applied to passwords before
+// hashing, so the same password typed on different platforms hashes alike.
+type Normalization int
+
+const (
+	// NormalizationNone hashes the password bytes as given.
+	NormalizationNone Normalization = iota
+	// NormalizationNFKC applies Unicode Normalization Form KC.
+	NormalizationNFKC
+	// NormalizationOpaqueString applies the RFC 8265 OpaqueString profile, the
+	// PRECIS successor to SASLprep: non-ASCII spaces are mapped to U+0020,
+	// the result is NFC-normalized, and control characters are rejected.
+	NormalizationOpaqueString
+)
+
+// normalizationParams maps each normalization to its PHC n= value.
+var normalizationParams = map[Normalization]string{
+	NormalizationNFKC:         "nfkc",
+	NormalizationOpaqueString: "opaque",
+}
+
+// normalize returns the password under n as a new buffer the caller must zero.
+func (n Normalization) normalize(password []byte) ([]byte, error) {
+	switch n {
+	case NormalizationNone:
+		return append([]byte{}, password...), nil
+	case NormalizationNFKC:
+		return norm.NFKC.Append(nil, password...), nil
+	case NormalizationOpaqueString:
+		out, err := precis.OpaqueString.Bytes(password)
+		if err != nil {
+			return nil, fmt.Errorf("argon2 password normalization: %w", err)
+		}
+		return out, nil
+	}
+
+	return nil, fmt.Errorf("unsupported argon2 normalization")
+}
+
+// normalizationOf maps the PHC n= value back to a Normalization; hashes
+// without it were computed over the raw password bytes.
+func normalizationOf(params map[string]string) (Normalization, error) {
+	value, ok := params["n"]
+	if !ok {
+		return NormalizationNone, nil
+	}
+
+	for n, param := range normalizationParams {
+		if param == value {
+			return n, nil
+		}
+	}
+
+	return 0, fmt.Errorf("unsupported argon2 normalization: %s", value)
+}
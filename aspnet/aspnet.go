@@ -0,0 +1,165 @@
+// Package aspnet provides a verify-only adapter for migrating ASP.NET Identity hashes.
+//
+// It understands the base64 blobs written by PasswordHasher in ASP.NET Identity
+// v2 and ASP.NET Core Identity v3. v2 blobs carry only 1000 rounds of
+// PBKDF2-HMAC-SHA1, and NeedsRehash reports true for both versions so the
+// current hasher takes over after a successful login.
+package aspnet
+
+import (
+	"crypto/pbkdf2"
+	"crypto/sha1" // #nosec G505 -- required to verify legacy PBKDF2-HMAC-SHA1 hashes
+	"crypto/sha256"
+	"crypto/sha512"
+	"encoding/base64"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"hash"
+
+	"github.com/allisson/go-pwdhash/internal/subtle"
+	"github.com/allisson/go-pwdhash/internal/zero"
+)
+
+const (
+	v2Marker     = 0x00
+	v2Iterations = 1000
+	v2SaltLength = 16
+	v2KeyLength  = 32
+
+	v3Marker       = 0x01
+	v3HeaderLength = 13
+	v3MinLength    = 16
+)
+
+var errInvalidHash = errors.New("invalid aspnet hash")
+
+// prfs maps the v3 KeyDerivationPrf values to their digests.
+var prfs = map[uint32]func() hash.Hash{
+	0: sha1.New,
+	1: sha256.New,
+	2: sha512.New,
+}
+
+// Verifier checks ASP.NET Identity v2 and v3 password hashes.
+type Verifier struct{}
+
+// New returns an ASP.NET Identity Verifier.
+func New() *Verifier {
+	return &Verifier{}
+}
+
+// ID reports the scheme identifier.
+func (v *Verifier) ID() string {
+	return "aspnet"
+}
+
+// Recognize reports whether encoded is a well-formed v2 or v3 blob.
+func (v *Verifier) Recognize(encoded string) bool {
+	_, err := parse(encoded)
+	return err == nil
+}
+
+// Verify recomputes the PBKDF2 subkey and compares it in constant time.
+func (v *Verifier) Verify(password []byte, encoded string) (bool, error) {
+	defer zero.Bytes(password)
+
+	p, err := parse(encoded)
+	if err != nil {
+		return false, err
+	}
+
+	defer zero.Bytes(p.want)
+
+	if p.iterations > MaxIterations {
+		return false, fmt.Errorf("aspnet iterations too high")
+	}
+	if len(p.want) > MaxKeyLength {
+		return false, fmt.Errorf("aspnet key length too high")
+	}
+
+	key, err := pbkdf2.Key(p.digest, string(password), p.salt, p.iterations, len(p.want))
+	if err != nil {
+		return false, err
+	}
+
+	defer zero.Bytes(key)
+
+	return subtle.ConstantTimeCompare(key, p.want), nil
+}
+
+// NeedsRehash always reports true for ASP.NET Identity hashes.
+func (v *Verifier) NeedsRehash(encoded string) (bool, error) {
+	if _, err := parse(encoded); err != nil {
+		return false, err
+	}
+
+	return true, nil
+}
+
+// params holds the decoded inputs for a single PBKDF2 computation.
+type params struct {
+	digest     func() hash.Hash
+	iterations int
+	salt       []byte
+	want       []byte
+}
+
+// parse decodes the base64 blob and dispatches on its leading format marker.
+func parse(encoded string) (*params, error) {
+	raw, err := base64.StdEncoding.DecodeString(encoded)
+	if err != nil || len(raw) == 0 {
+		return nil, errInvalidHash
+	}
+
+	switch raw[0] {
+	case v2Marker:
+		return parseV2(raw[1:])
+	case v3Marker:
+		return parseV3(raw[1:])
+	}
+
+	return nil, errInvalidHash
+}
+
+// parseV2 handles <salt:16><subkey:32>, derived with PBKDF2-HMAC-SHA1 and 1000 iterations.
+func parseV2(body []byte) (*params, error) {
+	if len(body) != v2SaltLength+v2KeyLength {
+		return nil, errInvalidHash
+	}
+
+	return &params{
+		digest:     sha1.New,
+		iterations: v2Iterations,
+		salt:       body[:v2SaltLength],
+		want:       body[v2SaltLength:],
+	}, nil
+}
+
+// parseV3 handles <prf:4><iterations:4><salt length:4><salt><subkey>, with the
+// header fields stored as big-endian uint32 values.
+func parseV3(body []byte) (*params, error) {
+	if len(body) < v3HeaderLength-1 {
+		return nil, errInvalidHash
+	}
+
+	digest, ok := prfs[binary.BigEndian.Uint32(body[0:])]
+	if !ok {
+		return nil, errInvalidHash
+	}
+
+	iterations := binary.BigEndian.Uint32(body[4:])
+	saltLength := binary.BigEndian.Uint32(body[8:])
+	rest := body[v3HeaderLength-1:]
+
+	if iterations < 1 || saltLength < v3MinLength || uint64(saltLength)+v3MinLength > uint64(len(rest)) {
+		return nil, errInvalidHash
+	}
+
+	return &params{
+		digest:     digest,
+		iterations: int(iterations),
+		salt:       rest[:saltLength],
+		want:       rest[saltLength:],
+	}, nil
+}
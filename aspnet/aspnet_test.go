@@ -0,0 +1,67 @@
+package aspnet
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestVerifier_Verify(t *testing.T) {
+	tests := []struct {
+		name    string
+		encoded string
+	}{
+		{
+			name:    "v2",
+			encoded: "AAABAgMEBQYHCAkKCwwNDg8DCeL+Tgvf59D+SCjUHCNEFuLZv7Yc3Y9kOhHPv9/BGQ==",
+		},
+		{
+			name:    "v3SHA256",
+			encoded: "AQAAAAEAACcQAAAAEAABAgMEBQYHCAkKCwwNDg/rbIFTVZIgPAkrFY+NOQlnI2Km9dvQDZgoBEy6qLJS6Q==",
+		},
+		{
+			name:    "v3SHA512",
+			encoded: "AQAAAAIAAYagAAAAEAABAgMEBQYHCAkKCwwNDg/73hTTOMxvghBX8/SnisILxwGxHjepOzeQw1EOAZRz8w==",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			verifier := New()
+			require.True(t, verifier.Recognize(tt.encoded))
+
+			ok, err := verifier.Verify([]byte("password"), tt.encoded)
+			require.NoError(t, err)
+			require.True(t, ok)
+
+			ok, err = verifier.Verify([]byte("wrong"), tt.encoded)
+			require.NoError(t, err)
+			require.False(t, ok)
+
+			needs, err := verifier.NeedsRehash(tt.encoded)
+			require.NoError(t, err)
+			require.True(t, needs)
+		})
+	}
+}
+
+func TestVerifier_Errors(t *testing.T) {
+	tests := []struct {
+		name    string
+		encoded string
+	}{
+		{name: "notBase64", encoded: "$2b$04$p4wSFWnH/3cP53yyCWSnEu"},
+		{name: "unknownMarker", encoded: "AgAAAAEAACcQAAAAEAABAgMEBQYHCAkKCwwNDg/rbIFTVZIgPAkrFY+NOQlnI2Km9dvQDZgoBEy6qLJS6Q=="},
+		{name: "v2Truncated", encoded: "AAABAgMEBQYHCAkKCwwNDg8DCeL+Tgvf59D+SCjUHCNEFuLZv7Yc3Y9kOhHPv9/B"},
+		{name: "v3UnknownPRF", encoded: "AQAAAAMAACcQAAAAEAABAgMEBQYHCAkKCwwNDg/rbIFTVZIgPAkrFY+NOQlnI2Km9dvQDZgoBEy6qLJS6Q=="},
+		{name: "v3SaltOverflow", encoded: "AQAAAAEAACcQAAAA/wABAgMEBQYHCAkKCwwNDg/rbIFTVZIgPAkrFY+NOQlnI2Km9dvQDZgoBEy6qLJS6Q=="},
+		{name: "v3IterationCeiling", encoded: "AQAAAAEF9eEAAAAAEAABAgMEBQYHCAkKCwwNDg/rbIFTVZIgPAkrFY+NOQlnI2Km9dvQDZgoBEy6qLJS6Q=="},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := New().Verify([]byte("password"), tt.encoded)
+			require.Error(t, err)
+		})
+	}
+}
@@ -0,0 +1,6 @@
+package aspnet
+
+const (
+	MaxIterations = 10_000_000
+	MaxKeyLength  = 128
+)
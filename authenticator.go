@@ -0,0 +1,130 @@
+package pwdhash
+
+import (
+	"context"
+	"crypto/rand"
+	"errors"
+)
+
+// Outcome classifies an authentication attempt.
+type Outcome int
+
+const (
+	// OutcomeSuccess means the password matched the stored hash.
+	OutcomeSuccess Outcome = iota
+	// OutcomeInvalidPassword means the user exists but the password did not match.
+	OutcomeInvalidPassword
+	// OutcomeUnknownUser means the store holds no hash for the user.
+	OutcomeUnknownUser
+)
+
+// String returns a lowercase label for logs and metrics.
+func (o Outcome) String() string {
+	switch o {
+	case OutcomeSuccess:
+		return "success"
+	case OutcomeInvalidPassword:
+		return "invalid_password"
+	case OutcomeUnknownUser:
+		return "unknown_user"
+	}
+
+	return "unknown"
+}
+
+// AuthResult reports the outcome of Authenticate.
+//
+// Callers should show the same message for OutcomeInvalidPassword and
+// OutcomeUnknownUser; the distinction is meant for logs and metrics only.
+// RehashErr records a failure to persist an upgraded hash, which does not
+// fail the login itself.
+type AuthResult struct {
+	Outcome   Outcome
+	Rehashed  bool
+	RehashErr error
+}
+
+// OK reports whether the attempt succeeded.
+func (r AuthResult) OK() bool {
+	return r.Outcome == OutcomeSuccess
+}
+
+// Authenticator runs the complete login flow against a Store: it verifies
+// the password, spends the same work on unknown users, and replaces hashes
+// that NeedsRehash flags.
+type Authenticator struct {
+	hasher *PasswordHasher
+	store  Store
+	dummy  string
+}
+
+// NewAuthenticator returns an Authenticator. It hashes a random password
+// with the current hasher so unknown users can be verified against it.
+func NewAuthenticator(hasher *PasswordHasher, store Store) (*Authenticator, error) {
+	random := make([]byte, 32)
+	if _, err := rand.Read(random); err != nil {
+		return nil, err
+	}
+
+	dummy, err := hasher.current.Hash(random)
+	if err != nil {
+		return nil, err
+	}
+
+	return &Authenticator{hasher: hasher, store: store, dummy: dummy}, nil
+}
+
+// Authenticate checks password for user. The returned error covers store
+// failures and unusable hashes; a wrong password or an unknown user is
+// reported through AuthResult.Outcome with a nil error.
+func (a *Authenticator) Authenticate(ctx context.Context, user string, password *Secret) (AuthResult, error) {
+	if err := ctx.Err(); err != nil {
+		return AuthResult{}, err
+	}
+
+	encoded, err := a.store.LoadHash(ctx, user)
+	if errors.Is(err, ErrUserNotFound) {
+		// Equalize timing with a real verification so probing cannot reveal
+		// which users exist.
+		if _, err := a.hasher.VerifySecret(password, a.dummy); err != nil {
+			return AuthResult{}, err
+		}
+		return AuthResult{Outcome: OutcomeUnknownUser}, nil
+	}
+	if err != nil {
+		return AuthResult{}, err
+	}
+
+	ok, err := a.hasher.VerifySecret(password, encoded)
+	if err != nil {
+		return AuthResult{}, err
+	}
+	if !ok {
+		return AuthResult{Outcome: OutcomeInvalidPassword}, nil
+	}
+
+	result := AuthResult{Outcome: OutcomeSuccess}
+
+	needs, err := a.hasher.NeedsRehash(encoded)
+	if err != nil || !needs {
+		result.RehashErr = err
+		return result, nil
+	}
+
+	// The password already passed verification, so upgrade it even if a
+	// Validator added since would reject it as a new password.
+	upgraded, err := a.hasher.rehashSecret(password)
+	if err != nil {
+		result.RehashErr = err
+		return result, nil
+	}
+
+	if err := a.store.UpdateHash(ctx, user, upgraded); err != nil {
+		result.RehashErr = err
+		return result, nil
+	}
+
+	result.Rehashed = true
+
+	return result, nil
+}
@@ -0,0 +1,116 @@
+package pwdhash
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+// failingStore wraps a Store and fails every update.
+type failingStore struct {
+	Store
+}
+
+func (f failingStore) UpdateHash(context.Context, string, string) error {
+	return errors.New("store unavailable")
+}
+
+func TestAuthenticator_Authenticate(t *testing.T) {
+	ctx := context.Background()
+
+	ph, err := New(WithHasher(newTestArgon2()))
+	require.NoError(t, err)
+
+	current, err := ph.Hash([]byte("password"))
+	require.NoError(t, err)
+
+	legacy := "$argon2i$v=19$m=65536,t=2,p=1$c29tZXNhbHQ$wWKIMhR9lyDFvRz9YTZweHKfbftvj+qf+YFY4NeBbtA"
+
+	tests := []struct {
+		name      string
+		stored    string
+		password  string
+		failStore bool
+		want      Outcome
+		rehashed  bool
+		rehashErr bool
+	}{
+		{name: "success", stored: current, password: "password", want: OutcomeSuccess},
+		{name: "invalidPassword", stored: current, password: "wrong", want: OutcomeInvalidPassword},
+		{name: "unknownUser", password: "password", want: OutcomeUnknownUser},
+		{name: "rehash", stored: legacy, password: "password", want: OutcomeSuccess, rehashed: true},
+		{name: "rehashStoreFailure", stored: legacy, password: "password", failStore: true, want: OutcomeSuccess, rehashErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			memory := NewMemoryStore()
+			if tt.stored != "" {
+				require.NoError(t, memory.UpdateHash(ctx, "alice", tt.stored))
+			}
+
+			var store Store = memory
+			if tt.failStore {
+				store = failingStore{memory}
+			}
+
+			auth, err := NewAuthenticator(ph, store)
+			require.NoError(t, err)
+
+			secret := NewSecretString(tt.password)
+			defer secret.Destroy()
+
+			result, err := auth.Authenticate(ctx, "alice", secret)
+			require.NoError(t, err)
+			require.Equal(t, tt.want, result.Outcome)
+			require.Equal(t, tt.want == OutcomeSuccess, result.OK())
+			require.Equal(t, tt.rehashed, result.Rehashed)
+			require.Equal(t, tt.rehashErr, result.RehashErr != nil)
+
+			if tt.rehashed {
+				upgraded, err := memory.LoadHash(ctx, "alice")
+				require.NoError(t, err)
+				require.NotEqual(t, tt.stored, upgraded)
+
+				needs, err := ph.NeedsRehash(upgraded)
+				require.NoError(t, err)
+				require.False(t, needs)
+			}
+		})
+	}
+}
+
+func TestAuthenticator_Errors(t *testing.T) {
+	ph, err := New(WithHasher(newTestArgon2()))
+	require.NoError(t, err)
+
+	store := NewMemoryStore()
+	require.NoError(t, store.UpdateHash(context.Background(), "alice", "corrupt"))
+
+	auth, err := NewAuthenticator(ph, store)
+	require.NoError(t, err)
+
+	_, err = auth.Authenticate(context.Background(), "alice", NewSecretString("password"))
+	require.Error(t, err)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+
+	_, err = auth.Authenticate(ctx, "alice", NewSecretString("password"))
+	require.ErrorIs(t, err, context.Canceled)
+
+	destroyed := NewSecretString("password")
+	destroyed.Destroy()
+
+	_, err = auth.Authenticate(context.Background(), "bob", destroyed)
+	require.ErrorIs(t, err, ErrSecretDestroyed)
+}
+
+func TestOutcome_String(t *testing.T) {
+	require.Equal(t, "success", OutcomeSuccess.String())
+	require.Equal(t, "invalid_password", OutcomeInvalidPassword.String())
+	require.Equal(t, "unknown_user", OutcomeUnknownUser.String())
+	require.Equal(t, "unknown", Outcome(99).String())
+}
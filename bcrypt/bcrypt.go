@@ -0,0 +1,97 @@
+// Package bcrypt provides a verify-only adapter for migrating bcrypt hashes.
+//
+// bcrypt reads only the first 72 bytes of a password, so NeedsRehash reports
+// true for every hash and the current hasher replaces it on the next login.
+package bcrypt
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+
+	xbcrypt "golang.org/x/crypto/bcrypt"
+
+	"github.com/allisson/go-pwdhash/internal/zero"
+)
+
+// springPrefix is the DelegatingPasswordEncoder marker used by Spring Security.
+const springPrefix = "{bcrypt}"
+
+// maxCost bounds the stored cost factor; each step doubles the work of a
+// single verification, so cost 16 already takes seconds.
+const maxCost = 16
+
+// bodyLength is the length of the two-digit cost, separator, salt, and digest
+// that follow the version prefix, as in $2b$10$<22-char salt><31-char digest>.
+const bodyLength = 56
+
+var errInvalidHash = errors.New("invalid bcrypt hash")
+
+// Verifier checks $2$, $2a$, $2b$, $2x$ and $2y$ bcrypt hashes, optionally
+// wrapped in Spring's {bcrypt} prefix.
+//
+// $2x$ hashes were produced by a crypt_blowfish release that mishandled
+// passwords with 8-bit characters; they are verified with the corrected
+// algorithm, which agrees for ASCII passwords only.
+type Verifier struct{}
+
+// New returns a bcrypt Verifier.
+func New() *Verifier {
+	return &Verifier{}
+}
+
+// ID reports the scheme identifier.
+func (v *Verifier) ID() string {
+	return "bcrypt"
+}
+
+// Recognize reports whether encoded looks like a bcrypt hash.
+func (v *Verifier) Recognize(encoded string) bool {
+	encoded = strings.TrimPrefix(encoded, springPrefix)
+
+	for _, prefix := range []string{"$2$", "$2a$", "$2b$", "$2x$", "$2y$"} {
+		if strings.HasPrefix(encoded, prefix) {
+			return len(encoded) == len(prefix)+bodyLength
+		}
+	}
+
+	return false
+}
+
+// Verify compares the password against the bcrypt hash in constant time.
+func (v *Verifier) Verify(password []byte, encoded string) (bool, error) {
+	defer zero.Bytes(password)
+
+	if !v.Recognize(encoded) {
+		return false, errInvalidHash
+	}
+
+	hash := []byte(strings.TrimPrefix(encoded, springPrefix))
+
+	cost, err := xbcrypt.Cost(hash)
+	if err != nil {
+		return false, err
+	}
+	if cost > maxCost {
+		return false, fmt.Errorf("bcrypt cost too high")
+	}
+
+	err = xbcrypt.CompareHashAndPassword(hash, password)
+	if errors.Is(err, xbcrypt.ErrMismatchedHashAndPassword) {
+		return false, nil
+	}
+	if err != nil {
+		return false, err
+	}
+
+	return true, nil
+}
+
+// NeedsRehash always reports true for bcrypt hashes.
+func (v *Verifier) NeedsRehash(encoded string) (bool, error) {
+	if !v.Recognize(encoded) {
+		return false, errInvalidHash
+	}
+
+	return true, nil
+}
@@ -0,0 +1,97 @@
+package bcrypt
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestVerifier_Verify(t *testing.T) {
+	tests := []struct {
+		name     string
+		password string
+		encoded  string
+	}{
+		{
+			name:     "2b",
+			password: "password",
+			encoded:  "$2b$04$p4wSFWnH/3cP53yyCWSnEuxvwVcro/yOPi/Vi6hez5ZvWA8q1d7WO",
+		},
+		{
+			name:     "2y",
+			password: "rasmuslerdorf",
+			encoded:  "$2y$10$.vGA1O9wmRjrwAVXD98HNOgsNpDczlqm3Jq7KnEd1rVAGv3Fykk1a",
+		},
+		{
+			name:     "spring",
+			password: "password",
+			encoded:  "{bcrypt}$2a$10$dXJ3SW6G7P50lGmMkkmwe.20cQQubK3.HZWzG3YB1tlRy.fqvM/BG",
+		},
+		{
+			name:     "nonASCII",
+			password: "pässword",
+			encoded:  "$2b$04$abcdefghijklmnopqrstuum174eFeoTj0DHmtuueFaS3Wde4P7wHG",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			verifier := New()
+			require.True(t, verifier.Recognize(tt.encoded))
+
+			ok, err := verifier.Verify([]byte(tt.password), tt.encoded)
+			require.NoError(t, err)
+			require.True(t, ok)
+
+			ok, err = verifier.Verify([]byte("wrong"), tt.encoded)
+			require.NoError(t, err)
+			require.False(t, ok)
+
+			needs, err := verifier.NeedsRehash(tt.encoded)
+			require.NoError(t, err)
+			require.True(t, needs)
+		})
+	}
+}
+
+func TestVerifier_Recognize(t *testing.T) {
+	tests := []struct {
+		name    string
+		encoded string
+		want    bool
+	}{
+		{name: "argon2id", encoded: "$argon2id$v=19$m=65536,t=3,p=4$YWJj$ZGVm", want: false},
+		{name: "truncated", encoded: "$2b$04$p4wSFWnH/3cP53yyCWSnEuxvwVcro", want: false},
+		{name: "unknownMinor", encoded: "$2c$04$p4wSFWnH/3cP53yyCWSnEuxvwVcro/yOPi/Vi6hez5ZvWA8q1d7WO", want: false},
+		{name: "noMinor", encoded: "$2$04$p4wSFWnH/3cP53yyCWSnEuxvwVcro/yOPi/Vi6hez5ZvWA8q1d7WO", want: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			require.Equal(t, tt.want, New().Recognize(tt.encoded))
+		})
+	}
+}
+
+func TestVerifier_RejectsMalformedHash(t *testing.T) {
+	verifier := New()
+
+	_, err := verifier.Verify([]byte("password"), "$argon2id$v=19$m=65536,t=3,p=4$YWJj$ZGVm")
+	require.Error(t, err)
+
+	_, err = verifier.NeedsRehash("not-a-hash")
+	require.Error(t, err)
+
+	_, err = verifier.Verify([]byte("password"), "$2b$99$p4wSFWnH/3cP53yyCWSnEuxvwVcro/yOPi/Vi6hez5ZvWA8q1d7WO")
+	require.Error(t, err)
+}
+
+func TestVerifier_RejectsExcessiveCost(t *testing.T) {
+	verifier := New()
+
+	_, err := verifier.Verify([]byte("password"), "$2b$31$p4wSFWnH/3cP53yyCWSnEuxvwVcro/yOPi/Vi6hez5ZvWA8q1d7WO")
+	require.ErrorContains(t, err, "cost too high")
+
+	_, err = verifier.Verify([]byte("password"), "$2b$17$p4wSFWnH/3cP53yyCWSnEuxvwVcro/yOPi/Vi6hez5ZvWA8q1d7WO")
+	require.ErrorContains(t, err, "cost too high")
+}
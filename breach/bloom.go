@@ -0,0 +1,209 @@
+package breach
+
+import (
+	"bufio"
+	"context"
+	"encoding/binary"
+	"fmt"
+	"io"
+	"math"
+	"os"
+
+	"github.com/allisson/go-pwdhash/internal/zero"
+)
+
+const (
+	maxBloomHashes = 32
+	maxBloomBits   = 1 << 37
+
+	// bloomChunkWords bounds how much of the bit array ReadBloom allocates
+	// ahead of the data that backs it (8 MiB).
+	bloomChunkWords = 1 << 20
+)
+
+// Bloom is a Bloom filter over breach digests. It never misses a breached
+// password but reports a small fraction of other passwords as breached, at
+// the rate chosen in NewBloom; about 1.7 GB holds the full SHA-1 corpus at a
+// 0.1% false-positive rate.
+//
+// Because the digests are already uniformly distributed, bit positions are
+// derived from the digest itself by double hashing.
+type Bloom struct {
+	kind   Kind
+	hashes uint8
+	bits   uint64
+	words  []uint64
+}
+
+// NewBloom returns an empty filter sized for n digests of kind k at the given
+// false-positive rate.
+func NewBloom(k Kind, n uint64, falsePositive float64) (*Bloom, error) {
+	if k.Size() == 0 {
+		return nil, fmt.Errorf("unsupported breach kind: %d", k)
+	}
+	if falsePositive <= 0 || falsePositive >= 1 {
+		return nil, fmt.Errorf("bloom false-positive rate must be between 0 and 1")
+	}
+
+	n = max(n, 1)
+	bits := uint64(math.Ceil(-float64(n) * math.Log(falsePositive) / (math.Ln2 * math.Ln2)))
+	if bits > maxBloomBits {
+		return nil, fmt.Errorf("bloom filter would exceed %d bits", uint64(maxBloomBits))
+	}
+	hashes := math.Round(float64(bits) / float64(n) * math.Ln2)
+	hashes = min(max(hashes, 1), maxBloomHashes)
+
+	return newBloom(k, uint8(hashes), bits), nil
+}
+
+func newBloom(k Kind, hashes uint8, bits uint64) *Bloom {
+	bits = max(bits, 64)
+	return &Bloom{kind: k, hashes: hashes, bits: bits, words: make([]uint64, (bits+63)/64)}
+}
+
+// Kind reports the digest the filter is keyed by.
+func (b *Bloom) Kind() Kind {
+	return b.kind
+}
+
+// Add inserts digest into the filter.
+func (b *Bloom) Add(digest []byte) error {
+	if len(digest) != b.kind.Size() {
+		return fmt.Errorf("%w: digest length %d", errInvalidDataset, len(digest))
+	}
+
+	h1, h2 := bloomSeeds(digest)
+	for i := uint64(0); i < uint64(b.hashes); i++ {
+		pos := (h1 + i*h2) % b.bits
+		b.words[pos/64] |= 1 << (pos % 64)
+	}
+
+	return nil
+}
+
+// Contains reports whether digest may be in the filter.
+func (b *Bloom) Contains(digest []byte) bool {
+	if len(digest) != b.kind.Size() {
+		return false
+	}
+
+	h1, h2 := bloomSeeds(digest)
+	for i := uint64(0); i < uint64(b.hashes); i++ {
+		pos := (h1 + i*h2) % b.bits
+		if b.words[pos/64]&(1<<(pos%64)) == 0 {
+			return false
+		}
+	}
+
+	return true
+}
+
+// Breached reports whether the digest of password may be in the filter.
+func (b *Bloom) Breached(_ context.Context, password []byte) (bool, error) {
+	digest, err := b.kind.Digest(password)
+	if err != nil {
+		return false, err
+	}
+
+	defer zero.Bytes(digest)
+
+	return b.Contains(digest), nil
+}
+
+// WriteTo serializes the filter.
+func (b *Bloom) WriteTo(w io.Writer) (int64, error) {
+	buf := bufio.NewWriter(w)
+
+	n, err := buf.Write(header{magic: bloomMagic, kind: b.kind, extra: b.hashes, count: b.bits}.encode())
+	written := int64(n)
+	if err != nil {
+		return written, err
+	}
+
+	word := make([]byte, 8)
+	for _, v := range b.words {
+		binary.LittleEndian.PutUint64(word, v)
+		n, err := buf.Write(word)
+		written += int64(n)
+		if err != nil {
+			return written, err
+		}
+	}
+
+	return written, buf.Flush()
+}
+
+// ReadBloom deserializes a filter written by WriteTo.
+func ReadBloom(r io.Reader) (*Bloom, error) {
+	buf := make([]byte, headerSize)
+	if _, err := io.ReadFull(r, buf); err != nil {
+		return nil, errInvalidDataset
+	}
+
+	h, err := decodeHeader(buf, bloomMagic)
+	if err != nil {
+		return nil, err
+	}
+
+	if h.extra < 1 || h.extra > maxBloomHashes || h.count < 64 || h.count > maxBloomBits {
+		return nil, errInvalidDataset
+	}
+
+	// The header is untrusted, so the bit array grows only as data arrives
+	// rather than being allocated up front from the declared size.
+	n := (h.count + 63) / 64
+	words := make([]uint64, 0, min(n, bloomChunkWords))
+	chunk := make([]byte, 8*min(n, bloomChunkWords))
+	br := bufio.NewReader(r)
+	for remaining := n; remaining > 0; {
+		buf := chunk[:8*min(remaining, bloomChunkWords)]
+		if _, err := io.ReadFull(br, buf); err != nil {
+			return nil, fmt.Errorf("%w: truncated bloom filter", errInvalidDataset)
+		}
+		for off := 0; off < len(buf); off += 8 {
+			words = append(words, binary.LittleEndian.Uint64(buf[off:]))
+		}
+		remaining -= uint64(len(buf) / 8)
+	}
+
+	return &Bloom{kind: h.kind, hashes: h.extra, bits: h.count, words: words}, nil
+}
+
+// OpenBloom loads the filter stored at path into memory.
+func OpenBloom(path string) (*Bloom, error) {
+	file, err := os.Open(path) // #nosec G304 -- the path is chosen by the caller
+	if err != nil {
+		return nil, err
+	}
+	defer func() { _ = file.Close() }()
+
+	info, err := file.Stat()
+	if err != nil {
+		return nil, err
+	}
+	if info.Size() < headerSize {
+		return nil, errInvalidDataset
+	}
+	buf := make([]byte, headerSize)
+	if _, err := io.ReadFull(file, buf); err != nil {
+		return nil, errInvalidDataset
+	}
+	h, err := decodeHeader(buf, bloomMagic)
+	if err != nil {
+		return nil, err
+	}
+	if h.count > maxBloomBits || info.Size() != headerSize+int64((h.count+63)/64*8) {
+		return nil, fmt.Errorf("%w: bloom filter size does not match its header", errInvalidDataset)
+	}
+	if _, err := file.Seek(0, io.SeekStart); err != nil {
+		return nil, err
+	}
+
+	return ReadBloom(file)
+}
+
+// bloomSeeds splits the leading 16 bytes of digest into the two double-hashing
+// seeds; the second is forced odd so successive probes never repeat early.
+func bloomSeeds(digest []byte) (uint64, uint64) {
+	return binary.BigEndian.Uint64(digest[:8]), binary.BigEndian.Uint64(digest[8:16]) | 1
+}
@@ -0,0 +1,135 @@
+// Package breach checks passwords against a locally stored copy of the Pwned
+// Passwords corpus, so deployments without internet access can refuse known
+// breached passwords.
+//
+// Three Checkers are provided: SortedFile binary-searches a file of sorted
+// digests, Bloom answers from a compact probabilistic filter, and
+// RangeChecker runs the k-anonymity range protocol against a RangeSource,
+// which by default reads the per-prefix files of the Pwned Passwords
+// downloader. The pwdhash-breach command builds the first two from the
+// downloaded dataset. Wrap any Checker in a Validator to make
+// pwdhash.PasswordHasher refuse breached passwords.
+package breach
+
+import (
+	"context"
+	"crypto/sha1" // #nosec G505 -- the Pwned Passwords corpus is keyed by SHA-1
+	"encoding/binary"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"strings"
+	"unicode/utf16"
+
+	"golang.org/x/crypto/md4" // #nosec G501 -- the Pwned Passwords NTLM corpus is keyed by MD4
+
+	"github.com/allisson/go-pwdhash/internal/zero"
+)
+
+// ErrBreached indicates that a password appears in the breach corpus.
+var ErrBreached = errors.New("password appears in a known data breach")
+
+var errInvalidDataset = errors.New("invalid breach dataset")
+
+// Kind selects the digest a dataset is keyed by.
+type Kind uint8
+
+const (
+	// KindSHA1 is the SHA-1 edition of Pwned Passwords.
+	KindSHA1 Kind = iota + 1
+	// KindNTLM is the NTLM edition: MD4 over the UTF-16LE password.
+	KindNTLM
+)
+
+// Size returns the digest length in bytes, or zero for an unknown kind.
+func (k Kind) Size() int {
+	switch k {
+	case KindSHA1:
+		return sha1.Size
+	case KindNTLM:
+		return md4.Size
+	}
+
+	return 0
+}
+
+// String returns the lowercase kind name.
+func (k Kind) String() string {
+	switch k {
+	case KindSHA1:
+		return "sha1"
+	case KindNTLM:
+		return "ntlm"
+	}
+
+	return "unknown"
+}
+
+// Digest returns the digest of password under k. Callers should zero it after use.
+func (k Kind) Digest(password []byte) ([]byte, error) {
+	switch k {
+	case KindSHA1:
+		sum := sha1.Sum(password) // #nosec G401 -- lookup key, not password storage
+		return sum[:], nil
+	case KindNTLM:
+		units := utf16.Encode([]rune(string(password)))
+		buf := make([]byte, 2*len(units))
+		for i, u := range units {
+			binary.LittleEndian.PutUint16(buf[2*i:], u)
+		}
+		defer zero.Bytes(buf)
+
+		h := md4.New()
+		h.Write(buf)
+		return h.Sum(nil), nil
+	}
+
+	return nil, fmt.Errorf("unsupported breach kind: %d", k)
+}
+
+// ParseLine decodes one line of a Pwned Passwords download, "HEX" or
+// "HEX:COUNT", into a digest of kind k.
+func ParseLine(k Kind, line string) ([]byte, error) {
+	digestHex, _, _ := strings.Cut(strings.TrimSpace(line), ":")
+
+	digest, err := hex.DecodeString(digestHex)
+	if err != nil {
+		return nil, err
+	}
+
+	if len(digest) != k.Size() {
+		return nil, fmt.Errorf("%w: %q is not a %s digest", errInvalidDataset, digestHex, k)
+	}
+
+	return digest, nil
+}
+
+// Checker reports whether a password appears in a breach corpus.
+type Checker interface {
+	Breached(ctx context.Context, password []byte) (bool, error)
+}
+
+// Validator adapts a Checker to the pwdhash.Validator interface, so
+// pwdhash.WithValidator refuses breached passwords with ErrBreached.
+type Validator struct {
+	checker Checker
+}
+
+// NewValidator returns a Validator backed by checker.
+func NewValidator(checker Checker) *Validator {
+	return &Validator{checker: checker}
+}
+
+// Validate returns ErrBreached if password appears in the corpus.
+func (v *Validator) Validate(password []byte) error {
+	breached, err := v.checker.Breached(context.Background(), password)
+	if err != nil {
+		return err
+	}
+
+	if breached {
+		return ErrBreached
+	}
+
+	return nil
+}
@@ -0,0 +1,213 @@
+package breach
+
+import (
+	"bytes"
+	"context"
+	"encoding/hex"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+var breached = []string{"password", "123456", "qwerty", "letmein", "dragon"}
+
+func digests(t *testing.T, k Kind) [][]byte {
+	t.Helper()
+
+	out := make([][]byte, 0, len(breached))
+	for _, p := range breached {
+		d, err := k.Digest([]byte(p))
+		require.NoError(t, err)
+		out = append(out, d)
+	}
+	sort.Slice(out, func(i, j int) bool { return bytes.Compare(out[i], out[j]) < 0 })
+
+	return out
+}
+
+func TestKind_Digest(t *testing.T) {
+	tests := []struct {
+		kind Kind
+		want string
+	}{
+		{kind: KindSHA1, want: "5BAA61E4C9B93F3F0682250B6CF8331B7EE68FD8"},
+		{kind: KindNTLM, want: "8846F7EAEE8FB117AD06BDD830B7586C"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.kind.String(), func(t *testing.T) {
+			d, err := tt.kind.Digest([]byte("password"))
+			require.NoError(t, err)
+			require.Equal(t, tt.want, strings.ToUpper(hex.EncodeToString(d)))
+
+			parsed, err := ParseLine(tt.kind, tt.want+":3861493\r")
+			require.NoError(t, err)
+			require.Equal(t, d, parsed)
+		})
+	}
+
+	_, err := Kind(9).Digest([]byte("password"))
+	require.Error(t, err)
+
+	_, err = ParseLine(KindNTLM, "5BAA61E4C9B93F3F0682250B6CF8331B7EE68FD8:1")
+	require.Error(t, err)
+}
+
+func TestSortedFile(t *testing.T) {
+	for _, k := range []Kind{KindSHA1, KindNTLM} {
+		t.Run(k.String(), func(t *testing.T) {
+			path := filepath.Join(t.TempDir(), "pwned.sorted")
+			file, err := os.Create(path)
+			require.NoError(t, err)
+
+			w, err := NewSortedWriter(file, k)
+			require.NoError(t, err)
+			for _, d := range digests(t, k) {
+				require.NoError(t, w.Add(d))
+				require.NoError(t, w.Add(d)) // duplicates are skipped
+			}
+			require.NoError(t, w.Close())
+			require.NoError(t, file.Close())
+
+			sorted, err := OpenSorted(path)
+			require.NoError(t, err)
+			defer sorted.Close()
+
+			require.Equal(t, k, sorted.Kind())
+			require.Equal(t, len(breached), sorted.Len())
+			requireChecker(t, sorted)
+		})
+	}
+}
+
+func TestSortedWriter_Errors(t *testing.T) {
+	file, err := os.Create(filepath.Join(t.TempDir(), "pwned.sorted"))
+	require.NoError(t, err)
+	defer file.Close()
+
+	w, err := NewSortedWriter(file, KindSHA1)
+	require.NoError(t, err)
+
+	ds := digests(t, KindSHA1)
+	require.NoError(t, w.Add(ds[1]))
+	require.Error(t, w.Add(ds[0]))
+	require.Error(t, w.Add([]byte{1, 2, 3}))
+
+	_, err = NewSortedWriter(file, Kind(0))
+	require.Error(t, err)
+}
+
+func TestOpenSorted_Invalid(t *testing.T) {
+	dir := t.TempDir()
+
+	for name, data := range map[string][]byte{
+		"empty":     {},
+		"magic":     []byte("XXXX\x01\x01\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00"),
+		"truncated": append([]byte("PWSF\x01\x01\x00\x00\x02\x00\x00\x00\x00\x00\x00\x00"), make([]byte, 20)...),
+	} {
+		path := filepath.Join(dir, name)
+		require.NoError(t, os.WriteFile(path, data, 0o600))
+
+		_, err := OpenSorted(path)
+		require.Error(t, err, name)
+	}
+}
+
+func TestBloom(t *testing.T) {
+	bloom, err := NewBloom(KindSHA1, uint64(len(breached)), 0.0001)
+	require.NoError(t, err)
+	for _, d := range digests(t, KindSHA1) {
+		require.NoError(t, bloom.Add(d))
+	}
+	require.Error(t, bloom.Add([]byte{1}))
+
+	path := filepath.Join(t.TempDir(), "pwned.bloom")
+	file, err := os.Create(path)
+	require.NoError(t, err)
+	_, err = bloom.WriteTo(file)
+	require.NoError(t, err)
+	require.NoError(t, file.Close())
+
+	loaded, err := OpenBloom(path)
+	require.NoError(t, err)
+	require.Equal(t, bloom, loaded)
+	requireChecker(t, loaded)
+
+	data, err := os.ReadFile(path)
+	require.NoError(t, err)
+	_, err = ReadBloom(bytes.NewReader(data[:len(data)-1]))
+	require.Error(t, err)
+
+	_, err = NewBloom(KindSHA1, 10, 1.5)
+	require.Error(t, err)
+}
+
+func TestReadBloom_HostileHeader(t *testing.T) {
+	huge := header{magic: bloomMagic, kind: KindSHA1, extra: 4, count: maxBloomBits}.encode()
+	data := append(huge, make([]byte, 64)...)
+
+	_, err := ReadBloom(bytes.NewReader(data))
+	require.ErrorIs(t, err, errInvalidDataset)
+
+	path := filepath.Join(t.TempDir(), "hostile.bloom")
+	require.NoError(t, os.WriteFile(path, data, 0o600))
+	_, err = OpenBloom(path)
+	require.ErrorIs(t, err, errInvalidDataset)
+}
+
+func TestRangeChecker(t *testing.T) {
+	dir := t.TempDir()
+
+	byPrefix := map[string][]string{}
+	for _, d := range digests(t, KindSHA1) {
+		h := strings.ToUpper(hex.EncodeToString(d))
+		byPrefix[h[:PrefixLength]] = append(byPrefix[h[:PrefixLength]], h[PrefixLength:]+":42")
+	}
+	for prefix, lines := range byPrefix {
+		require.NoError(t, os.WriteFile(filepath.Join(dir, prefix+".txt"), []byte(strings.Join(lines, "\r\n")), 0o600))
+	}
+
+	// Give the negative case an existing, unrelated range file.
+	d, err := KindSHA1.Digest([]byte("correct horse battery staple"))
+	require.NoError(t, err)
+	prefix := strings.ToUpper(hex.EncodeToString(d))[:PrefixLength]
+	require.NoError(t, os.WriteFile(filepath.Join(dir, prefix+".txt"), []byte("0000000000000000000000000000000000A:1\n"), 0o600))
+
+	requireChecker(t, NewRangeChecker(NewDirSource(dir), KindSHA1))
+
+	_, err = NewDirSource(dir).Range(context.Background(), "../..")
+	require.Error(t, err)
+
+	_, err = NewRangeChecker(NewDirSource(t.TempDir()), KindSHA1).Breached(context.Background(), []byte("password"))
+	require.Error(t, err)
+}
+
+func TestValidator(t *testing.T) {
+	bloom, err := NewBloom(KindSHA1, uint64(len(breached)), 0.0001)
+	require.NoError(t, err)
+	for _, d := range digests(t, KindSHA1) {
+		require.NoError(t, bloom.Add(d))
+	}
+
+	v := NewValidator(bloom)
+	require.ErrorIs(t, v.Validate([]byte("letmein")), ErrBreached)
+	require.NoError(t, v.Validate([]byte("correct horse battery staple")))
+}
+
+func requireChecker(t *testing.T, c Checker) {
+	t.Helper()
+
+	for _, p := range breached {
+		ok, err := c.Breached(context.Background(), []byte(p))
+		require.NoError(t, err)
+		require.True(t, ok, p)
+	}
+
+	ok, err := c.Breached(context.Background(), []byte("correct horse battery staple"))
+	require.NoError(t, err)
+	require.False(t, ok)
+}
@@ -0,0 +1,116 @@
+package breach
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"encoding/hex"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/allisson/go-pwdhash/internal/zero"
+)
+
+// PrefixLength is the number of hex characters sent in a range query.
+const PrefixLength = 5
+
+// RangeSource answers k-anonymity range queries: given the first
+// PrefixLength uppercase hex characters of a digest, it returns the
+// remaining hex characters of every breached digest sharing that prefix.
+// Only the prefix leaves the caller, so a remote implementation never learns
+// the password or its full digest.
+type RangeSource interface {
+	Range(ctx context.Context, prefix string) ([]string, error)
+}
+
+// DirSource is a RangeSource over a directory of range files named
+// <PREFIX>.txt, each holding SUFFIX:COUNT lines, as written by the Pwned
+// Passwords downloader. It needs no network access.
+type DirSource struct {
+	dir string
+}
+
+// NewDirSource returns a DirSource reading range files from dir.
+func NewDirSource(dir string) *DirSource {
+	return &DirSource{dir: dir}
+}
+
+// Range returns the suffixes listed in the range file for prefix.
+func (d *DirSource) Range(ctx context.Context, prefix string) ([]string, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+
+	if !validPrefix(prefix) {
+		return nil, fmt.Errorf("invalid range prefix: %q", prefix)
+	}
+
+	data, err := os.ReadFile(filepath.Join(d.dir, prefix+".txt")) // #nosec G304 -- prefix is validated as hex
+	if err != nil {
+		return nil, err
+	}
+
+	var suffixes []string
+	scanner := bufio.NewScanner(bytes.NewReader(data))
+	for scanner.Scan() {
+		suffix, _, _ := strings.Cut(strings.TrimSpace(scanner.Text()), ":")
+		if suffix != "" {
+			suffixes = append(suffixes, strings.ToUpper(suffix))
+		}
+	}
+
+	return suffixes, scanner.Err()
+}
+
+// RangeChecker is a Checker that queries a RangeSource with the digest prefix
+// and compares the suffixes locally.
+type RangeChecker struct {
+	source RangeSource
+	kind   Kind
+}
+
+// NewRangeChecker returns a RangeChecker for digests of kind k.
+func NewRangeChecker(source RangeSource, k Kind) *RangeChecker {
+	return &RangeChecker{source: source, kind: k}
+}
+
+// Breached reports whether the digest of password is listed by the source.
+func (r *RangeChecker) Breached(ctx context.Context, password []byte) (bool, error) {
+	digest, err := r.kind.Digest(password)
+	if err != nil {
+		return false, err
+	}
+
+	defer zero.Bytes(digest)
+
+	full := strings.ToUpper(hex.EncodeToString(digest))
+
+	suffixes, err := r.source.Range(ctx, full[:PrefixLength])
+	if err != nil {
+		return false, err
+	}
+
+	for _, suffix := range suffixes {
+		if suffix == full[PrefixLength:] {
+			return true, nil
+		}
+	}
+
+	return false, nil
+}
+
+func validPrefix(prefix string) bool {
+	if len(prefix) != PrefixLength {
+		return false
+	}
+
+	for _, c := range prefix {
+		if !('0' <= c && c <= '9' || 'A' <= c && c <= 'F') {
+			return false
+		}
+	}
+
+	return true
+}
@@ -0,0 +1,236 @@
+package breach
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"encoding/binary"
+	"fmt"
+	"io"
+	"os"
+	"sort"
+
+	"github.com/allisson/go-pwdhash/internal/zero"
+)
+
+// Dataset files start with a 16-byte header: a 4-byte magic, a format
+// version, the Kind, one byte of format-specific data, a reserved byte, and a
+// little-endian uint64 (the record count for sorted files, the bit count for
+// Bloom filters).
+const (
+	headerSize    = 16
+	formatVersion = 1
+)
+
+var (
+	sortedMagic = [4]byte{'P', 'W', 'S', 'F'}
+	bloomMagic  = [4]byte{'P', 'W', 'B', 'F'}
+)
+
+type header struct {
+	magic [4]byte
+	kind  Kind
+	extra uint8
+	count uint64
+}
+
+func (h header) encode() []byte {
+	buf := make([]byte, headerSize)
+	copy(buf, h.magic[:])
+	buf[4] = formatVersion
+	buf[5] = byte(h.kind)
+	buf[6] = h.extra
+	binary.LittleEndian.PutUint64(buf[8:], h.count)
+	return buf
+}
+
+func decodeHeader(buf []byte, magic [4]byte) (header, error) {
+	if len(buf) != headerSize || !bytes.Equal(buf[:4], magic[:]) || buf[4] != formatVersion {
+		return header{}, errInvalidDataset
+	}
+
+	h := header{magic: magic, kind: Kind(buf[5]), extra: buf[6], count: binary.LittleEndian.Uint64(buf[8:])}
+	if h.kind.Size() == 0 {
+		return header{}, errInvalidDataset
+	}
+
+	return h, nil
+}
+
+// SortedWriter streams digests in ascending order into a SortedFile.
+type SortedWriter struct {
+	w     io.WriteSeeker
+	buf   *bufio.Writer
+	kind  Kind
+	count uint64
+	last  []byte
+}
+
+// NewSortedWriter writes a SortedFile of kind k to w. The header is
+// completed by Close, so w must support seeking.
+func NewSortedWriter(w io.WriteSeeker, k Kind) (*SortedWriter, error) {
+	if k.Size() == 0 {
+		return nil, fmt.Errorf("unsupported breach kind: %d", k)
+	}
+
+	buf := bufio.NewWriter(w)
+	if _, err := buf.Write(header{magic: sortedMagic, kind: k}.encode()); err != nil {
+		return nil, err
+	}
+
+	return &SortedWriter{w: w, buf: buf, kind: k}, nil
+}
+
+// Add appends digest, which must sort strictly after the previous one.
+// Duplicates are skipped.
+func (s *SortedWriter) Add(digest []byte) error {
+	if len(digest) != s.kind.Size() {
+		return fmt.Errorf("%w: digest length %d", errInvalidDataset, len(digest))
+	}
+
+	switch c := bytes.Compare(digest, s.last); {
+	case s.last != nil && c == 0:
+		return nil
+	case s.last != nil && c < 0:
+		return fmt.Errorf("%w: digests are not in ascending order", errInvalidDataset)
+	}
+
+	if _, err := s.buf.Write(digest); err != nil {
+		return err
+	}
+
+	s.last = append(s.last[:0], digest...)
+	s.count++
+
+	return nil
+}
+
+// Close flushes the records and writes the final header. It does not close
+// the underlying writer.
+func (s *SortedWriter) Close() error {
+	if err := s.buf.Flush(); err != nil {
+		return err
+	}
+
+	if _, err := s.w.Seek(0, io.SeekStart); err != nil {
+		return err
+	}
+
+	_, err := s.w.Write(header{magic: sortedMagic, kind: s.kind, count: s.count}.encode())
+
+	return err
+}
+
+// SortedFile answers lookups by binary search over a file of sorted
+// digests, reading only O(log n) records per check.
+type SortedFile struct {
+	r     io.ReaderAt
+	file  *os.File
+	kind  Kind
+	count uint64
+}
+
+// OpenSorted opens the SortedFile at path. Close releases the file.
+func OpenSorted(path string) (*SortedFile, error) {
+	file, err := os.Open(path) // #nosec G304 -- the path is chosen by the caller
+	if err != nil {
+		return nil, err
+	}
+
+	info, err := file.Stat()
+	if err != nil {
+		_ = file.Close()
+		return nil, err
+	}
+
+	s, err := NewSortedFile(file, info.Size())
+	if err != nil {
+		_ = file.Close()
+		return nil, err
+	}
+
+	s.file = file
+
+	return s, nil
+}
+
+// NewSortedFile reads a SortedFile of size bytes from r.
+func NewSortedFile(r io.ReaderAt, size int64) (*SortedFile, error) {
+	buf := make([]byte, headerSize)
+	if _, err := r.ReadAt(buf, 0); err != nil {
+		return nil, errInvalidDataset
+	}
+
+	h, err := decodeHeader(buf, sortedMagic)
+	if err != nil {
+		return nil, err
+	}
+
+	if uint64(size-headerSize) != h.count*uint64(h.kind.Size()) { // #nosec G115 -- size is at least headerSize here
+		return nil, fmt.Errorf("%w: truncated sorted file", errInvalidDataset)
+	}
+
+	return &SortedFile{r: r, kind: h.kind, count: h.count}, nil
+}
+
+// Kind reports the digest the file is keyed by.
+func (s *SortedFile) Kind() Kind {
+	return s.kind
+}
+
+// Len reports the number of digests in the file.
+func (s *SortedFile) Len() int {
+	return int(s.count) // #nosec G115 -- bounded by the file size
+}
+
+// Breached reports whether the digest of password is in the file.
+func (s *SortedFile) Breached(_ context.Context, password []byte) (bool, error) {
+	digest, err := s.kind.Digest(password)
+	if err != nil {
+		return false, err
+	}
+
+	defer zero.Bytes(digest)
+
+	return s.Contains(digest)
+}
+
+// Contains reports whether digest is in the file.
+func (s *SortedFile) Contains(digest []byte) (bool, error) {
+	size := s.kind.Size()
+	record := make([]byte, size)
+
+	var readErr error
+	i := sort.Search(s.Len(), func(i int) bool {
+		if readErr != nil {
+			return true
+		}
+		if _, err := s.r.ReadAt(record, headerSize+int64(i)*int64(size)); err != nil {
+			readErr = err
+			return true
+		}
+		return bytes.Compare(record, digest) >= 0
+	})
+	if readErr != nil {
+		return false, readErr
+	}
+
+	if i == s.Len() {
+		return false, nil
+	}
+
+	if _, err := s.r.ReadAt(record, headerSize+int64(i)*int64(size)); err != nil {
+		return false, err
+	}
+
+	return bytes.Equal(record, digest), nil
+}
+
+// Close releases the file opened by OpenSorted.
+func (s *SortedFile) Close() error {
+	if s.file == nil {
+		return nil
+	}
+
+	return s.file.Close()
+}
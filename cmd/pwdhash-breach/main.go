@@ -0,0 +1,196 @@
+// Command pwdhash-breach builds the offline datasets read by the breach
+// package from a Pwned Passwords download.
+//
+// Usage:
+//
+//	pwdhash-breach [-kind sha1|ntlm] [-format sorted|bloom] [-fp rate] [-n count] [-ranges] -out file input...
+//
+// Inputs are text files of HEX:COUNT lines ordered by hash, such as the
+// combined file from the Pwned Passwords downloader. With -ranges each input
+// is instead a directory of <PREFIX>.txt range files holding SUFFIX:COUNT
+// lines. The sorted format requires ascending input; the bloom format
+// accepts any order and counts the inputs first unless -n is given.
+package main
+
+import (
+	"bufio"
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"github.com/allisson/go-pwdhash/breach"
+)
+
+func main() {
+	if err := run(os.Args[1:], os.Stderr); err != nil {
+		fmt.Fprintln(os.Stderr, "pwdhash-breach:", err)
+		os.Exit(1)
+	}
+}
+
+func run(args []string, stderr io.Writer) error {
+	flags := flag.NewFlagSet("pwdhash-breach", flag.ContinueOnError)
+	flags.SetOutput(stderr)
+
+	kindName := flags.String("kind", "sha1", "digest of the input: sha1 or ntlm")
+	format := flags.String("format", "sorted", "output format: sorted or bloom")
+	falsePositive := flags.Float64("fp", 0.001, "bloom filter false-positive rate")
+	count := flags.Uint64("n", 0, "expected number of digests for bloom sizing (0 counts the input)")
+	ranges := flags.Bool("ranges", false, "inputs are directories of <PREFIX>.txt range files")
+	out := flags.String("out", "", "output file")
+
+	if err := flags.Parse(args); err != nil {
+		return err
+	}
+
+	if *out == "" || flags.NArg() == 0 {
+		flags.Usage()
+		return errors.New("an output file and at least one input are required")
+	}
+
+	var kind breach.Kind
+	switch *kindName {
+	case "sha1":
+		kind = breach.KindSHA1
+	case "ntlm":
+		kind = breach.KindNTLM
+	default:
+		return fmt.Errorf("unknown kind: %s", *kindName)
+	}
+
+	src := source{kind: kind, inputs: flags.Args(), ranges: *ranges}
+
+	switch *format {
+	case "sorted":
+		return buildSorted(src, *out)
+	case "bloom":
+		return buildBloom(src, *out, *count, *falsePositive)
+	}
+
+	return fmt.Errorf("unknown format: %s", *format)
+}
+
+func buildSorted(src source, out string) error {
+	file, err := os.OpenFile(out, os.O_CREATE|os.O_TRUNC|os.O_WRONLY, 0o600) // #nosec G304 -- the path is chosen by the operator
+	if err != nil {
+		return err
+	}
+	defer func() { _ = file.Close() }()
+
+	w, err := breach.NewSortedWriter(file, src.kind)
+	if err != nil {
+		return err
+	}
+
+	if err := src.each(w.Add); err != nil {
+		return err
+	}
+
+	if err := w.Close(); err != nil {
+		return err
+	}
+
+	return file.Close()
+}
+
+func buildBloom(src source, out string, n uint64, falsePositive float64) error {
+	if n == 0 {
+		if err := src.each(func([]byte) error { n++; return nil }); err != nil {
+			return err
+		}
+	}
+
+	bloom, err := breach.NewBloom(src.kind, n, falsePositive)
+	if err != nil {
+		return err
+	}
+
+	if err := src.each(bloom.Add); err != nil {
+		return err
+	}
+
+	file, err := os.OpenFile(out, os.O_CREATE|os.O_TRUNC|os.O_WRONLY, 0o600) // #nosec G304 -- the path is chosen by the operator
+	if err != nil {
+		return err
+	}
+	defer func() { _ = file.Close() }()
+
+	if _, err := bloom.WriteTo(file); err != nil {
+		return err
+	}
+
+	return file.Close()
+}
+
+// source iterates the digests of every input in order.
+type source struct {
+	kind   breach.Kind
+	inputs []string
+	ranges bool
+}
+
+func (s source) each(fn func([]byte) error) error {
+	for _, input := range s.inputs {
+		if !s.ranges {
+			if err := s.readFile(input, "", fn); err != nil {
+				return err
+			}
+			continue
+		}
+
+		entries, err := os.ReadDir(input)
+		if err != nil {
+			return err
+		}
+
+		names := make([]string, 0, len(entries))
+		for _, e := range entries {
+			if !e.IsDir() && strings.HasSuffix(e.Name(), ".txt") {
+				names = append(names, e.Name())
+			}
+		}
+		sort.Strings(names)
+
+		for _, name := range names {
+			prefix := strings.ToUpper(strings.TrimSuffix(name, ".txt"))
+			if err := s.readFile(filepath.Join(input, name), prefix, fn); err != nil {
+				return err
+			}
+		}
+	}
+
+	return nil
+}
+
+// readFile feeds each line of path, prefixed with prefix, to fn.
+func (s source) readFile(path, prefix string, fn func([]byte) error) error {
+	file, err := os.Open(path) // #nosec G304 -- the path is chosen by the operator
+	if err != nil {
+		return err
+	}
+	defer func() { _ = file.Close() }()
+
+	scanner := bufio.NewScanner(file)
+	for line := 1; scanner.Scan(); line++ {
+		text := strings.TrimSpace(scanner.Text())
+		if text == "" {
+			continue
+		}
+
+		digest, err := breach.ParseLine(s.kind, prefix+text)
+		if err != nil {
+			return fmt.Errorf("%s:%d: %w", path, line, err)
+		}
+
+		if err := fn(digest); err != nil {
+			return fmt.Errorf("%s:%d: %w", path, line, err)
+		}
+	}
+
+	return scanner.Err()
+}
@@ -0,0 +1,119 @@
+package main
+
+import (
+	"bytes"
+	"context"
+	"crypto/sha1" // #nosec G505 -- matches the Pwned Passwords corpus
+	"encoding/hex"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/allisson/go-pwdhash/breach"
+)
+
+func writeCorpus(t *testing.T, dir string) (string, string) {
+	t.Helper()
+
+	var hashes []string
+	for _, p := range []string{"password", "123456", "qwerty", "letmein"} {
+		sum := sha1.Sum([]byte(p)) // #nosec G401 -- test corpus
+		hashes = append(hashes, strings.ToUpper(hex.EncodeToString(sum[:])))
+	}
+	sort.Strings(hashes)
+
+	combined := filepath.Join(dir, "pwned.txt")
+	var lines []string
+	for _, h := range hashes {
+		lines = append(lines, h+":7")
+	}
+	require.NoError(t, os.WriteFile(combined, []byte(strings.Join(lines, "\n")+"\n"), 0o600))
+
+	ranges := filepath.Join(dir, "ranges")
+	require.NoError(t, os.Mkdir(ranges, 0o700))
+	for _, h := range hashes {
+		path := filepath.Join(ranges, h[:5]+".txt")
+		f, err := os.OpenFile(path, os.O_CREATE|os.O_APPEND|os.O_WRONLY, 0o600)
+		require.NoError(t, err)
+		_, err = f.WriteString(h[5:] + ":7\r\n")
+		require.NoError(t, err)
+		require.NoError(t, f.Close())
+	}
+
+	return combined, ranges
+}
+
+func requireBreached(t *testing.T, c breach.Checker) {
+	t.Helper()
+
+	ok, err := c.Breached(context.Background(), []byte("letmein"))
+	require.NoError(t, err)
+	require.True(t, ok)
+
+	ok, err = c.Breached(context.Background(), []byte("correct horse battery staple"))
+	require.NoError(t, err)
+	require.False(t, ok)
+}
+
+func TestRun(t *testing.T) {
+	dir := t.TempDir()
+	combined, ranges := writeCorpus(t, dir)
+
+	tests := []struct {
+		name string
+		args []string
+	}{
+		{name: "sorted", args: []string{"-format", "sorted", combined}},
+		{name: "sortedRanges", args: []string{"-format", "sorted", "-ranges", ranges}},
+		{name: "bloom", args: []string{"-format", "bloom", "-fp", "0.0001", combined}},
+		{name: "bloomRanges", args: []string{"-format", "bloom", "-n", "4", "-ranges", ranges}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			out := filepath.Join(t.TempDir(), "out")
+			require.NoError(t, run(append([]string{"-out", out}, tt.args...), &bytes.Buffer{}))
+
+			if strings.HasPrefix(tt.name, "bloom") {
+				bloom, err := breach.OpenBloom(out)
+				require.NoError(t, err)
+				requireBreached(t, bloom)
+				return
+			}
+
+			sorted, err := breach.OpenSorted(out)
+			require.NoError(t, err)
+			defer sorted.Close()
+			require.Equal(t, 4, sorted.Len())
+			requireBreached(t, sorted)
+		})
+	}
+}
+
+func TestRun_Errors(t *testing.T) {
+	dir := t.TempDir()
+	combined, _ := writeCorpus(t, dir)
+	out := filepath.Join(dir, "out")
+
+	unsorted := filepath.Join(dir, "unsorted.txt")
+	data, err := os.ReadFile(combined)
+	require.NoError(t, err)
+	lines := strings.Split(strings.TrimSpace(string(data)), "\n")
+	lines[0], lines[1] = lines[1], lines[0]
+	require.NoError(t, os.WriteFile(unsorted, []byte(strings.Join(lines, "\n")), 0o600))
+
+	for _, args := range [][]string{
+		{combined},
+		{"-out", out},
+		{"-out", out, "-kind", "md5", combined},
+		{"-out", out, "-format", "xml", combined},
+		{"-out", out, "-kind", "ntlm", combined},
+		{"-out", out, unsorted},
+	} {
+		require.Error(t, run(args, &bytes.Buffer{}), args)
+	}
+}
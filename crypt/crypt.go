@@ -0,0 +1,85 @@
+// Package crypt provides a verify-only adapter for migrating crypt(3) hashes.
+//
+// It understands md5-crypt ($1$), Apache apr1 ($apr1$), sha256-crypt ($5$),
+// sha512-crypt ($6$), and yescrypt ($y$) as found in /etc/shadow and htpasswd
+// files. NeedsRehash reports true, so users imported from such a file move to
+// the current hasher one login at a time.
+package crypt
+
+import (
+	"errors"
+	"strings"
+
+	"github.com/allisson/go-pwdhash/internal/subtle"
+	"github.com/allisson/go-pwdhash/internal/zero"
+)
+
+var errInvalidHash = errors.New("invalid crypt hash")
+
+// setting is a parsed crypt string that can recompute its checksum.
+type setting interface {
+	checksum(password []byte) (string, error)
+}
+
+// Verifier checks md5-crypt, apr1, sha256-crypt, sha512-crypt, and yescrypt hashes.
+type Verifier struct{}
+
+// New returns a crypt Verifier.
+func New() *Verifier {
+	return &Verifier{}
+}
+
+// ID reports the scheme identifier.
+func (v *Verifier) ID() string {
+	return "crypt"
+}
+
+// Recognize reports whether encoded is in one of the supported crypt formats.
+func (v *Verifier) Recognize(encoded string) bool {
+	_, _, err := parse(encoded)
+	return err == nil
+}
+
+// Verify recomputes the crypt checksum and compares it in constant time.
+func (v *Verifier) Verify(password []byte, encoded string) (bool, error) {
+	defer zero.Bytes(password)
+
+	s, want, err := parse(encoded)
+	if err != nil {
+		return false, err
+	}
+
+	got, err := s.checksum(password)
+	if err != nil {
+		return false, err
+	}
+
+	return subtle.ConstantTimeCompare([]byte(got), []byte(want)), nil
+}
+
+// NeedsRehash always reports true for crypt hashes.
+func (v *Verifier) NeedsRehash(encoded string) (bool, error) {
+	if _, _, err := parse(encoded); err != nil {
+		return false, err
+	}
+
+	return true, nil
+}
+
+// parse splits encoded into its scheme setting and the stored checksum.
+func parse(encoded string) (setting, string, error) {
+	switch {
+	case strings.HasPrefix(encoded, md5Prefix):
+		return parseMD5(encoded, md5Prefix)
+	case strings.HasPrefix(encoded, apr1Prefix):
+		return parseMD5(encoded, apr1Prefix)
+	case strings.HasPrefix(encoded, sha256Prefix):
+		return parseSHA(encoded, sha256Prefix)
+	case strings.HasPrefix(encoded, sha512Prefix):
+		return parseSHA(encoded, sha512Prefix)
+	case strings.HasPrefix(encoded, yescryptPrefix):
+		return parseYescrypt(encoded)
+	}
+
+	return nil, "", errInvalidHash
+}
@@ -0,0 +1,119 @@
+package crypt
+
+import (
+	"bytes"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/allisson/go-pwdhash/internal/shacrypt"
+)
+
+func TestVerifier_Verify(t *testing.T) {
+	tests := []struct {
+		name     string
+		password string
+		encoded  string
+	}{
+		{
+			name:     "md5crypt",
+			password: "password",
+			encoded:  "$1$saltstri$qQY4WxjABChYG1ccLpfkz/",
+		},
+		{
+			name:     "apr1",
+			password: "password",
+			encoded:  "$apr1$saltstri$KbmdckUzuN1qd7Gpo8DEL.",
+		},
+		{
+			name:     "sha256crypt",
+			password: "password",
+			encoded:  "$5$saltstring$OH4IDuTlsuTYPdED1gsuiRMyTAwNlRWyA6Xr3I4/dQ5",
+		},
+		{
+			name:     "sha256cryptRounds",
+			password: "password",
+			encoded:  "$5$rounds=10000$saltstringsaltst$DnR6.aMZDwOMqtynA.o2eobA3dYZepULoyGw/CZ4ID0",
+		},
+		{
+			name:     "sha512crypt",
+			password: "password",
+			encoded:  "$6$saltstring$adDbXsJjcDlq2662QPgd.tkSOVmnG9Tt3oXl4HR60SusC3AGjirnDenVZp3DGwLwqy6iYKCzannhaX9DR72nN1",
+		},
+		{
+			name:     "sha512cryptRoundsBelowMinimum",
+			password: "password",
+			encoded:  "$6$rounds=10$roundsalt$.5mYe7AXZNNji.rZibqlffO.3YoDcHSMpZ5Cq8cnumEpfdJgb2OF7BpRYEbbKR2anPgTQaFLkwuLmK7AYQU0/0",
+		},
+		{
+			name:     "yescrypt",
+			password: "password",
+			encoded:  "$y$j9T$F5Jx5fExrKuPp53xLKQ..1$tnSYvahCwPBHKZUspmcxMfb0.WiB9W.zEaKlOBL35rC",
+		},
+		{
+			name:     "yescryptWithoutPrehash",
+			password: "password",
+			encoded:  "$y$j75$jAFVU2eDwU60paJf.HqOu0$TeLRYxYvL1CaBWCe45lmlbimw5TOq3ZH585zHcEPgw0",
+		},
+		{
+			name:     "yescryptEmptyPassword",
+			password: "",
+			encoded:  "$y$j9T$F5Jx5fExrKuPp53xLKQ..1$5P1uc1zvKhieqEtKttbwCQrTPXpY1cK9wEnTDKAqLD8",
+		},
+		{
+			name:     "yescryptClassicScrypt",
+			password: "password",
+			encoded:  "$y$.9/$abcdefgh$ZTEBZxyapL2wBET1Z.ORrejFsCQGUo29oHgdhgPP7z7",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			verifier := New()
+			require.True(t, verifier.Recognize(tt.encoded))
+
+			ok, err := verifier.Verify([]byte(tt.password), tt.encoded)
+			require.NoError(t, err)
+			require.True(t, ok)
+
+			ok, err = verifier.Verify([]byte("wrong"), tt.encoded)
+			require.NoError(t, err)
+			require.False(t, ok)
+
+			needs, err := verifier.NeedsRehash(tt.encoded)
+			require.NoError(t, err)
+			require.True(t, needs)
+		})
+	}
+}
+
+func TestVerifier_Errors(t *testing.T) {
+	tests := []struct {
+		name    string
+		encoded string
+	}{
+		{name: "unknownFormat", encoded: "$2b$04$p4wSFWnH/3cP53yyCWSnEuxvwVcro/yOPi/Vi6hez5ZvWA8q1d7WO"},
+		{name: "md5MissingChecksum", encoded: "$1$saltstri"},
+		{name: "md5ShortChecksum", encoded: "$1$saltstri$qQY4Wx"},
+		{name: "shaBadRounds", encoded: "$5$rounds=abc$salt$OH4IDuTlsuTYPdED1gsuiRMyTAwNlRWyA6Xr3I4/dQ5"},
+		{name: "shaRoundsCeiling", encoded: "$5$rounds=999999999$salt$OH4IDuTlsuTYPdED1gsuiRMyTAwNlRWyA6Xr3I4/dQ5"},
+		{name: "yescryptUnsupportedFlavor", encoded: "$y$/9T$abcdefgh$ZTEBZxyapL2wBET1Z.ORrejFsCQGUo29oHgdhgPP7z7"},
+		{name: "yescryptMemoryCeiling", encoded: "$y$jMT$abcdefgh$ZTEBZxyapL2wBET1Z.ORrejFsCQGUo29oHgdhgPP7z7"},
+		{name: "yescryptBadSalt", encoded: "$y$j9T$a$ZTEBZxyapL2wBET1Z.ORrejFsCQGUo29oHgdhgPP7z7"},
+		{name: "yescryptRom", encoded: "$y$j9T7.$abcdefgh$ZTEBZxyapL2wBET1Z.ORrejFsCQGUo29oHgdhgPP7z7"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := New().Verify([]byte("password"), tt.encoded)
+			require.Error(t, err)
+		})
+	}
+}
+
+func TestVerifier_RejectsLongPasswords(t *testing.T) {
+	password := bytes.Repeat([]byte("a"), 64*1024)
+
+	_, err := New().Verify(password, "$6$saltstring$adDbXsJjcDlq2662QPgd.tkSOVmnG9Tt3oXl4HR60SusC3AGjirnDenVZp3DGwLwqy6iYKCzannhaX9DR72nN1")
+	require.ErrorIs(t, err, shacrypt.ErrPasswordTooLong)
+}
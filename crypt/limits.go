@@ -0,0 +1,8 @@
+package crypt
+
+const (
+	MaxRounds      = 10_000_000
+	MaxMemory      = 1024 * 1024 * 1024
+	MaxParallelism = 16
+	MaxTime        = 16
+)
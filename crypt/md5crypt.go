@@ -0,0 +1,89 @@
+package crypt
+
+import (
+	"crypto/md5" // #nosec G501 -- required to verify legacy md5-crypt hashes
+	"strings"
+
+	"github.com/allisson/go-pwdhash/internal/encoding"
+	"github.com/allisson/go-pwdhash/internal/zero"
+)
+
+const (
+	md5Prefix      = "$1$"
+	apr1Prefix     = "$apr1$"
+	md5SaltMax     = 8
+	md5Rounds      = 1000
+	md5ChecksumLen = 22
+)
+
+// md5Order lists digest bytes in the order they are fed to the hash64 encoder.
+var md5Order = []int{12, 6, 0, 13, 7, 1, 14, 8, 2, 15, 9, 3, 5, 10, 4, 11}
+
+// md5Setting is a $1$ or $apr1$ salt; the two differ only in their magic prefix.
+type md5Setting struct {
+	magic string
+	salt  []byte
+}
+
+func parseMD5(encoded, magic string) (setting, string, error) {
+	salt, want, ok := strings.Cut(strings.TrimPrefix(encoded, magic), "$")
+	if !ok || len(want) != md5ChecksumLen {
+		return nil, "", errInvalidHash
+	}
+
+	if len(salt) > md5SaltMax {
+		salt = salt[:md5SaltMax]
+	}
+
+	return &md5Setting{magic: magic, salt: []byte(salt)}, want, nil
+}
+
+// checksum implements the md5-crypt algorithm from FreeBSD's crypt-md5.c.
+func (s *md5Setting) checksum(password []byte) (string, error) {
+	h := md5.New() // #nosec G401 -- legacy verification only
+	h.Write(password)
+	h.Write(s.salt)
+	h.Write(password)
+	alternate := h.Sum(nil)
+	defer zero.Bytes(alternate)
+
+	h.Reset()
+	h.Write(password)
+	h.Write([]byte(s.magic))
+	h.Write(s.salt)
+	for i := len(password); i > 0; i -= md5.Size {
+		h.Write(alternate[:min(i, md5.Size)])
+	}
+	for i := len(password); i > 0; i >>= 1 {
+		if i&1 != 0 {
+			h.Write([]byte{0})
+		} else {
+			h.Write(password[:1])
+		}
+	}
+	final := h.Sum(nil)
+	defer zero.Bytes(final)
+
+	for i := range md5Rounds {
+		h.Reset()
+		if i&1 != 0 {
+			h.Write(password)
+		} else {
+			h.Write(final)
+		}
+		if i%3 != 0 {
+			h.Write(s.salt)
+		}
+		if i%7 != 0 {
+			h.Write(password)
+		}
+		if i&1 != 0 {
+			h.Write(final)
+		} else {
+			h.Write(password)
+		}
+		final = h.Sum(final[:0])
+	}
+
+	return encoding.EncodeHash64Order(final, md5Order), nil
+}
@@ -0,0 +1,72 @@
+package crypt
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+
+	"github.com/allisson/go-pwdhash/internal/shacrypt"
+)
+
+const (
+	sha256Prefix  = "$5$"
+	sha512Prefix  = "$6$"
+	roundsPrefix  = "rounds="
+	shaSaltMax    = 16
+	roundsDefault = 5000
+	roundsMin     = 1000
+)
+
+// shaSetting holds the salt and round count of a $5$ or $6$ hash.
+type shaSetting struct {
+	sum    func(password, salt []byte, rounds int) (string, error)
+	salt   []byte
+	rounds int
+}
+
+// parseSHA handles $5$[rounds=<n>$]<salt>$<checksum> and its $6$ counterpart.
+//
+// Round counts below the specified minimum of 1000 are raised to it, as the
+// reference implementation does; counts above MaxRounds are rejected.
+func parseSHA(encoded, prefix string) (setting, string, error) {
+	s := &shaSetting{sum: shacrypt.SHA256, rounds: roundsDefault}
+	checksumLen := 43
+	if prefix == sha512Prefix {
+		s.sum, checksumLen = shacrypt.SHA512, 86
+	}
+
+	rest := strings.TrimPrefix(encoded, prefix)
+	if strings.HasPrefix(rest, roundsPrefix) {
+		value, after, ok := strings.Cut(strings.TrimPrefix(rest, roundsPrefix), "$")
+		if !ok {
+			return nil, "", errInvalidHash
+		}
+
+		rounds, err := strconv.ParseUint(value, 10, 32)
+		if err != nil {
+			return nil, "", errInvalidHash
+		}
+		if rounds > MaxRounds {
+			return nil, "", fmt.Errorf("crypt rounds too high")
+		}
+
+		s.rounds = max(int(rounds), roundsMin) // #nosec G115 -- bounded by MaxRounds
+		rest = after
+	}
+
+	salt, want, ok := strings.Cut(rest, "$")
+	if !ok || len(want) != checksumLen {
+		return nil, "", errInvalidHash
+	}
+
+	if len(salt) > shaSaltMax {
+		salt = salt[:shaSaltMax]
+	}
+	s.salt = []byte(salt)
+
+	return s, want, nil
+}
+
+func (s *shaSetting) checksum(password []byte) (string, error) {
+	return s.sum(password, s.salt, s.rounds)
+}
@@ -0,0 +1,563 @@
+package crypt
+
+import (
+	"crypto/hmac"
+	"crypto/pbkdf2"
+	"crypto/sha256"
+	"encoding/binary"
+	"fmt"
+	"math/bits"
+	"strings"
+
+	"github.com/allisson/go-pwdhash/internal/encoding"
+	"github.com/allisson/go-pwdhash/internal/zero"
+)
+
+const (
+	yescryptPrefix      = "$y$"
+	yescryptChecksumLen = 43
+
+	// yescryptRW selects the read-write mode; yescryptDefaults is the only
+	// pwxform flavour defined by the reference implementation (6 rounds,
+	// gather 4, simple 2, 12 KiB S-box) and the one libxcrypt emits.
+	yescryptRW       = 0x002
+	yescryptDefaults = 0x0b6
+	yescryptFlavors  = 0x3fc
+
+	pwxRounds  = 6
+	pwxGather  = 4
+	pwxSimple  = 2
+	sboxWords  = 3 * 256 * pwxSimple * 2
+	sboxBlocks = sboxWords * 4 / 128
+	sboxMask   = 255 * pwxSimple * 8
+	sboxWrap   = 256*pwxSimple - 1
+)
+
+// yescryptSetting holds the decoded cost parameters and salt of a $y$ hash.
+type yescryptSetting struct {
+	flags uint32
+	n     int
+	r     int
+	p     int
+	t     int
+	salt  []byte
+}
+
+// parseYescrypt handles $y$<params>$<salt>$<checksum>, where params packs the
+// flavour, log2(N), r, and an optional bitmask of p, t, g, and NROM.
+//
+// Classic scrypt (flavour 0) and the standard read-write flavour are supported;
+// hash upgrades (g) and ROMs are rejected, as are costs above the package limits.
+func parseYescrypt(encoded string) (setting, string, error) {
+	rest := strings.TrimPrefix(encoded, yescryptPrefix)
+
+	end := strings.LastIndexByte(rest, '$')
+	if end < 0 || len(rest)-end-1 != yescryptChecksumLen {
+		return nil, "", errInvalidHash
+	}
+	rest, want := rest[:end], rest[end+1:]
+
+	flavor, rest, err := decodeParam(rest, 0)
+	if err != nil {
+		return nil, "", err
+	}
+
+	s := &yescryptSetting{p: 1}
+	switch {
+	case flavor == 0:
+	case flavor == yescryptRW+(yescryptDefaults>>2):
+		s.flags = yescryptDefaults
+	case flavor < yescryptRW+(yescryptFlavors>>2):
+		return nil, "", fmt.Errorf("unsupported yescrypt flavor")
+	default:
+		return nil, "", errInvalidHash
+	}
+
+	logN, rest, err := decodeParam(rest, 1)
+	if err != nil {
+		return nil, "", err
+	}
+
+	r, rest, err := decodeParam(rest, 1)
+	if err != nil {
+		return nil, "", err
+	}
+
+	var p, t uint32 = 1, 0
+	if !strings.HasPrefix(rest, "$") {
+		var have uint32
+		if have, rest, err = decodeParam(rest, 1); err != nil {
+			return nil, "", err
+		}
+		if have&1 != 0 {
+			if p, rest, err = decodeParam(rest, 2); err != nil {
+				return nil, "", err
+			}
+		}
+		if have&2 != 0 {
+			if t, rest, err = decodeParam(rest, 1); err != nil {
+				return nil, "", err
+			}
+		}
+		if have&^3 != 0 {
+			return nil, "", fmt.Errorf("yescrypt upgrades and ROMs are not supported")
+		}
+	}
+
+	salt, ok := strings.CutPrefix(rest, "$")
+	if !ok {
+		return nil, "", errInvalidHash
+	}
+
+	if s.salt, err = encoding.DecodeHash64(salt); err != nil {
+		return nil, "", errInvalidHash
+	}
+
+	if logN < 2 || logN > 30 {
+		return nil, "", fmt.Errorf("yescrypt N out of range")
+	}
+	if p > MaxParallelism {
+		return nil, "", fmt.Errorf("yescrypt parallelism too high")
+	}
+	if t > MaxTime {
+		return nil, "", fmt.Errorf("yescrypt time too high")
+	}
+	if uint64(128)*uint64(r)<<logN > MaxMemory || uint64(128)*uint64(r)*uint64(p) > MaxMemory {
+		return nil, "", fmt.Errorf("yescrypt memory too high")
+	}
+
+	s.n, s.r, s.p, s.t = 1<<logN, int(r), int(p), int(t)
+	if s.flags&yescryptRW != 0 && s.n/s.p <= 3 {
+		return nil, "", fmt.Errorf("yescrypt N too low for parallelism")
+	}
+
+	return s, want, nil
+}
+
+// decodeParam reads one variable-length integer from the front of s; small
+// values take a single character and larger ones spill into following characters.
+func decodeParam(s string, min uint32) (uint32, string, error) {
+	if s == "" {
+		return 0, "", errInvalidHash
+	}
+
+	c := strings.IndexByte(cryptAlphabet, s[0])
+	if c < 0 {
+		return 0, "", errInvalidHash
+	}
+
+	value := uint64(min)
+	start, end, chars, shift := 0, 47, 1, 0
+	for c > end {
+		value += uint64(end+1-start) << shift
+		start = end + 1
+		end = start + (62-end)/2
+		chars++
+		shift += 6
+	}
+	value += uint64(c-start) << shift
+
+	if len(s) < chars {
+		return 0, "", errInvalidHash
+	}
+	for i := 1; i < chars; i++ {
+		c := strings.IndexByte(cryptAlphabet, s[i])
+		if c < 0 {
+			return 0, "", errInvalidHash
+		}
+		shift -= 6
+		value += uint64(c) << shift
+	}
+
+	if value > 1<<32-1 {
+		return 0, "", errInvalidHash
+	}
+
+	return uint32(value), s[chars:], nil
+}
+
+// cryptAlphabet is the "./0-9A-Za-z" alphabet used for yescrypt parameters.
+const cryptAlphabet = "./0123456789ABCDEFGHIJKLMNOPQRSTUVWXYZabcdefghijklmnopqrstuvwxyz"
+
+// checksum implements yescrypt_kdf from the yescrypt 1.x reference code,
+// including the SCRAM-style ClientKey/StoredKey finalization.
+func (s *yescryptSetting) checksum(password []byte) (string, error) {
+	if s.flags&yescryptRW != 0 && s.n/s.p >= 0x100 && s.n/s.p*s.r >= 0x20000 {
+		prehashed, err := s.body(password, s.n>>6, 0, true)
+		if err != nil {
+			return "", err
+		}
+
+		defer zero.Bytes(prehashed)
+		password = prehashed
+	}
+
+	key, err := s.body(password, s.n, s.t, false)
+	if err != nil {
+		return "", err
+	}
+
+	defer zero.Bytes(key)
+
+	return encoding.EncodeHash64(key), nil
+}
+
+func (s *yescryptSetting) body(password []byte, n, t int, prehash bool) ([]byte, error) {
+	passwd := password
+	if s.flags != 0 {
+		label := "yescrypt"
+		if prehash {
+			label = "yescrypt-prehash"
+		}
+
+		mac := hmac.New(sha256.New, []byte(label))
+		mac.Write(password)
+		passwd = mac.Sum(nil)
+		defer zero.Bytes(passwd)
+	}
+
+	raw, err := pbkdf2.Key(sha256.New, string(passwd), s.salt, 1, 128*s.r*s.p)
+	if err != nil {
+		return nil, err
+	}
+
+	defer zero.Bytes(raw)
+
+	if s.flags != 0 {
+		copy(passwd, raw[:sha256.Size])
+	}
+
+	b := make([]uint32, len(raw)/4)
+	defer wipeWords(b)
+	for i := range b {
+		b[i] = binary.LittleEndian.Uint32(raw[i*4:])
+	}
+
+	m := &mixer{
+		r:  s.r,
+		rw: s.flags&yescryptRW != 0,
+		v:  make([]uint32, 32*s.r*n),
+		x:  make([]uint32, 32*s.r),
+		y:  make([]uint32, 32*s.r),
+	}
+	defer m.wipe()
+
+	if s.p == 1 || m.rw {
+		m.smix(b, n, s.p, t, passwd)
+	} else {
+		for i := range s.p {
+			m.smix(b[i*32*s.r:(i+1)*32*s.r], n, 1, t, nil)
+		}
+	}
+
+	for i, w := range b {
+		binary.LittleEndian.PutUint32(raw[i*4:], w)
+	}
+
+	key, err := pbkdf2.Key(sha256.New, string(passwd), raw, 1, sha256.Size)
+	if err != nil {
+		return nil, err
+	}
+
+	if s.flags != 0 && !prehash {
+		mac := hmac.New(sha256.New, key)
+		mac.Write([]byte("Client Key"))
+		clientKey := mac.Sum(nil)
+		defer zero.Bytes(clientKey)
+
+		stored := sha256.Sum256(clientKey)
+		copy(key, stored[:])
+		zero.Bytes(stored[:])
+	}
+
+	return key, nil
+}
+
+// mixer holds the working memory of one yescrypt computation. Blocks in x and
+// v are kept in the reference code's SIMD-shuffled word order, which pwxform
+// observes through its S-box lookups.
+type mixer struct {
+	r      int
+	rw     bool
+	v      []uint32
+	x      []uint32
+	y      []uint32
+	sboxes [][]uint32
+}
+
+func (m *mixer) wipe() {
+	wipeWords(m.v)
+	wipeWords(m.x)
+	wipeWords(m.y)
+	for _, s := range m.sboxes {
+		wipeWords(s)
+	}
+}
+
+func (m *mixer) smix(b []uint32, n, p, t int, passwd []byte) {
+	s := 32 * m.r
+
+	chunk := n / p
+	loopAll := chunk
+	switch {
+	case m.rw && t <= 1:
+		if t == 1 {
+			loopAll *= 2
+		}
+		loopAll = (loopAll + 2) / 3
+	case m.rw:
+		loopAll *= t - 1
+	case t == 1:
+		loopAll += (loopAll + 1) / 2
+	case t > 1:
+		loopAll *= t
+	}
+
+	loopRW := 0
+	if m.rw {
+		loopRW = loopAll / p
+	}
+
+	chunk &^= 1
+	loopAll = (loopAll + 1) &^ 1
+	loopRW = (loopRW + 1) &^ 1
+
+	ctxs := make([]*pwxform, p)
+	for i := range p {
+		start := i * chunk
+		np := chunk
+		if i == p-1 {
+			np = n - start
+		}
+
+		bp := b[i*s : (i+1)*s]
+		vp := m.v[start*s:]
+
+		if m.rw {
+			sbox := make([]uint32, sboxWords)
+			m.sboxes = append(m.sboxes, sbox)
+			m.smix1(bp, 1, sboxBlocks, false, sbox, nil)
+			ctxs[i] = &pwxform{s: sbox, s1: sboxWords / 3, s0: sboxWords / 3 * 2}
+
+			if i == 0 {
+				var key [64]byte
+				for k, w := range bp[s-16:] {
+					binary.LittleEndian.PutUint32(key[k*4:], w)
+				}
+				mac := hmac.New(sha256.New, key[:])
+				mac.Write(passwd)
+				mac.Sum(passwd[:0])
+				zero.Bytes(key[:])
+			}
+		}
+
+		m.smix1(bp, m.r, np, m.rw, vp, ctxs[i])
+		m.smix2(bp, floorPow2(np), loopRW, m.rw, vp, ctxs[i])
+	}
+
+	if loopAll > loopRW {
+		for i := range p {
+			m.smix2(b[i*s:(i+1)*s], n, loopAll-loopRW, false, m.v, ctxs[i])
+		}
+	}
+}
+
+func (m *mixer) smix1(b []uint32, r, n int, rw bool, v []uint32, ctx *pwxform) {
+	s := 32 * r
+	x := m.x[:s]
+
+	shuffle(x, b[:s])
+	for i := range n {
+		copy(v[i*s:(i+1)*s], x)
+		if rw && i > 1 {
+			j := wrap(integerify(x), i)
+			xorWords(x, v[j*s:(j+1)*s])
+		}
+		m.blockmix(x, ctx)
+	}
+	unshuffle(b[:s], x)
+}
+
+func (m *mixer) smix2(b []uint32, n, loops int, rw bool, v []uint32, ctx *pwxform) {
+	s := 32 * m.r
+	x := m.x[:s]
+
+	shuffle(x, b)
+	for range loops {
+		j := int(integerify(x) & uint64(n-1)) // #nosec G115 -- masked below n
+		block := v[j*s : (j+1)*s]
+		xorWords(x, block)
+		if rw {
+			copy(block, x)
+		}
+		m.blockmix(x, ctx)
+	}
+	unshuffle(b, x)
+}
+
+func (m *mixer) blockmix(x []uint32, ctx *pwxform) {
+	if ctx != nil {
+		ctx.blockmix(x)
+		return
+	}
+
+	blocks := len(x) / 16
+	y := m.y[:len(x)]
+
+	var t [16]uint32
+	copy(t[:], x[len(x)-16:])
+	for i := range blocks {
+		xorWords(t[:], x[i*16:(i+1)*16])
+		salsa20(t[:], 8)
+		copy(y[i*16:], t[:])
+	}
+
+	half := blocks / 2
+	for i := range half {
+		copy(x[i*16:(i+1)*16], y[2*i*16:])
+		copy(x[(i+half)*16:(i+half+1)*16], y[(2*i+1)*16:])
+	}
+	wipeWords(t[:])
+}
+
+// pwxform is the per-lane S-box state; s0, s1, and s2 are word offsets into s
+// that rotate after every call.
+type pwxform struct {
+	s          []uint32
+	s0, s1, s2 int
+	w          int
+}
+
+func (c *pwxform) blockmix(x []uint32) {
+	var t [16]uint32
+	copy(t[:], x[len(x)-16:])
+
+	blocks := len(x) / 16
+	for i := range blocks {
+		if blocks > 1 {
+			xorWords(t[:], x[i*16:(i+1)*16])
+		}
+		c.transform(&t)
+		copy(x[i*16:], t[:])
+	}
+	wipeWords(t[:])
+
+	salsa20(x[len(x)-16:], 2)
+}
+
+func (c *pwxform) transform(b *[16]uint32) {
+	for i := range pwxRounds {
+		for j := range pwxGather {
+			p0 := c.s0 + int(b[j*4]&sboxMask)/4
+			p1 := c.s1 + int(b[j*4+1]&sboxMask)/4
+
+			for k := range pwxSimple {
+				lo, hi := b[j*4+k*2], b[j*4+k*2+1]
+				s0 := uint64(c.s[p0+k*2+1])<<32 | uint64(c.s[p0+k*2])
+				s1 := uint64(c.s[p1+k*2+1])<<32 | uint64(c.s[p1+k*2])
+
+				v := (uint64(hi)*uint64(lo) + s0) ^ s1
+				b[j*4+k*2], b[j*4+k*2+1] = uint32(v), uint32(v>>32)
+
+				if i != 0 && i != pwxRounds-1 {
+					c.s[c.s2+c.w*2], c.s[c.s2+c.w*2+1] = uint32(v), uint32(v>>32)
+					c.w++
+				}
+			}
+		}
+	}
+
+	c.s0, c.s1, c.s2 = c.s2, c.s0, c.s1
+	c.w &= sboxWrap
+}
+
+// shuffle copies b into x in the reference code's SIMD word order.
+func shuffle(x, b []uint32) {
+	for k := 0; k < len(b); k += 16 {
+		for i := range 16 {
+			x[k+i] = b[k+i*5%16]
+		}
+	}
+}
+
+func unshuffle(b, x []uint32) {
+	for k := 0; k < len(x); k += 16 {
+		for i := range 16 {
+			b[k+i*5%16] = x[k+i]
+		}
+	}
+}
+
+// integerify reads the first 64 bits of the last 64-byte block of a shuffled block.
+func integerify(x []uint32) uint64 {
+	last := x[len(x)-16:]
+	return uint64(last[13])<<32 | uint64(last[0])
+}
+
+// wrap maps x into the window of blocks written since the last power of two below i.
+func wrap(x uint64, i int) int {
+	n := floorPow2(i)
+	return int(x&uint64(n-1)) + (i - n) // #nosec G115 -- masked below n
+}
+
+func floorPow2(x int) int {
+	return 1 << (bits.Len(uint(x)) - 1)
+}
+
+func xorWords(dst, src []uint32) {
+	for i := range dst {
+		dst[i] ^= src[i]
+	}
+}
+
+func wipeWords(words []uint32) {
+	clear(words)
+}
+
+// salsa20 applies the Salsa20 core with the given number of rounds to a shuffled block.
+func salsa20(b []uint32, rounds int) {
+	var x [16]uint32
+	for i := range 16 {
+		x[i*5%16] = b[i]
+	}
+
+	for i := 0; i < rounds; i += 2 {
+		x[4] ^= bits.RotateLeft32(x[0]+x[12], 7)
+		x[8] ^= bits.RotateLeft32(x[4]+x[0], 9)
+		x[12] ^= bits.RotateLeft32(x[8]+x[4], 13)
+		x[0] ^= bits.RotateLeft32(x[12]+x[8], 18)
+		x[9] ^= bits.RotateLeft32(x[5]+x[1], 7)
+		x[13] ^= bits.RotateLeft32(x[9]+x[5], 9)
+		x[1] ^= bits.RotateLeft32(x[13]+x[9], 13)
+		x[5] ^= bits.RotateLeft32(x[1]+x[13], 18)
+		x[14] ^= bits.RotateLeft32(x[10]+x[6], 7)
+		x[2] ^= bits.RotateLeft32(x[14]+x[10], 9)
+		x[6] ^= bits.RotateLeft32(x[2]+x[14], 13)
+		x[10] ^= bits.RotateLeft32(x[6]+x[2], 18)
+		x[3] ^= bits.RotateLeft32(x[15]+x[11], 7)
+		x[7] ^= bits.RotateLeft32(x[3]+x[15], 9)
+		x[11] ^= bits.RotateLeft32(x[7]+x[3], 13)
+		x[15] ^= bits.RotateLeft32(x[11]+x[7], 18)
+
+		x[1] ^= bits.RotateLeft32(x[0]+x[3], 7)
+		x[2] ^= bits.RotateLeft32(x[1]+x[0], 9)
+		x[3] ^= bits.RotateLeft32(x[2]+x[1], 13)
+		x[0] ^= bits.RotateLeft32(x[3]+x[2], 18)
+		x[6] ^= bits.RotateLeft32(x[5]+x[4], 7)
+		x[7] ^= bits.RotateLeft32(x[6]+x[5], 9)
+		x[4] ^= bits.RotateLeft32(x[7]+x[6], 13)
+		x[5] ^= bits.RotateLeft32(x[4]+x[7], 18)
+		x[11] ^= bits.RotateLeft32(x[10]+x[9], 7)
+		x[8] ^= bits.RotateLeft32(x[11]+x[10], 9)
+		x[9] ^= bits.RotateLeft32(x[8]+x[11], 13)
+		x[10] ^= bits.RotateLeft32(x[9]+x[8], 18)
+		x[12] ^= bits.RotateLeft32(x[15]+x[14], 7)
+		x[13] ^= bits.RotateLeft32(x[12]+x[15], 9)
+		x[14] ^= bits.RotateLeft32(x[13]+x[12], 13)
+		x[15] ^= bits.RotateLeft32(x[14]+x[13], 18)
+	}
+
+	for i := range 16 {
+		b[i] += x[i*5%16]
+	}
+}
@@ -0,0 +1,290 @@
+package pwdhash
+
+import (
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/rand"
+	"errors"
+	"fmt"
+	"strconv"
+
+	"golang.org/x/crypto/chacha20poly1305"
+
+	"github.com/allisson/go-pwdhash/internal/cast"
+	"github.com/allisson/go-pwdhash/internal/encoding"
+	"github.com/allisson/go-pwdhash/internal/zero"
+)
+
+// Cipher selects the AEAD construction used by EncryptingHasher.
+type Cipher string
+
+const (
+	// CipherAES256GCM seals hashes with AES-256-GCM.
+	CipherAES256GCM Cipher = "aes256gcm"
+	// CipherXChaCha20Poly1305 seals hashes with XChaCha20-Poly1305.
+	CipherXChaCha20Poly1305 Cipher = "xchacha20poly1305"
+)
+
+// encryptedVersion is the layout version written into sealed PHC strings.
+const encryptedVersion = 1
+
+// EncryptingHasher seals the PHC output of another Hasher with a versioned AEAD key.
+//
+// Sealed hashes are PHC strings of the form
+// $aead$v=1$a=<cipher>,k=<key version>$<nonce>$<ciphertext>, where the header is
+// authenticated as associated data so neither the cipher nor the key version can
+// be swapped without detection.
+type EncryptingHasher struct {
+	Inner   Hasher
+	Keyring Keyring
+	Cipher  Cipher
+}
+
+// NewEncryptingHasher wraps inner so its output is sealed with AES-256-GCM.
+func NewEncryptingHasher(inner Hasher, keyring Keyring) *EncryptingHasher {
+	return &EncryptingHasher{
+		Inner:   inner,
+		Keyring: keyring,
+		Cipher:  CipherAES256GCM,
+	}
+}
+
+// ID reports the PHC algorithm identifier.
+func (e *EncryptingHasher) ID() string {
+	return "aead"
+}
+
+// Hash delegates to the inner hasher and seals the result under the current key.
+func (e *EncryptingHasher) Hash(password []byte) (string, error) {
+	encoded, err := e.Inner.Hash(password)
+	if err != nil {
+		return "", err
+	}
+
+	plaintext := []byte(encoded)
+	defer zero.Bytes(plaintext)
+
+	return e.seal(plaintext)
+}
+
+// HashWithAD delegates to an inner AssociatedDataHasher and seals the result.
+func (e *EncryptingHasher) HashWithAD(password, ad []byte) (string, error) {
+	inner, ok := e.Inner.(AssociatedDataHasher)
+	if !ok {
+		return "", ErrAssociatedDataUnsupported
+	}
+
+	encoded, err := inner.HashWithAD(password, ad)
+	if err != nil {
+		return "", err
+	}
+
+	plaintext := []byte(encoded)
+	defer zero.Bytes(plaintext)
+
+	return e.seal(plaintext)
+}
+
+// Verify decrypts the sealed hash and delegates verification to the inner hasher.
+func (e *EncryptingHasher) Verify(password []byte, encoded string) (bool, error) {
+	defer zero.Bytes(password)
+
+	parsed, err := encoding.Parse(encoded)
+	if err != nil {
+		return false, err
+	}
+
+	if parsed.Algorithm != e.ID() {
+		return false, nil
+	}
+
+	plaintext, err := e.open(parsed)
+	if err != nil {
+		return false, err
+	}
+
+	defer zero.Bytes(plaintext)
+
+	return e.Inner.Verify(password, string(plaintext))
+}
+
+// VerifyWithAD decrypts the sealed hash and delegates to an inner AssociatedDataHasher.
+func (e *EncryptingHasher) VerifyWithAD(password, ad []byte, encoded string) (bool, error) {
+	defer zero.Bytes(password)
+
+	inner, ok := e.Inner.(AssociatedDataHasher)
+	if !ok {
+		return false, ErrAssociatedDataUnsupported
+	}
+
+	parsed, err := encoding.Parse(encoded)
+	if err != nil {
+		return false, err
+	}
+
+	if parsed.Algorithm != e.ID() {
+		return false, nil
+	}
+
+	plaintext, err := e.open(parsed)
+	if err != nil {
+		return false, err
+	}
+
+	defer zero.Bytes(plaintext)
+
+	return inner.VerifyWithAD(password, ad, string(plaintext))
+}
+
+// NeedsRehash reports true when the hash was sealed under an older key or
+// another cipher, and otherwise asks the inner hasher whether it is outdated.
+func (e *EncryptingHasher) NeedsRehash(encoded string) (bool, error) {
+	parsed, err := encoding.Parse(encoded)
+	if err != nil {
+		return false, err
+	}
+
+	if parsed.Algorithm != e.ID() {
+		return true, nil
+	}
+
+	plaintext, err := e.open(parsed)
+	if err != nil {
+		return false, err
+	}
+
+	defer zero.Bytes(plaintext)
+
+	version, _, err := e.Keyring.Current()
+	if err != nil {
+		return false, err
+	}
+
+	if parsed.Params["k"] != strconv.FormatUint(uint64(version), 10) || parsed.Params["a"] != string(e.Cipher) {
+		return true, nil
+	}
+
+	return e.Inner.NeedsRehash(string(plaintext))
+}
+
+// Reencrypt opens a sealed hash and seals it again under the current key and cipher.
+//
+// No password is required, so stored hashes can be rotated in bulk after a key
+// compromise. Plain hashes produced by the inner hasher are sealed as-is, which
+// lets existing rows be encrypted without waiting for users to log in.
+func (e *EncryptingHasher) Reencrypt(stored string) (string, error) {
+	parsed, err := encoding.Parse(stored)
+	if err != nil {
+		return "", err
+	}
+
+	switch parsed.Algorithm {
+	case e.ID():
+		plaintext, err := e.open(parsed)
+		if err != nil {
+			return "", err
+		}
+
+		defer zero.Bytes(plaintext)
+
+		return e.seal(plaintext)
+	case e.Inner.ID():
+		return e.seal([]byte(stored))
+	}
+
+	return "", fmt.Errorf("unknown hash algorithm: %s", parsed.Algorithm)
+}
+
+func (e *EncryptingHasher) seal(plaintext []byte) (string, error) {
+	version, key, err := e.Keyring.Current()
+	if err != nil {
+		return "", err
+	}
+
+	aead, err := e.Cipher.aead(key)
+	if err != nil {
+		return "", err
+	}
+
+	enc := encoding.EncodedHash{
+		Algorithm: e.ID(),
+		Version:   encryptedVersion,
+		Params: map[string]string{
+			"a": string(e.Cipher),
+			"k": strconv.FormatUint(uint64(version), 10),
+		},
+	}
+
+	nonce := make([]byte, aead.NonceSize())
+	if _, err := rand.Read(nonce); err != nil {
+		return "", err
+	}
+
+	enc.Salt = nonce
+	enc.Hash = aead.Seal(nil, nonce, plaintext, sealedHeader(enc))
+
+	return enc.String(), nil
+}
+
+func (e *EncryptingHasher) open(parsed *encoding.EncodedHash) ([]byte, error) {
+	if parsed.Version != encryptedVersion {
+		return nil, errors.New("unsupported aead version")
+	}
+
+	version, err := cast.ConvertStringToUint32(parsed.Params["k"])
+	if err != nil {
+		return nil, err
+	}
+
+	key, err := e.Keyring.Key(version)
+	if err != nil {
+		return nil, err
+	}
+
+	aead, err := Cipher(parsed.Params["a"]).aead(key)
+	if err != nil {
+		return nil, err
+	}
+
+	if len(parsed.Salt) != aead.NonceSize() {
+		return nil, ErrInvalidHash
+	}
+
+	plaintext, err := aead.Open(nil, parsed.Salt, parsed.Hash, sealedHeader(*parsed))
+	if err != nil {
+		return nil, ErrDecryptionFailed
+	}
+
+	return plaintext, nil
+}
+
+// sealedHeader renders the algorithm, version, and parameters used as associated data.
+func sealedHeader(enc encoding.EncodedHash) []byte {
+	header := encoding.EncodedHash{
+		Algorithm: enc.Algorithm,
+		Version:   enc.Version,
+		Params:    enc.Params,
+	}
+
+	return []byte(header.String())
+}
+
+func (c Cipher) aead(key []byte) (cipher.AEAD, error) {
+	switch c {
+	case CipherAES256GCM:
+		if len(key) != 32 {
+			return nil, errors.New("aes256gcm requires a 32-byte key")
+		}
+
+		block, err := aes.NewCipher(key)
+		if err != nil {
+			return nil, err
+		}
+
+		return cipher.NewGCM(block)
+	case CipherXChaCha20Poly1305:
+		return chacha20poly1305.NewX(key)
+	}
+
+	return nil, fmt.Errorf("unknown cipher: %s", c)
+}
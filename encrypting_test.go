@@ -0,0 +1,209 @@
+package pwdhash
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/allisson/go-pwdhash/argon2"
+)
+
+func newTestKeyring(t *testing.T, current uint32) *StaticKeyring {
+	t.Helper()
+
+	keyring, err := NewStaticKeyring(current, map[uint32][]byte{
+		1: []byte("0123456789abcdef0123456789abcdef"),
+		2: []byte("fedcba9876543210fedcba9876543210"),
+	})
+	require.NoError(t, err)
+
+	return keyring
+}
+
+func newTestArgon2() *argon2.Argon2idHasher {
+	return &argon2.Argon2idHasher{
+		Memory:      argon2.MinMemory,
+		Iterations:  argon2.MinIterations,
+		Parallelism: 2,
+		SaltLength:  16,
+		KeyLength:   32,
+	}
+}
+
+func TestEncryptingHasher_HashAndVerify(t *testing.T) {
+	tests := []struct {
+		name   string
+		cipher Cipher
+	}{
+		{name: "aes256gcm", cipher: CipherAES256GCM},
+		{name: "xchacha20poly1305", cipher: CipherXChaCha20Poly1305},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			hasher := NewEncryptingHasher(newTestArgon2(), newTestKeyring(t, 1))
+			hasher.Cipher = tt.cipher
+
+			encoded, err := hasher.Hash([]byte("password"))
+			require.NoError(t, err)
+			require.True(t, strings.HasPrefix(encoded, "$aead$v=1$a="+string(tt.cipher)+",k=1$"))
+			require.NotContains(t, encoded, "argon2id")
+
+			ok, err := hasher.Verify([]byte("password"), encoded)
+			require.NoError(t, err)
+			require.True(t, ok)
+
+			ok, err = hasher.Verify([]byte("wrong"), encoded)
+			require.NoError(t, err)
+			require.False(t, ok)
+
+			needs, err := hasher.NeedsRehash(encoded)
+			require.NoError(t, err)
+			require.False(t, needs)
+		})
+	}
+}
+
+func TestEncryptingHasher_Reencrypt(t *testing.T) {
+	old := NewEncryptingHasher(newTestArgon2(), newTestKeyring(t, 1))
+
+	encoded, err := old.Hash([]byte("password"))
+	require.NoError(t, err)
+
+	rotated := NewEncryptingHasher(newTestArgon2(), newTestKeyring(t, 2))
+	rotated.Cipher = CipherXChaCha20Poly1305
+
+	reencrypted, err := rotated.Reencrypt(encoded)
+	require.NoError(t, err)
+	require.Contains(t, reencrypted, "a=xchacha20poly1305,k=2$")
+
+	ok, err := rotated.Verify([]byte("password"), reencrypted)
+	require.NoError(t, err)
+	require.True(t, ok)
+}
+
+func TestEncryptingHasher_ReencryptPlainHash(t *testing.T) {
+	inner := newTestArgon2()
+
+	plain, err := inner.Hash([]byte("password"))
+	require.NoError(t, err)
+
+	hasher := NewEncryptingHasher(newTestArgon2(), newTestKeyring(t, 1))
+
+	sealed, err := hasher.Reencrypt(plain)
+	require.NoError(t, err)
+
+	ok, err := hasher.Verify([]byte("password"), sealed)
+	require.NoError(t, err)
+	require.True(t, ok)
+
+	_, err = hasher.Reencrypt("$bcrypt$v=1$c=10$YWJj$ZGVm")
+	require.Error(t, err)
+}
+
+func TestEncryptingHasher_VerifyErrors(t *testing.T) {
+	hasher := NewEncryptingHasher(newTestArgon2(), newTestKeyring(t, 1))
+
+	encoded, err := hasher.Hash([]byte("password"))
+	require.NoError(t, err)
+
+	tests := []struct {
+		name    string
+		mutator func(string) string
+		wantErr error
+	}{
+		{
+			name: "unknownKey",
+			mutator: func(s string) string {
+				return strings.Replace(s, "k=1", "k=9", 1)
+			},
+			wantErr: ErrKeyNotFound,
+		},
+		{
+			name: "swappedKey",
+			mutator: func(s string) string {
+				return strings.Replace(s, "k=1", "k=2", 1)
+			},
+			wantErr: ErrDecryptionFailed,
+		},
+		{
+			name: "tamperedCiphertext",
+			mutator: func(s string) string {
+				idx := strings.LastIndex(s, "$") + 1
+				flipped := "A"
+				if s[idx] == 'A' {
+					flipped = "B"
+				}
+				return s[:idx] + flipped + s[idx+1:]
+			},
+			wantErr: ErrDecryptionFailed,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ok, err := hasher.Verify([]byte("password"), tt.mutator(encoded))
+			require.ErrorIs(t, err, tt.wantErr)
+			require.False(t, ok)
+		})
+	}
+}
+
+func TestEncryptingHasher_NeedsRehashDelegates(t *testing.T) {
+	hasher := NewEncryptingHasher(newTestArgon2(), newTestKeyring(t, 1))
+
+	encoded, err := hasher.Hash([]byte("password"))
+	require.NoError(t, err)
+
+	stronger := newTestArgon2()
+	stronger.Iterations++
+	upgraded := NewEncryptingHasher(stronger, newTestKeyring(t, 1))
+
+	needs, err := upgraded.NeedsRehash(encoded)
+	require.NoError(t, err)
+	require.True(t, needs)
+
+	needs, err = upgraded.NeedsRehash("$argon2id$v=19$m=1,t=1,p=1$YWJj$ZGVm")
+	require.NoError(t, err)
+	require.True(t, needs)
+}
+
+func TestEncryptingHasher_NeedsRehashAfterRotation(t *testing.T) {
+	hasher := NewEncryptingHasher(newTestArgon2(), newTestKeyring(t, 1))
+
+	encoded, err := hasher.Hash([]byte("password"))
+	require.NoError(t, err)
+
+	needs, err := hasher.NeedsRehash(encoded)
+	require.NoError(t, err)
+	require.False(t, needs)
+
+	rotated := NewEncryptingHasher(newTestArgon2(), newTestKeyring(t, 2))
+	needs, err = rotated.NeedsRehash(encoded)
+	require.NoError(t, err)
+	require.True(t, needs)
+
+	switched := NewEncryptingHasher(newTestArgon2(), newTestKeyring(t, 1))
+	switched.Cipher = CipherXChaCha20Poly1305
+	needs, err = switched.NeedsRehash(encoded)
+	require.NoError(t, err)
+	require.True(t, needs)
+}
+
+func TestPasswordHasher_WithEncryptingHasher(t *testing.T) {
+	ph, err := New(WithHasher(NewEncryptingHasher(newTestArgon2(), newTestKeyring(t, 1))))
+	require.NoError(t, err)
+
+	encoded, err := ph.Hash([]byte("password"))
+	require.NoError(t, err)
+
+	ok, err := ph.Verify([]byte("password"), encoded)
+	require.NoError(t, err)
+	require.True(t, ok)
+}
+
+func TestNewStaticKeyringRequiresCurrentKey(t *testing.T) {
+	_, err := NewStaticKeyring(3, map[uint32][]byte{1: make([]byte, 32)})
+	require.ErrorIs(t, err, ErrKeyNotFound)
+}
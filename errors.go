@@ -5,4 +5,22 @@ import "errors"
 var (
 	// ErrInvalidHash indicates that an encoded hash cannot be parsed.
 	ErrInvalidHash = errors.New("invalid encoded hash")
+	// ErrUnknownAlgorithm indicates that no registered verifier handles an encoded hash.
+	ErrUnknownAlgorithm = errors.New("unknown hash algorithm")
+	// ErrKeyNotFound indicates that a keyring does not hold the requested key version.
+	ErrKeyNotFound = errors.New("encryption key not found")
+	// ErrDecryptionFailed indicates that a sealed hash could not be authenticated.
+	ErrDecryptionFailed = errors.New("encrypted hash decryption failed")
+	// ErrAssociatedDataUnsupported indicates that a hasher cannot bind associated data.
+	ErrAssociatedDataUnsupported = errors.New("hasher does not support associated data")
+	// ErrNotApproved indicates that a hasher is not FIPS 140-3 approved.
+	ErrNotApproved = errors.New("hasher is not FIPS 140-3 approved")
+	// ErrSecretDestroyed indicates that a Secret was used after Destroy.
+	ErrSecretDestroyed = errors.New("secret has been destroyed")
+	// ErrUserNotFound indicates that a Store holds no hash for the requested user.
+	ErrUserNotFound = errors.New("user not found")
+	// ErrThrottled indicates that a Limiter refused an attempt; see ThrottledError for details.
+	ErrThrottled = errors.New("too many failed attempts")
+	// ErrPasswordReused indicates that a password matches a previous one; see ReusedError for details.
+	ErrPasswordReused = errors.New("password was used recently")
 )
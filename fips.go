@@ -0,0 +1,73 @@
+package pwdhash
+
+import (
+	"fmt"
+
+	"github.com/allisson/go-pwdhash/argon2"
+	"github.com/allisson/go-pwdhash/pbkdf2"
+)
+
+// unapprovedIDs lists the verifier IDs of schemes built on primitives outside
+// FIPS 140-3, whatever concrete type registers them.
+var unapprovedIDs = map[string]bool{
+	"argon2id":      true,
+	"argon2i":       true,
+	"argon2d":       true,
+	"argon2id-wrap": true,
+	"bcrypt":        true,
+	"scrypt":        true,
+	"crypt":         true,
+	"phpass":        true,
+	"mysql":         true,
+	"postgres":      true,
+	"ldap":          true,
+}
+
+// applyFIPS restricts the configuration to FIPS 140-3 approved hashing. It
+// selects PBKDF2 when no hasher was chosen, rejects any other current hasher,
+// drops verifiers for unapproved schemes, and adds verify-only Argon2
+// checkers when migration is enabled.
+func (c *config) applyFIPS() error {
+	if c.current == nil {
+		c.current = pbkdf2.NewHasher()
+	}
+
+	if _, ok := c.current.(*pbkdf2.Hasher); !ok {
+		return fmt.Errorf("%w: %s", ErrNotApproved, c.current.ID())
+	}
+
+	verifiers := make([]Verifier, 0, len(c.verifiers)+3)
+	for _, v := range c.verifiers {
+		if approved(v) {
+			verifiers = append(verifiers, v)
+		}
+	}
+
+	if c.argon2 {
+		verifiers = append(verifiers,
+			argon2.NewLegacyVerifier(argon2.Argon2id),
+			argon2.NewLegacyVerifier(argon2.Argon2i),
+			argon2.NewLegacyVerifier(argon2.Argon2d),
+		)
+	}
+
+	c.verifiers = verifiers
+
+	return nil
+}
+
+// approved reports whether v verifies a FIPS 140-3 approved scheme. Sealed
+// hashes are judged by the hasher inside the envelope.
+func approved(v Verifier) bool {
+	if e, ok := v.(*EncryptingHasher); ok {
+		return approved(e.Inner)
+	}
+
+	return !unapprovedIDs[v.ID()]
+}
+
+// FIPS reports whether the PasswordHasher runs in FIPS 140-3 mode, either
+// because the Go FIPS module is enabled or because WithFIPS was given.
+func (p *PasswordHasher) FIPS() bool {
+	return p.fips
+}
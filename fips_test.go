@@ -0,0 +1,151 @@
+package pwdhash
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/allisson/go-pwdhash/argon2"
+	"github.com/allisson/go-pwdhash/bcrypt"
+	"github.com/allisson/go-pwdhash/pbkdf2"
+)
+
+func newTestPBKDF2() *pbkdf2.Hasher {
+	return &pbkdf2.Hasher{
+		Digest:     pbkdf2.DigestSHA256,
+		Iterations: pbkdf2.MinIterations,
+		SaltLength: pbkdf2.MinSaltLength,
+		KeyLength:  32,
+	}
+}
+
+func TestFIPS_DefaultsToPBKDF2(t *testing.T) {
+	ph, err := New(WithFIPS())
+	require.NoError(t, err)
+	require.True(t, ph.FIPS())
+
+	_, ok := ph.current.(*pbkdf2.Hasher)
+	require.True(t, ok)
+
+	_, ok = ph.registry["argon2id"]
+	require.False(t, ok)
+}
+
+func TestFIPS_RejectsArgon2Hasher(t *testing.T) {
+	_, err := New(WithFIPS(), WithHasher(newTestArgon2()))
+	require.ErrorIs(t, err, ErrNotApproved)
+
+	_, err = New(WithFIPS(), WithPolicy(PolicyInteractive))
+	require.ErrorIs(t, err, ErrNotApproved)
+}
+
+func TestFIPS_HashAndVerify(t *testing.T) {
+	ph, err := New(WithFIPS(), WithHasher(newTestPBKDF2()))
+	require.NoError(t, err)
+
+	encoded, err := ph.Hash([]byte("password"))
+	require.NoError(t, err)
+	require.Contains(t, encoded, "$pbkdf2-sha256$")
+
+	ok, err := ph.Verify([]byte("password"), encoded)
+	require.NoError(t, err)
+	require.True(t, ok)
+
+	needs, err := ph.NeedsRehash(encoded)
+	require.NoError(t, err)
+	require.False(t, needs)
+}
+
+func TestFIPS_Argon2Migration(t *testing.T) {
+	legacy, err := newTestArgon2().Hash([]byte("password"))
+	require.NoError(t, err)
+
+	strict, err := New(WithFIPS(), WithHasher(newTestPBKDF2()))
+	require.NoError(t, err)
+
+	_, err = strict.Verify([]byte("password"), legacy)
+	require.ErrorIs(t, err, ErrUnknownAlgorithm)
+
+	ph, err := New(WithFIPS(), WithHasher(newTestPBKDF2()), WithArgon2Migration())
+	require.NoError(t, err)
+
+	ok, err := ph.Verify([]byte("password"), legacy)
+	require.NoError(t, err)
+	require.True(t, ok)
+
+	needs, err := ph.NeedsRehash(legacy)
+	require.NoError(t, err)
+	require.True(t, needs)
+
+	_, err = ph.Verify([]byte("password"), "$argon2i$v=19$m=65536,t=2,p=1$c29tZXNhbHQ$wWKIMhR9lyDFvRz9YTZweHKfbftvj+qf+YFY4NeBbtA")
+	require.NoError(t, err)
+}
+
+func TestFIPS_DropsUnapprovedVerifiers(t *testing.T) {
+	legacy, err := argon2.Default().Hash([]byte("password"))
+	require.NoError(t, err)
+
+	ph, err := New(
+		WithFIPS(),
+		WithHasher(newTestPBKDF2()),
+		WithVerifier(argon2.Default()),
+		WithVerifier(bcrypt.New()),
+		WithVerifier(NewEncryptingHasher(argon2.Default(), newTestKeyring(t, 1))),
+	)
+	require.NoError(t, err)
+
+	for _, id := range []string{"argon2id", "argon2i", "bcrypt", "aead"} {
+		_, ok := ph.registry[id]
+		require.False(t, ok, id)
+	}
+	for _, r := range ph.recognizers {
+		require.NotEqual(t, "bcrypt", r.(Verifier).ID())
+	}
+
+	_, err = ph.Verify([]byte("password"), legacy)
+	require.ErrorIs(t, err, ErrUnknownAlgorithm)
+
+	// Migration verifies through the approved-mode checker, not argon2.Default().
+	ph, err = New(WithFIPS(), WithHasher(newTestPBKDF2()), WithVerifier(argon2.Default()), WithArgon2Migration())
+	require.NoError(t, err)
+	_, ok := ph.registry["argon2id"].(*argon2.LegacyVerifier)
+	require.True(t, ok)
+}
+
+func TestFIPS_Argon2MigrationWithAD(t *testing.T) {
+	bound, err := newTestArgon2().HashWithAD([]byte("password"), []byte("user-42"))
+	require.NoError(t, err)
+
+	ph, err := New(WithFIPS(), WithHasher(newTestPBKDF2()), WithArgon2Migration())
+	require.NoError(t, err)
+
+	ok, err := ph.VerifyWithAD([]byte("password"), []byte("user-42"), bound)
+	require.NoError(t, err)
+	require.True(t, ok)
+
+	ok, err = ph.VerifyWithAD([]byte("password"), []byte("user-43"), bound)
+	require.NoError(t, err)
+	require.False(t, ok)
+
+	_, err = ph.Verify([]byte("password"), bound)
+	require.Error(t, err)
+
+	needs, err := ph.NeedsRehash(bound)
+	require.NoError(t, err)
+	require.True(t, needs)
+}
+
+func TestFIPS_Argon2MigrationNormalized(t *testing.T) {
+	argon := newTestArgon2()
+	argon.Normalization = argon2.NormalizationNFKC
+
+	legacy, err := argon.Hash([]byte("cafe\u0301"))
+	require.NoError(t, err)
+
+	ph, err := New(WithFIPS(), WithHasher(newTestPBKDF2()), WithArgon2Migration())
+	require.NoError(t, err)
+
+	ok, err := ph.Verify([]byte("cafe\u0301"), legacy)
+	require.NoError(t, err)
+	require.True(t, ok)
+}
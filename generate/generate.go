@@ -0,0 +1,306 @@
+// Package generate creates random passwords and diceware passphrases for
+// temporary admin passwords and service credentials.
+//
+// Every character and word is drawn with crypto/rand without modulo bias,
+// and each Result reports the entropy of the process that produced it.
+// Passing a pwdhash.Validator, such as policy.New(), guarantees the output
+// would be accepted by a PasswordHasher configured with the same validator.
+package generate
+
+import (
+	"bytes"
+	"crypto/rand"
+	"errors"
+	"fmt"
+	"io"
+	"math"
+	"math/big"
+	"strings"
+
+	"github.com/allisson/go-pwdhash"
+	"github.com/allisson/go-pwdhash/internal/zero"
+)
+
+// ErrUnsatisfiable indicates that no generated candidate passed the
+// validators within MaxAttempts.
+var ErrUnsatisfiable = errors.New("no generated password passed the validators")
+
+// MaxAttempts bounds how many candidates are drawn before giving up on the
+// validators.
+const MaxAttempts = 100
+
+// Class selects a set of characters for Password.
+type Class uint8
+
+const (
+	// Lower is a-z.
+	Lower Class = 1 << iota
+	// Upper is A-Z.
+	Upper
+	// Digits is 0-9.
+	Digits
+	// Symbols is ASCII punctuation other than quotes, backslash, backtick,
+	// and $, so results paste safely into most configuration formats.
+	Symbols
+)
+
+// classChars lists the characters of each Class.
+var classChars = []struct {
+	class Class
+	chars string
+}{
+	{Lower, "abcdefghijklmnopqrstuvwxyz"},
+	{Upper, "ABCDEFGHIJKLMNOPQRSTUVWXYZ"},
+	{Digits, "0123456789"},
+	{Symbols, "!#%&()*+,-./:;<=>?@[]^_{}~"},
+}
+
+// Ambiguous lists characters that are easily confused when read aloud or
+// copied from a screen. Password leaves them out unless WithAmbiguous is set.
+const Ambiguous = "Il1|O0o;:,."
+
+// Result is a generated password or passphrase.
+type Result struct {
+	// Password holds the generated bytes. PasswordHasher.Hash zeroizes its
+	// input, so display or store it before hashing, or wrap it with
+	// pwdhash.NewSecret.
+	Password []byte
+	// Entropy is the entropy in bits of the generator for the chosen
+	// settings. Rejections by validators are not subtracted; they remove a
+	// negligible share of the outputs for any reasonable policy.
+	Entropy float64
+}
+
+type config struct {
+	length     int
+	classes    Class
+	ambiguous  bool
+	words      int
+	separator  string
+	validators []pwdhash.Validator
+	rand       io.Reader
+}
+
+// Option configures Password and Passphrase. Options that concern only one
+// of them are ignored by the other.
+type Option func(*config)
+
+// WithLength sets the number of characters of a Password (default 20).
+func WithLength(n int) Option {
+	return func(c *config) {
+		c.length = n
+	}
+}
+
+// WithClasses selects the character classes of a Password (default all).
+// Every selected class appears at least once.
+func WithClasses(classes Class) Option {
+	return func(c *config) {
+		c.classes = classes
+	}
+}
+
+// WithAmbiguous keeps the Ambiguous characters in a Password.
+func WithAmbiguous() Option {
+	return func(c *config) {
+		c.ambiguous = true
+	}
+}
+
+// WithWords sets the number of words of a Passphrase (default 6).
+func WithWords(n int) Option {
+	return func(c *config) {
+		c.words = n
+	}
+}
+
+// WithSeparator sets the string between Passphrase words (default "-").
+func WithSeparator(sep string) Option {
+	return func(c *config) {
+		c.separator = sep
+	}
+}
+
+// WithValidator draws new candidates until v accepts one. Repeat it to
+// require several validators.
+func WithValidator(v pwdhash.Validator) Option {
+	return func(c *config) {
+		c.validators = append(c.validators, v)
+	}
+}
+
+func newConfig(opts []Option) *config {
+	c := &config{
+		length:    20,
+		classes:   Lower | Upper | Digits | Symbols,
+		words:     6,
+		separator: "-",
+		rand:      rand.Reader,
+	}
+
+	for _, opt := range opts {
+		opt(c)
+	}
+
+	return c
+}
+
+// Password returns a random password of the configured length and classes.
+func Password(opts ...Option) (Result, error) {
+	c := newConfig(opts)
+
+	var sets []string
+	for _, cc := range classChars {
+		if c.classes&cc.class == 0 {
+			continue
+		}
+		chars := cc.chars
+		if !c.ambiguous {
+			chars = strings.Map(func(r rune) rune {
+				if strings.ContainsRune(Ambiguous, r) {
+					return -1
+				}
+				return r
+			}, chars)
+		}
+		sets = append(sets, chars)
+	}
+
+	if len(sets) == 0 {
+		return Result{}, errors.New("no character classes selected")
+	}
+	if c.length < len(sets) {
+		return Result{}, fmt.Errorf("length %d cannot hold %d character classes", c.length, len(sets))
+	}
+
+	alphabet := strings.Join(sets, "")
+	entropy := passwordEntropy(c.length, sets)
+
+	return c.generate(entropy, func() ([]byte, error) {
+		for {
+			password := make([]byte, c.length)
+			for i := range password {
+				n, err := c.uniform(len(alphabet))
+				if err != nil {
+					zero.Bytes(password)
+					return nil, err
+				}
+				password[i] = alphabet[n]
+			}
+
+			// Rejecting candidates that miss a class keeps the draw uniform
+			// over the passwords that contain them all.
+			if containsAll(password, sets) {
+				return password, nil
+			}
+			zero.Bytes(password)
+		}
+	})
+}
+
+// Passphrase returns random words from the EFF large wordlist joined by the
+// separator, worth about 12.9 bits each.
+func Passphrase(opts ...Option) (Result, error) {
+	c := newConfig(opts)
+	if c.words < 1 {
+		return Result{}, fmt.Errorf("word count must be positive, got %d", c.words)
+	}
+
+	words := wordlist()
+	entropy := float64(c.words) * math.Log2(float64(len(words)))
+
+	return c.generate(entropy, func() ([]byte, error) {
+		chosen := make([]string, c.words)
+		size := (c.words - 1) * len(c.separator)
+		for i := range chosen {
+			n, err := c.uniform(len(words))
+			if err != nil {
+				return nil, err
+			}
+			chosen[i] = words[n]
+			size += len(words[n])
+		}
+
+		// Sizing the buffer up front leaves no reallocated copies to wipe.
+		passphrase := make([]byte, 0, size)
+		for i, word := range chosen {
+			if i > 0 {
+				passphrase = append(passphrase, c.separator...)
+			}
+			passphrase = append(passphrase, word...)
+		}
+
+		return passphrase, nil
+	})
+}
+
+// generate draws candidates until every validator accepts one.
+func (c *config) generate(entropy float64, draw func() ([]byte, error)) (Result, error) {
+	for range MaxAttempts {
+		password, err := draw()
+		if err != nil {
+			return Result{}, err
+		}
+
+		if c.validate(password) == nil {
+			return Result{Password: password, Entropy: entropy}, nil
+		}
+		zero.Bytes(password)
+	}
+
+	return Result{}, ErrUnsatisfiable
+}
+
+func (c *config) validate(password []byte) error {
+	for _, v := range c.validators {
+		if err := v.Validate(password); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// uniform returns an unbiased random integer in [0, n).
+func (c *config) uniform(n int) (int, error) {
+	v, err := rand.Int(c.rand, big.NewInt(int64(n)))
+	if err != nil {
+		return 0, err
+	}
+
+	return int(v.Int64()), nil
+}
+
+func containsAll(password []byte, sets []string) bool {
+	for _, set := range sets {
+		if !bytes.ContainsAny(password, set) {
+			return false
+		}
+	}
+
+	return true
+}
+
+// passwordEntropy returns log2 of the number of length-character strings
+// over the union of sets that contain every set, by inclusion-exclusion
+// computed relative to the unrestricted count to avoid overflow.
+func passwordEntropy(length int, sets []string) float64 {
+	total := 0
+	for _, s := range sets {
+		total += len(s)
+	}
+
+	sum := 0.0
+	for mask := 0; mask < 1<<len(sets); mask++ {
+		missing, sign := 0, 1.0
+		for i, s := range sets {
+			if mask&(1<<i) != 0 {
+				missing += len(s)
+				sign = -sign
+			}
+		}
+		sum += sign * math.Pow(float64(total-missing)/float64(total), float64(length))
+	}
+
+	return float64(length)*math.Log2(float64(total)) + math.Log2(sum)
+}
@@ -0,0 +1,175 @@
+package generate
+
+import (
+	"bytes"
+	"errors"
+	"io"
+	"math"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/allisson/go-pwdhash"
+	"github.com/allisson/go-pwdhash/policy"
+)
+
+func withRand(r io.Reader) Option {
+	return func(c *config) {
+		c.rand = r
+	}
+}
+
+type validatorFunc func([]byte) error
+
+func (f validatorFunc) Validate(password []byte) error { return f(password) }
+
+func TestWordlist(t *testing.T) {
+	words := wordlist()
+
+	require.Len(t, words, 7776)
+	require.Equal(t, "abacus", words[0])
+	require.Equal(t, "zoom", words[len(words)-1])
+
+	seen := make(map[string]bool, len(words))
+	for _, w := range words {
+		require.False(t, seen[w], w)
+		seen[w] = true
+	}
+}
+
+func TestPassword_Defaults(t *testing.T) {
+	for range 50 {
+		r, err := Password()
+		require.NoError(t, err)
+		require.Len(t, r.Password, 20)
+		require.False(t, bytes.ContainsAny(r.Password, Ambiguous), string(r.Password))
+
+		for _, cc := range classChars {
+			require.True(t, bytes.ContainsAny(r.Password, cc.chars), string(r.Password))
+		}
+	}
+
+	r, err := Password()
+	require.NoError(t, err)
+	// 78 characters remain once the ambiguous ones are removed; requiring
+	// every class costs a fraction of a bit.
+	require.InDelta(t, 20*math.Log2(78), r.Entropy, 1)
+	require.Less(t, r.Entropy, 20*math.Log2(78))
+}
+
+func TestPassword_Options(t *testing.T) {
+	r, err := Password(WithClasses(Digits), WithLength(6))
+	require.NoError(t, err)
+	require.Len(t, r.Password, 6)
+	require.Empty(t, strings.Trim(string(r.Password), "23456789"))
+	require.InDelta(t, 18, r.Entropy, 1e-9)
+
+	r, err = Password(WithClasses(Lower|Upper), WithLength(200), WithAmbiguous())
+	require.NoError(t, err)
+	require.Len(t, r.Password, 200)
+	require.Empty(t, strings.Trim(string(r.Password), classChars[0].chars+classChars[1].chars))
+	require.InDelta(t, 200*math.Log2(52), r.Entropy, 1e-6)
+}
+
+func TestPassword_Errors(t *testing.T) {
+	_, err := Password(WithClasses(0))
+	require.Error(t, err)
+
+	_, err = Password(WithLength(3))
+	require.Error(t, err)
+
+	_, err = Password(withRand(bytes.NewReader(nil)))
+	require.ErrorIs(t, err, io.EOF)
+}
+
+func TestPassword_Uniform(t *testing.T) {
+	counts := make(map[byte]int)
+	for range 8000 {
+		r, err := Password(WithClasses(Digits), WithLength(1))
+		require.NoError(t, err)
+		counts[r.Password[0]]++
+	}
+
+	require.Len(t, counts, 8)
+	for digit, n := range counts {
+		require.InDelta(t, 1000, n, 150, string(digit))
+	}
+}
+
+func TestPassphrase(t *testing.T) {
+	words := make(map[string]bool)
+	for _, w := range wordlist() {
+		words[w] = true
+	}
+
+	r, err := Passphrase()
+	require.NoError(t, err)
+	require.InDelta(t, 6*math.Log2(7776), r.Entropy, 1e-9)
+	require.Equal(t, cap(r.Password), len(r.Password))
+
+	r, err = Passphrase(WithWords(4), WithSeparator(" "))
+	require.NoError(t, err)
+	chosen := strings.Split(string(r.Password), " ")
+	require.Len(t, chosen, 4)
+	for _, w := range chosen {
+		require.True(t, words[w], w)
+	}
+
+	_, err = Passphrase(WithWords(0))
+	require.Error(t, err)
+}
+
+func TestValidators(t *testing.T) {
+	r, err := Password(WithValidator(policy.New()))
+	require.NoError(t, err)
+	require.NoError(t, policy.New().Validate(r.Password))
+
+	r, err = Passphrase(WithValidator(policy.New()))
+	require.NoError(t, err)
+	require.NoError(t, policy.New().Validate(r.Password))
+
+	calls := 0
+	var rejected [][]byte
+	picky := validatorFunc(func(password []byte) error {
+		calls++
+		if calls < 3 {
+			rejected = append(rejected, password)
+			return errors.New("rejected")
+		}
+		return nil
+	})
+	r, err = Passphrase(WithValidator(picky))
+	require.NoError(t, err)
+	require.Equal(t, 3, calls)
+	for _, p := range rejected {
+		require.Equal(t, make([]byte, len(p)), p, "rejected candidates are wiped")
+	}
+
+	_, err = Password(WithValidator(validatorFunc(func([]byte) error { return errors.New("never") })))
+	require.ErrorIs(t, err, ErrUnsatisfiable)
+}
+
+func TestResult_HashesWithPasswordHasher(t *testing.T) {
+	r, err := Password(WithValidator(policy.New()))
+	require.NoError(t, err)
+
+	hasher, err := pwdhash.New(pwdhash.WithValidator(policy.New()))
+	require.NoError(t, err)
+
+	secret := pwdhash.NewSecret(r.Password)
+	defer secret.Destroy()
+
+	encoded, err := hasher.HashSecret(secret)
+	require.NoError(t, err)
+
+	ok, err := hasher.VerifySecret(secret, encoded)
+	require.NoError(t, err)
+	require.True(t, ok)
+}
+
+func TestPasswordEntropy(t *testing.T) {
+	require.InDelta(t, 1, passwordEntropy(2, []string{"a", "b"}), 1e-9)
+	require.InDelta(t, math.Log2(6), passwordEntropy(3, []string{"a", "b"}), 1e-9)
+	require.InDelta(t, 10*math.Log2(26), passwordEntropy(10, []string{classChars[0].chars}), 1e-9)
+}
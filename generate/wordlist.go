@@ -0,0 +1,26 @@
+package generate
+
+import (
+	_ "embed"
+	"strings"
+	"sync"
+)
+
+// effLargeWordlist is the EFF large wordlist for five-dice passphrases,
+// published under CC BY 3.0 at
+// https://www.eff.org/deeplinks/2016/07/new-wordlists-random-passphrases.
+//
+//go:embed eff_large_wordlist.txt
+var effLargeWordlist string
+
+// wordlist returns the words of the EFF large wordlist in dice order.
+var wordlist = sync.OnceValue(func() []string {
+	words := make([]string, 0, 7776)
+	for _, line := range strings.Split(strings.TrimSpace(effLargeWordlist), "\n") {
+		if _, word, ok := strings.Cut(line, "\t"); ok {
+			words = append(words, word)
+		}
+	}
+
+	return words
+})
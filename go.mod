@@ -6,6 +6,7 @@ require (
 	github.com/ccoveille/go-safecast/v2 v2.0.0
 	github.com/stretchr/testify v1.11.1
 	golang.org/x/crypto v0.47.0
+	golang.org/x/text v0.33.0
 )
 
 require (
@@ -1,10 +1,44 @@
 // Package pwdhash manages password hashing via configurable algorithms.
 package pwdhash
 
-// Hasher represents a password hashing algorithm implementation.
-type Hasher interface {
+// Verifier represents a verify-only password hashing scheme, kept so hashes
+// written by older systems can still be checked and upgraded.
+type Verifier interface {
 	ID() string
-	Hash(password []byte) (string, error)
 	Verify(password []byte, encoded string) (bool, error)
 	NeedsRehash(encoded string) (bool, error)
 }
+
+// Recognizer is implemented by verifiers whose hashes are not PHC strings, so
+// PasswordHasher can route those encodings to them.
+type Recognizer interface {
+	Verifier
+	Recognize(encoded string) bool
+}
+
+// Hasher represents a password hashing algorithm implementation.
+type Hasher interface {
+	Verifier
+	Hash(password []byte) (string, error)
+}
+
+// AssociatedDataVerifier is implemented by verifiers that can check hashes
+// bound to associated data, including verify-only schemes.
+type AssociatedDataVerifier interface {
+	Verifier
+	VerifyWithAD(password, ad []byte, encoded string) (bool, error)
+}
+
+// AssociatedDataHasher is implemented by hashers that can bind a hash to
+// caller-supplied associated data, such as the account identifier it belongs to.
+type AssociatedDataHasher interface {
+	Hasher
+	HashWithAD(password, ad []byte) (string, error)
+	VerifyWithAD(password, ad []byte, encoded string) (bool, error)
+}
+
+// Validator decides whether a new password is acceptable before it is hashed,
+// such as the NIST SP 800-63B checks in the policy package.
+type Validator interface {
+	Validate(password []byte) error
+}
@@ -0,0 +1,159 @@
+package pwdhash
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"slices"
+	"sync"
+)
+
+// DefaultHistoryConcurrency is the number of previous hashes History verifies
+// at once unless WithHistoryConcurrency says otherwise.
+const DefaultHistoryConcurrency = 4
+
+// ReusedError reports that a new password matches an entry of the history
+// passed to History.Check. It matches ErrPasswordReused under errors.Is.
+type ReusedError struct {
+	// Index is the position of the matching entry in the history slice.
+	Index int
+}
+
+// Error implements error.
+func (e *ReusedError) Error() string {
+	return fmt.Sprintf("%s (history entry %d)", ErrPasswordReused, e.Index)
+}
+
+// Is reports whether target is ErrPasswordReused.
+func (e *ReusedError) Is(target error) bool {
+	return target == ErrPasswordReused
+}
+
+// HistoryOption configures a History.
+type HistoryOption func(*History)
+
+// WithHistoryConcurrency limits how many previous hashes are verified at
+// once. Each Argon2id verification holds its full memory cost, so the limit
+// bounds the memory a single password change can use.
+func WithHistoryConcurrency(n int) HistoryOption {
+	return func(h *History) {
+		h.concurrency = max(n, 1)
+	}
+}
+
+// History rejects new passwords that match one of a user's previous hashes.
+//
+// Entries are verified through the PasswordHasher registry, so a history can
+// mix hashes from every registered algorithm and parameter set. Histories
+// are ordered oldest first; only the newest Size entries are checked.
+type History struct {
+	hasher      *PasswordHasher
+	size        int
+	concurrency int
+}
+
+// NewHistory returns a History that remembers size passwords.
+func NewHistory(hasher *PasswordHasher, size int, opts ...HistoryOption) (*History, error) {
+	if size < 1 {
+		return nil, fmt.Errorf("history size must be positive, got %d", size)
+	}
+
+	h := &History{hasher: hasher, size: size, concurrency: DefaultHistoryConcurrency}
+	for _, opt := range opts {
+		opt(h)
+	}
+
+	return h, nil
+}
+
+// Size returns the number of passwords the History remembers.
+func (h *History) Size() int {
+	return h.size
+}
+
+// Find returns the index in previous of an entry matching password, or -1.
+// Entries are verified concurrently, and the remaining work is abandoned as
+// soon as one matches. If nothing matches, the errors of entries that could
+// not be verified, such as ErrUnknownAlgorithm, are joined and returned with
+// -1 so callers can decide whether an unverifiable history is acceptable.
+func (h *History) Find(ctx context.Context, password *Secret, previous []string) (int, error) {
+	start := max(len(previous)-h.size, 0)
+
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+
+	var (
+		wg      sync.WaitGroup
+		mu      sync.Mutex
+		matched = -1
+		errs    []error
+	)
+
+	sem := make(chan struct{}, h.concurrency)
+	for i := len(previous) - 1; i >= start; i-- {
+		select {
+		case sem <- struct{}{}:
+		case <-ctx.Done():
+		}
+		if ctx.Err() != nil {
+			break
+		}
+
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			defer func() { <-sem }()
+
+			ok, err := h.hasher.VerifySecret(password, previous[i])
+
+			mu.Lock()
+			defer mu.Unlock()
+			switch {
+			case err != nil:
+				errs = append(errs, fmt.Errorf("history entry %d: %w", i, err))
+			case ok && i > matched:
+				matched = i
+				cancel()
+			}
+		}(i)
+	}
+	wg.Wait()
+
+	if matched >= 0 {
+		return matched, nil
+	}
+
+	if err := ctx.Err(); err != nil {
+		return -1, err
+	}
+
+	return -1, errors.Join(errs...)
+}
+
+// Check returns a *ReusedError if password matches one of the newest Size
+// entries of previous, and otherwise the error from Find.
+func (h *History) Check(ctx context.Context, password *Secret, previous []string) error {
+	i, err := h.Find(ctx, password, previous)
+	if i >= 0 {
+		return &ReusedError{Index: i}
+	}
+
+	return err
+}
+
+// Add returns previous with encoded appended, trimmed to the newest Size
+// entries. The previous slice is not modified.
+func (h *History) Add(previous []string, encoded string) []string {
+	return TrimHistory(append(slices.Clip(previous), encoded), h.size)
+}
+
+// TrimHistory returns the newest n entries of an oldest-first history. The
+// result does not share memory with previous.
+func TrimHistory(previous []string, n int) []string {
+	n = max(n, 0)
+	if len(previous) > n {
+		previous = previous[len(previous)-n:]
+	}
+
+	return append([]string(nil), previous...)
+}
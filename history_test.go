@@ -0,0 +1,147 @@
+package pwdhash
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/allisson/go-pwdhash/argon2"
+)
+
+const legacyArgon2i = "$argon2i$v=19$m=65536,t=2,p=1$c29tZXNhbHQ$wWKIMhR9lyDFvRz9YTZweHKfbftvj+qf+YFY4NeBbtA"
+
+// newTestHistory returns a History over mixed-age entries: a legacy argon2i
+// hash of "password", a PBKDF2 hash of "summer", and current Argon2id hashes
+// of "autumn" and "winter".
+func newTestHistory(t *testing.T, size int, opts ...HistoryOption) (*History, []string) {
+	t.Helper()
+
+	old, err := New(WithHasher(newTestPBKDF2()))
+	require.NoError(t, err)
+	summer, err := old.Hash([]byte("summer"))
+	require.NoError(t, err)
+
+	ph, err := New(WithHasher(newTestArgon2()), WithVerifier(newTestPBKDF2()))
+	require.NoError(t, err)
+	autumn, err := ph.Hash([]byte("autumn"))
+	require.NoError(t, err)
+	winter, err := ph.Hash([]byte("winter"))
+	require.NoError(t, err)
+
+	h, err := NewHistory(ph, size, opts...)
+	require.NoError(t, err)
+
+	return h, []string{legacyArgon2i, summer, autumn, winter}
+}
+
+func TestHistory_Find(t *testing.T) {
+	h, previous := newTestHistory(t, 4)
+
+	tests := []struct {
+		password string
+		want     int
+	}{
+		{"password", 0},
+		{"summer", 1},
+		{"autumn", 2},
+		{"winter", 3},
+		{"spring", -1},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.password, func(t *testing.T) {
+			secret := NewSecretString(tt.password)
+			i, err := h.Find(context.Background(), secret, previous)
+			require.NoError(t, err)
+			require.Equal(t, tt.want, i)
+
+			err = h.Check(context.Background(), secret, previous)
+			if tt.want < 0 {
+				require.NoError(t, err)
+				return
+			}
+			require.ErrorIs(t, err, ErrPasswordReused)
+			var reused *ReusedError
+			require.True(t, errors.As(err, &reused))
+			require.Equal(t, tt.want, reused.Index)
+		})
+	}
+}
+
+func TestHistory_ChecksNewestEntriesOnly(t *testing.T) {
+	h, previous := newTestHistory(t, 2, WithHistoryConcurrency(1))
+
+	i, err := h.Find(context.Background(), NewSecretString("password"), previous)
+	require.NoError(t, err)
+	require.Equal(t, -1, i)
+
+	i, err = h.Find(context.Background(), NewSecretString("autumn"), previous)
+	require.NoError(t, err)
+	require.Equal(t, 2, i)
+}
+
+func TestHistory_UnverifiableEntries(t *testing.T) {
+	h, previous := newTestHistory(t, 5)
+	previous = append([]string{"$md5$v=1$c29tZXNhbHQ$aGFzaA"}, previous...)
+
+	i, err := h.Find(context.Background(), NewSecretString("winter"), previous)
+	require.NoError(t, err)
+	require.Equal(t, 4, i)
+
+	i, err = h.Find(context.Background(), NewSecretString("spring"), previous)
+	require.Equal(t, -1, i)
+	require.ErrorIs(t, err, ErrUnknownAlgorithm)
+	require.ErrorContains(t, err, "history entry 0")
+}
+
+func TestHistory_Cancelled(t *testing.T) {
+	h, previous := newTestHistory(t, 4)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+
+	i, err := h.Find(ctx, NewSecretString("spring"), previous)
+	require.Equal(t, -1, i)
+	require.ErrorIs(t, err, context.Canceled)
+}
+
+func TestHistory_Add(t *testing.T) {
+	ph, err := New(WithHasher(newTestArgon2()))
+	require.NoError(t, err)
+	h, err := NewHistory(ph, 3)
+	require.NoError(t, err)
+	require.Equal(t, 3, h.Size())
+
+	previous := make([]string, 3, 8)
+	copy(previous, []string{"a", "b", "c"})
+
+	next := h.Add(previous, "d")
+	require.Equal(t, []string{"b", "c", "d"}, next)
+	require.Equal(t, []string{"a", "b", "c"}, previous)
+	require.Equal(t, "", previous[:4][3])
+
+	require.Equal(t, []string{"e"}, h.Add(nil, "e"))
+}
+
+func TestTrimHistory(t *testing.T) {
+	previous := []string{"a", "b", "c", "d"}
+
+	require.Equal(t, []string{"c", "d"}, TrimHistory(previous, 2))
+	require.Equal(t, previous, TrimHistory(previous, 10))
+	require.Empty(t, TrimHistory(previous, 0))
+	require.Empty(t, TrimHistory(previous, -1))
+
+	trimmed := TrimHistory(previous, 4)
+	trimmed[0] = "z"
+	require.Equal(t, "a", previous[0])
+}
+
+func TestNewHistory_InvalidSize(t *testing.T) {
+	ph, err := New(WithHasher(argon2.Default()))
+	require.NoError(t, err)
+
+	_, err = NewHistory(ph, 0)
+	require.Error(t, err)
+}
@@ -0,0 +1,82 @@
+package pwdhash
+
+import "github.com/allisson/go-pwdhash/internal/encoding"
+
+// Confidence grades how certain Identify is about the scheme it reports.
+type Confidence int
+
+const (
+	// ConfidenceNone means no registered scheme recognized the input.
+	ConfidenceNone Confidence = iota
+	// ConfidenceLow means the input is a well-formed PHC string for an
+	// algorithm that is not registered, so it cannot be verified.
+	ConfidenceLow
+	// ConfidenceMedium means several registered recognizers accepted the input;
+	// the first one registered is reported and is the one Verify would use.
+	ConfidenceMedium
+	// ConfidenceHigh means exactly one registered scheme accepted the input.
+	ConfidenceHigh
+)
+
+// String reports a lowercase label for the confidence level.
+func (c Confidence) String() string {
+	switch c {
+	case ConfidenceNone:
+		return "none"
+	case ConfidenceLow:
+		return "low"
+	case ConfidenceMedium:
+		return "medium"
+	case ConfidenceHigh:
+		return "high"
+	}
+
+	return "unknown"
+}
+
+// Identification describes the scheme Identify matched for a stored hash.
+type Identification struct {
+	// Scheme is the ID of the verifier that would handle the hash, or the PHC
+	// algorithm name when it is not registered.
+	Scheme string
+	// Confidence grades the match.
+	Confidence Confidence
+	// Candidates lists the IDs of every registered scheme that accepted the hash.
+	Candidates []string
+}
+
+// Identify names the scheme of a stored hash without verifying it.
+//
+// It follows the same routing as Verify: registered PHC algorithms first, then
+// each Recognizer in registration order. Register every adapter with
+// WithVerifier to triage tables that mix formats before a migration.
+func (p *PasswordHasher) Identify(encoded string) Identification {
+	parsed, err := encoding.Parse(encoded)
+	if err == nil {
+		if v, ok := p.registry[parsed.Algorithm]; ok {
+			return Identification{
+				Scheme:     v.ID(),
+				Confidence: ConfidenceHigh,
+				Candidates: []string{v.ID()},
+			}
+		}
+	}
+
+	var candidates []string
+	for _, r := range p.recognizers {
+		if r.Recognize(encoded) {
+			candidates = append(candidates, r.ID())
+		}
+	}
+
+	switch {
+	case len(candidates) == 1:
+		return Identification{Scheme: candidates[0], Confidence: ConfidenceHigh, Candidates: candidates}
+	case len(candidates) > 1:
+		return Identification{Scheme: candidates[0], Confidence: ConfidenceMedium, Candidates: candidates}
+	case err == nil:
+		return Identification{Scheme: parsed.Algorithm, Confidence: ConfidenceLow}
+	}
+
+	return Identification{Confidence: ConfidenceNone}
+}
@@ -0,0 +1,72 @@
+package pwdhash
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/allisson/go-pwdhash/aspnet"
+	"github.com/allisson/go-pwdhash/bcrypt"
+	"github.com/allisson/go-pwdhash/ldap"
+	"github.com/allisson/go-pwdhash/pbkdf2"
+)
+
+type fakeRecognizer struct {
+	fakeHasher
+	prefix string
+}
+
+func (f *fakeRecognizer) Recognize(encoded string) bool {
+	return len(encoded) >= len(f.prefix) && encoded[:len(f.prefix)] == f.prefix
+}
+
+func TestPasswordHasher_Identify(t *testing.T) {
+	ph, err := New(
+		WithHasher(newTestArgon2()),
+		WithVerifier(bcrypt.New()),
+		WithVerifier(pbkdf2.New()),
+		WithVerifier(aspnet.New()),
+		WithVerifier(ldap.New()),
+	)
+	require.NoError(t, err)
+
+	argonHash, err := ph.Hash([]byte("password"))
+	require.NoError(t, err)
+
+	tests := []struct {
+		name       string
+		encoded    string
+		scheme     string
+		confidence Confidence
+	}{
+		{name: "registeredPHC", encoded: argonHash, scheme: "argon2id", confidence: ConfidenceHigh},
+		{name: "legacyArgon2", encoded: "$argon2i$v=19$m=65536,t=2,p=1$c29tZXNhbHQ$wWKIMhR9lyDFvRz9YTZweHKfbftvj+qf+YFY4NeBbtA", scheme: "argon2i", confidence: ConfidenceHigh},
+		{name: "bcrypt", encoded: "$2b$04$p4wSFWnH/3cP53yyCWSnEuxvwVcro/yOPi/Vi6hez5ZvWA8q1d7WO", scheme: "bcrypt", confidence: ConfidenceHigh},
+		{name: "django", encoded: "pbkdf2_sha256$1000$seasalt$YIWkt6M1JFXrHg5s0jZjBSc7C2Cz6QvchSJ0h8Y+i7c=", scheme: "pbkdf2", confidence: ConfidenceHigh},
+		{name: "aspnet", encoded: "AAABAgMEBQYHCAkKCwwNDg8DCeL+Tgvf59D+SCjUHCNEFuLZv7Yc3Y9kOhHPv9/BGQ==", scheme: "aspnet", confidence: ConfidenceHigh},
+		{name: "ldap", encoded: "{SSHA}yrht1iYXEIkejLVu42JWkadd80RzYWx0c2FsdA==", scheme: "ldap", confidence: ConfidenceHigh},
+		{name: "unregisteredPHC", encoded: "$balloon$v=1$s=1024$YWJj$ZGVm", scheme: "balloon", confidence: ConfidenceLow},
+		{name: "unknown", encoded: "5f4dcc3b5aa765d61d8327deb882cf99", confidence: ConfidenceNone},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := ph.Identify(tt.encoded)
+			require.Equal(t, tt.scheme, got.Scheme)
+			require.Equal(t, tt.confidence, got.Confidence, got.Confidence.String())
+		})
+	}
+}
+
+func TestPasswordHasher_IdentifyAmbiguous(t *testing.T) {
+	ph, err := New(
+		WithVerifier(&fakeRecognizer{fakeHasher: fakeHasher{id: "first"}, prefix: "$x$"}),
+		WithVerifier(&fakeRecognizer{fakeHasher: fakeHasher{id: "second"}, prefix: "$x"}),
+	)
+	require.NoError(t, err)
+
+	got := ph.Identify("$x$abc")
+	require.Equal(t, "first", got.Scheme)
+	require.Equal(t, ConfidenceMedium, got.Confidence)
+	require.Equal(t, []string{"first", "second"}, got.Candidates)
+}
@@ -0,0 +1,91 @@
+package encoding
+
+import (
+	"encoding/base64"
+	"errors"
+	"strings"
+)
+
+// cryptAlphabet is the "./0-9A-Za-z" alphabet used by crypt(3)-style hashes.
+const cryptAlphabet = "./0123456789ABCDEFGHIJKLMNOPQRSTUVWXYZabcdefghijklmnopqrstuvwxyz"
+
+var errInvalidHash64 = errors.New("invalid hash64 encoding")
+
+// CryptBase64 is unpadded base64 in standard bit order over the crypt alphabet,
+// as used by Cisco type 8 and type 9 hashes.
+var CryptBase64 = base64.NewEncoding(cryptAlphabet).WithPadding(base64.NoPadding)
+
+// DecodeAdaptedBase64 decodes passlib's adapted base64, which substitutes '.'
+// for '+' and drops padding. Plain unpadded standard base64 is accepted too.
+func DecodeAdaptedBase64(s string) ([]byte, error) {
+	s = strings.TrimRight(strings.ReplaceAll(s, ".", "+"), "=")
+	return base64.RawStdEncoding.DecodeString(s)
+}
+
+// EncodeHash64 encodes src in the little-endian "hash64" layout used by
+// crypt(3) schemes: each 3-byte group is read least significant byte first and
+// emitted six bits at a time, and a trailing group of n bytes yields n+1 characters.
+func EncodeHash64(src []byte) string {
+	var sb strings.Builder
+	sb.Grow((len(src)*8 + 5) / 6)
+
+	for i := 0; i < len(src); i += 3 {
+		var value uint32
+		n := 0
+		for ; n < 3 && i+n < len(src); n++ {
+			value |= uint32(src[i+n]) << (8 * n)
+		}
+		for c := 0; c <= n; c++ {
+			sb.WriteByte(cryptAlphabet[value&0x3f])
+			value >>= 6
+		}
+	}
+
+	return sb.String()
+}
+
+// DecodeHash64 reverses EncodeHash64, rejecting dangling characters and
+// non-zero padding bits.
+func DecodeHash64(s string) ([]byte, error) {
+	dst := make([]byte, 0, len(s)*6/8)
+
+	for i := 0; i < len(s); i += 4 {
+		end := min(i+4, len(s))
+		if end-i < 2 {
+			return nil, errInvalidHash64
+		}
+
+		var value uint32
+		for c := i; c < end; c++ {
+			idx := strings.IndexByte(cryptAlphabet, s[c])
+			if idx < 0 {
+				return nil, errInvalidHash64
+			}
+			value |= uint32(idx) << (6 * (c - i)) // #nosec G115 -- idx is below 64
+		}
+
+		n := end - i - 1
+		for b := 0; b < n; b++ {
+			dst = append(dst, byte(value))
+			value >>= 8
+		}
+		if value != 0 {
+			return nil, errInvalidHash64
+		}
+	}
+
+	return dst, nil
+}
+
+// EncodeHash64Order encodes the bytes of src taken in the given index order,
+// matching the byte shuffles md5-crypt and sha-crypt apply before encoding.
+func EncodeHash64Order(src []byte, order []int) string {
+	permuted := make([]byte, len(order))
+	defer clear(permuted)
+
+	for i, idx := range order {
+		permuted[i] = src[idx]
+	}
+
+	return EncodeHash64(permuted)
+}
@@ -31,6 +31,19 @@ func TestParse_ValidPHCString(t *testing.T) {
 	require.Equal(t, original.Hash, parsed.Hash)
 }
 
+func TestParse_WithoutVersion(t *testing.T) {
+	parsed, err := Parse("$argon2i$m=65536,t=2,p=1$YWJj$ZGVm")
+	require.NoError(t, err)
+
+	require.Equal(t, "argon2i", parsed.Algorithm)
+	require.Equal(t, 0, parsed.Version)
+	require.Equal(t, "65536", parsed.Params["m"])
+	require.Equal(t, []byte("abc"), parsed.Salt)
+	require.Equal(t, []byte("def"), parsed.Hash)
+
+	require.Equal(t, "$argon2i$m=65536,p=1,t=2$YWJj$ZGVm", parsed.String())
+}
+
 func TestParse_ErrorScenarios(t *testing.T) {
 	const validParams = "m=65536,t=3,p=4"
 	const validSalt = "YWJj"
@@ -104,3 +117,68 @@ func TestEncodedHashStringIncludesMetadata(t *testing.T) {
 	require.Contains(t, encoded, "$v=19$")
 	require.Contains(t, encoded, "m=65536")
 }
+
+func TestEncodedHashStringInOrder(t *testing.T) {
+	enc := EncodedHash{
+		Algorithm: "argon2id",
+		Version:   19,
+		Params:    map[string]string{"m": "65536", "t": "3", "p": "4", "ad": "1"},
+		Salt:      []byte("salt"),
+		Hash:      []byte("hash"),
+	}
+
+	require.Equal(t, "$argon2id$v=19$ad=1,m=65536,p=4,t=3$c2FsdA$aGFzaA", enc.String())
+	require.Equal(t, "$argon2id$v=19$m=65536,t=3,p=4,ad=1$c2FsdA$aGFzaA", enc.StringInOrder([]string{"m", "t", "p", "keyid"}))
+}
+
+func TestDecodeAdaptedBase64(t *testing.T) {
+	tests := []struct {
+		name  string
+		input string
+	}{
+		{name: "adapted", input: "..8A"},
+		{name: "standard", input: "++8A"},
+		{name: "padded", input: "++8A="},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := DecodeAdaptedBase64(tt.input)
+			require.NoError(t, err)
+			require.Equal(t, []byte{0xfb, 0xef, 0x00}, got)
+		})
+	}
+}
+
+func TestCryptBase64UsesCryptAlphabet(t *testing.T) {
+	require.Equal(t, "./..", CryptBase64.EncodeToString([]byte{0x00, 0x10, 0x00}))
+}
+
+func TestHash64RoundTrip(t *testing.T) {
+	tests := []struct {
+		name    string
+		input   []byte
+		encoded string
+	}{
+		{name: "fullGroup", input: []byte{0x01, 0x02, 0x03}, encoded: "/6k."},
+		{name: "oneByte", input: []byte{0x3f}, encoded: "z."},
+		{name: "twoBytes", input: []byte{0xff, 0x00}, encoded: "z1."},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			require.Equal(t, tt.encoded, EncodeHash64(tt.input))
+
+			got, err := DecodeHash64(tt.encoded)
+			require.NoError(t, err)
+			require.Equal(t, tt.input, got)
+		})
+	}
+}
+
+func TestDecodeHash64Errors(t *testing.T) {
+	for _, input := range []string{"z", "zz", "!!"} {
+		_, err := DecodeHash64(input)
+		require.Error(t, err, input)
+	}
+}
@@ -15,22 +15,36 @@ func Parse(s string) (*EncodedHash, error) {
 	}
 
 	parts := strings.Split(s, "$")
+
+	// Some encoders omit the version segment ($algo$params$salt$hash); those
+	// hashes are reported with Version 0.
+	versioned := true
+	if len(parts) == 5 && strings.Contains(parts[2], "=") {
+		parts = []string{parts[0], parts[1], "", parts[2], parts[3], parts[4]}
+		versioned = false
+	}
+
 	if len(parts) < 6 {
 		return nil, errors.New("invalid PHC format")
 	}
 
-	algo := parts[1]
+	version := 0
+	if versioned {
+		// v=19
+		versionPart := parts[2]
+		if !strings.HasPrefix(versionPart, "v=") {
+			return nil, errors.New("missing version")
+		}
 
-	// v=19
-	versionPart := parts[2]
-	if !strings.HasPrefix(versionPart, "v=") {
-		return nil, errors.New("missing version")
-	}
-	version, err := strconv.Atoi(strings.TrimPrefix(versionPart, "v="))
-	if err != nil {
-		return nil, err
+		var err error
+		version, err = strconv.Atoi(strings.TrimPrefix(versionPart, "v="))
+		if err != nil {
+			return nil, err
+		}
 	}
 
+	algo := parts[1]
+
 	params := map[string]string{}
 	for _, kv := range strings.Split(parts[3], ",") {
 		p := strings.SplitN(kv, "=", 2)
@@ -4,6 +4,7 @@ package encoding
 import (
 	"encoding/base64"
 	"fmt"
+	"slices"
 	"sort"
 	"strings"
 )
@@ -18,22 +19,45 @@ type EncodedHash struct {
 }
 
 // String renders the hash in PHC string format with deterministic parameter ordering.
+// A zero Version omits the v= segment.
 func (e EncodedHash) String() string {
+	return e.StringInOrder(nil)
+}
+
+// StringInOrder renders the hash like String, but emits the parameters named
+// in order first and in that sequence, as libargon2-based parsers require.
+// Remaining parameters follow in alphabetical order.
+func (e EncodedHash) StringInOrder(order []string) string {
 	keys := make([]string, 0, len(e.Params))
+	for _, k := range order {
+		if _, ok := e.Params[k]; ok {
+			keys = append(keys, k)
+		}
+	}
+
+	var rest []string
 	for k := range e.Params {
-		keys = append(keys, k)
+		if !slices.Contains(order, k) {
+			rest = append(rest, k)
+		}
 	}
-	sort.Strings(keys)
+	sort.Strings(rest)
+	keys = append(keys, rest...)
 
 	params := make([]string, 0, len(keys))
 	for _, k := range keys {
 		params = append(params, fmt.Sprintf("%s=%s", k, e.Params[k]))
 	}
 
+	version := ""
+	if e.Version != 0 {
+		version = fmt.Sprintf("$v=%d", e.Version)
+	}
+
 	return fmt.Sprintf(
-		"$%s$v=%d$%s$%s$%s",
+		"$%s%s$%s$%s$%s",
 		e.Algorithm,
-		e.Version,
+		version,
 		strings.Join(params, ","),
 		base64.RawStdEncoding.EncodeToString(e.Salt),
 		base64.RawStdEncoding.EncodeToString(e.Hash),
@@ -0,0 +1,128 @@
+// Package shacrypt implements the SHA-crypt checksum shared by sha256-crypt,
+// sha512-crypt, and MySQL's caching_sha2_password.
+package shacrypt
+
+import (
+	"crypto/sha256"
+	"crypto/sha512"
+	"errors"
+	"hash"
+
+	"github.com/allisson/go-pwdhash/internal/encoding"
+	"github.com/allisson/go-pwdhash/internal/zero"
+)
+
+// MaxPasswordLength bounds the passwords SHA-crypt accepts. The checksum
+// hashes the password once per byte of its length, so its cost grows with the
+// square of the length; 256 bytes matches MySQL's limit and is far beyond any
+// password set through crypt(3).
+const MaxPasswordLength = 256
+
+// ErrPasswordTooLong indicates a password longer than MaxPasswordLength.
+var ErrPasswordTooLong = errors.New("sha-crypt password too long")
+
+// sha256Order and sha512Order list digest bytes in the order they are fed to
+// the hash64 encoder.
+var (
+	sha256Order = []int{
+		20, 10, 0, 11, 1, 21, 2, 22, 12, 23, 13, 3, 14, 4, 24, 5,
+		25, 15, 26, 16, 6, 17, 7, 27, 8, 28, 18, 29, 19, 9, 30, 31,
+	}
+	sha512Order = []int{
+		42, 21, 0, 1, 43, 22, 23, 2, 44, 45, 24, 3, 4, 46, 25, 26,
+		5, 47, 48, 27, 6, 7, 49, 28, 29, 8, 50, 51, 30, 9, 10, 52,
+		31, 32, 11, 53, 54, 33, 12, 13, 55, 34, 35, 14, 56, 57, 36, 15,
+		16, 58, 37, 38, 17, 59, 60, 39, 18, 19, 61, 40, 41, 20, 62, 63,
+	}
+)
+
+// SHA256 returns the encoded sha256-crypt checksum. Callers enforce any salt
+// length and round limits of their format.
+func SHA256(password, salt []byte, rounds int) (string, error) {
+	return checksum(sha256.New, sha256Order, password, salt, rounds)
+}
+
+// SHA512 returns the encoded sha512-crypt checksum.
+func SHA512(password, salt []byte, rounds int) (string, error) {
+	return checksum(sha512.New, sha512Order, password, salt, rounds)
+}
+
+// checksum implements Ulrich Drepper's SHA-crypt specification.
+func checksum(newHash func() hash.Hash, order []int, password, salt []byte, rounds int) (string, error) {
+	if len(password) > MaxPasswordLength {
+		return "", ErrPasswordTooLong
+	}
+
+	h := newHash()
+	size := h.Size()
+
+	h.Write(password)
+	h.Write(salt)
+	h.Write(password)
+	alternate := h.Sum(nil)
+	defer zero.Bytes(alternate)
+
+	h.Reset()
+	h.Write(password)
+	h.Write(salt)
+	for i := len(password); i > 0; i -= size {
+		h.Write(alternate[:min(i, size)])
+	}
+	for i := len(password); i > 0; i >>= 1 {
+		if i&1 != 0 {
+			h.Write(alternate)
+		} else {
+			h.Write(password)
+		}
+	}
+	digest := h.Sum(nil)
+	defer zero.Bytes(digest)
+
+	h.Reset()
+	for range len(password) {
+		h.Write(password)
+	}
+	p := repeat(h.Sum(nil), len(password))
+	defer zero.Bytes(p)
+
+	h.Reset()
+	for range 16 + int(digest[0]) {
+		h.Write(salt)
+	}
+	s := repeat(h.Sum(nil), len(salt))
+
+	for i := range rounds {
+		h.Reset()
+		if i&1 != 0 {
+			h.Write(p)
+		} else {
+			h.Write(digest)
+		}
+		if i%3 != 0 {
+			h.Write(s)
+		}
+		if i%7 != 0 {
+			h.Write(p)
+		}
+		if i&1 != 0 {
+			h.Write(digest)
+		} else {
+			h.Write(p)
+		}
+		digest = h.Sum(digest[:0])
+	}
+
+	return encoding.EncodeHash64Order(digest, order), nil
+}
+
+// repeat fills a buffer of length n with copies of block and wipes block.
+func repeat(block []byte, n int) []byte {
+	defer zero.Bytes(block)
+
+	out := make([]byte, n)
+	for i := 0; i < n; i += len(block) {
+		copy(out[i:], block)
+	}
+
+	return out
+}
@@ -0,0 +1,28 @@
+package shacrypt
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestChecksum(t *testing.T) {
+	sum, err := SHA256([]byte("password"), []byte("saltstring"), 5000)
+	require.NoError(t, err)
+	require.Equal(t, "OH4IDuTlsuTYPdED1gsuiRMyTAwNlRWyA6Xr3I4/dQ5", sum)
+
+	sum, err = SHA512([]byte("password"), []byte("saltstring"), 5000)
+	require.NoError(t, err)
+	require.Equal(t, "adDbXsJjcDlq2662QPgd.tkSOVmnG9Tt3oXl4HR60SusC3AGjirnDenVZp3DGwLwqy6iYKCzannhaX9DR72nN1", sum)
+}
+
+func TestChecksum_RejectsLongPasswords(t *testing.T) {
+	_, err := SHA256(make([]byte, MaxPasswordLength), []byte("saltstring"), 5000)
+	require.NoError(t, err)
+
+	_, err = SHA256(make([]byte, MaxPasswordLength+1), []byte("saltstring"), 5000)
+	require.ErrorIs(t, err, ErrPasswordTooLong)
+
+	_, err = SHA512(make([]byte, 64*1024), []byte("saltstring"), 5000)
+	require.ErrorIs(t, err, ErrPasswordTooLong)
+}
@@ -0,0 +1,46 @@
+package pwdhash
+
+import "fmt"
+
+// Keyring supplies versioned encryption keys to an EncryptingHasher.
+type Keyring interface {
+	// Current returns the version and material of the key used to seal new hashes.
+	Current() (version uint32, key []byte, err error)
+	// Key returns the material for a previously issued key version.
+	Key(version uint32) ([]byte, error)
+}
+
+// StaticKeyring is an in-memory Keyring backed by a fixed set of keys.
+type StaticKeyring struct {
+	current uint32
+	keys    map[uint32][]byte
+}
+
+// NewStaticKeyring builds a keyring whose active key is current.
+func NewStaticKeyring(current uint32, keys map[uint32][]byte) (*StaticKeyring, error) {
+	if _, ok := keys[current]; !ok {
+		return nil, fmt.Errorf("%w: %d", ErrKeyNotFound, current)
+	}
+
+	copied := make(map[uint32][]byte, len(keys))
+	for version, key := range keys {
+		copied[version] = append([]byte(nil), key...)
+	}
+
+	return &StaticKeyring{current: current, keys: copied}, nil
+}
+
+// Current returns the active key version and material.
+func (k *StaticKeyring) Current() (uint32, []byte, error) {
+	return k.current, k.keys[k.current], nil
+}
+
+// Key returns the material for the requested key version.
+func (k *StaticKeyring) Key(version uint32) ([]byte, error) {
+	key, ok := k.keys[version]
+	if !ok {
+		return nil, fmt.Errorf("%w: %d", ErrKeyNotFound, version)
+	}
+
+	return key, nil
+}
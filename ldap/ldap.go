@@ -0,0 +1,145 @@
+// Package ldap provides a verify-only adapter for migrating LDAP userPassword values.
+//
+// It understands the {SSHA}, {SSHA256}, {SSHA512}, and {SMD5} salted digests
+// written by OpenLDAP and 389-DS, and hands {CRYPT} values to the crypt and
+// bcrypt adapters. The salted digests are a single hash round, so NeedsRehash
+// reports true and the current hasher rewrites the value after login.
+package ldap
+
+import (
+	"crypto/md5"  // #nosec G501 -- required to verify legacy {SMD5} hashes
+	"crypto/sha1" // #nosec G505 -- required to verify legacy {SSHA} hashes
+	"crypto/sha256"
+	"crypto/sha512"
+	"encoding/base64"
+	"errors"
+	"hash"
+	"strings"
+
+	"github.com/allisson/go-pwdhash/bcrypt"
+	"github.com/allisson/go-pwdhash/crypt"
+	"github.com/allisson/go-pwdhash/internal/subtle"
+	"github.com/allisson/go-pwdhash/internal/zero"
+)
+
+const cryptScheme = "CRYPT"
+
+var errInvalidHash = errors.New("invalid ldap hash")
+
+// digests maps the salted scheme names to their hash constructors.
+var digests = map[string]func() hash.Hash{
+	"SSHA":    sha1.New,
+	"SSHA256": sha256.New,
+	"SSHA512": sha512.New,
+	"SMD5":    md5.New,
+}
+
+// cryptVerifier is implemented by the adapters {CRYPT} values are routed to.
+type cryptVerifier interface {
+	Recognize(encoded string) bool
+	Verify(password []byte, encoded string) (bool, error)
+}
+
+// cryptVerifiers are tried in order for the payload of a {CRYPT} value.
+var cryptVerifiers = []cryptVerifier{crypt.New(), bcrypt.New()}
+
+// Verifier checks LDAP {SCHEME}-prefixed password values. Scheme names are
+// matched case-insensitively, as directory servers do.
+type Verifier struct{}
+
+// New returns an LDAP Verifier.
+func New() *Verifier {
+	return &Verifier{}
+}
+
+// ID reports the scheme identifier.
+func (v *Verifier) ID() string {
+	return "ldap"
+}
+
+// Recognize reports whether encoded uses one of the supported LDAP schemes.
+func (v *Verifier) Recognize(encoded string) bool {
+	_, err := parse(encoded)
+	return err == nil
+}
+
+// Verify recomputes the salted digest, or delegates {CRYPT} values, and
+// compares in constant time.
+func (v *Verifier) Verify(password []byte, encoded string) (bool, error) {
+	defer zero.Bytes(password)
+
+	p, err := parse(encoded)
+	if err != nil {
+		return false, err
+	}
+
+	if p.crypt != nil {
+		return p.crypt.Verify(password, p.payload)
+	}
+
+	h := p.digest()
+	h.Write(password)
+	h.Write(p.salt)
+	got := h.Sum(nil)
+	defer zero.Bytes(got)
+
+	return subtle.ConstantTimeCompare(got, p.want), nil
+}
+
+// NeedsRehash always reports true for LDAP hashes.
+func (v *Verifier) NeedsRehash(encoded string) (bool, error) {
+	if _, err := parse(encoded); err != nil {
+		return false, err
+	}
+
+	return true, nil
+}
+
+// params holds a decoded salted digest or the payload of a {CRYPT} value.
+type params struct {
+	digest  func() hash.Hash
+	salt    []byte
+	want    []byte
+	crypt   cryptVerifier
+	payload string
+}
+
+// parse handles {SCHEME}<base64 digest || salt> and {CRYPT}<crypt(3) string>.
+func parse(encoded string) (*params, error) {
+	if !strings.HasPrefix(encoded, "{") {
+		return nil, errInvalidHash
+	}
+
+	scheme, payload, ok := strings.Cut(encoded[1:], "}")
+	if !ok {
+		return nil, errInvalidHash
+	}
+	scheme = strings.ToUpper(scheme)
+
+	if scheme == cryptScheme {
+		for _, c := range cryptVerifiers {
+			if c.Recognize(payload) {
+				return &params{crypt: c, payload: payload}, nil
+			}
+		}
+
+		return nil, errInvalidHash
+	}
+
+	digest, ok := digests[scheme]
+	if !ok {
+		return nil, errInvalidHash
+	}
+
+	raw, err := base64.StdEncoding.DecodeString(payload)
+	if err != nil {
+		return nil, errInvalidHash
+	}
+
+	size := digest().Size()
+	if len(raw) <= size {
+		return nil, errInvalidHash
+	}
+
+	return &params{digest: digest, want: raw[:size], salt: raw[size:]}, nil
+}
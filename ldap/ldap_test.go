@@ -0,0 +1,65 @@
+package ldap
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestVerifier_Verify(t *testing.T) {
+	tests := []struct {
+		name    string
+		encoded string
+	}{
+		{name: "ssha", encoded: "{SSHA}yrht1iYXEIkejLVu42JWkadd80RzYWx0c2FsdA=="},
+		{name: "sshaLowercase", encoded: "{ssha}yrht1iYXEIkejLVu42JWkadd80RzYWx0c2FsdA=="},
+		{name: "ssha256", encoded: "{SSHA256}DIzeh0gCRMTRu9dAH3C3rr7fWkRT0Bp2ZdtRqvTX3XJzYWx0c2FsdA=="},
+		{
+			name:    "ssha512",
+			encoded: "{SSHA512}9ZxHVj4YomwqqFiYKcIjExMLx2ZblYfXRGc4KMqbgvHq2+HOgwiTIi+eO/Uam/8D0beDAkGpvx14+UFlfBskLnNhbHRzYWx0",
+		},
+		{name: "smd5", encoded: "{SMD5}/b3zQZ//mL2wJBOQ9iqds3NhbHRzYWx0"},
+		{name: "cryptSHA512", encoded: "{CRYPT}$6$saltstring$adDbXsJjcDlq2662QPgd.tkSOVmnG9Tt3oXl4HR60SusC3AGjirnDenVZp3DGwLwqy6iYKCzannhaX9DR72nN1"},
+		{name: "cryptBcrypt", encoded: "{CRYPT}$2b$04$p4wSFWnH/3cP53yyCWSnEuxvwVcro/yOPi/Vi6hez5ZvWA8q1d7WO"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			verifier := New()
+			require.True(t, verifier.Recognize(tt.encoded))
+
+			ok, err := verifier.Verify([]byte("password"), tt.encoded)
+			require.NoError(t, err)
+			require.True(t, ok)
+
+			ok, err = verifier.Verify([]byte("wrong"), tt.encoded)
+			require.NoError(t, err)
+			require.False(t, ok)
+
+			needs, err := verifier.NeedsRehash(tt.encoded)
+			require.NoError(t, err)
+			require.True(t, needs)
+		})
+	}
+}
+
+func TestVerifier_Errors(t *testing.T) {
+	tests := []struct {
+		name    string
+		encoded string
+	}{
+		{name: "noScheme", encoded: "yrht1iYXEIkejLVu42JWkadd80RzYWx0c2FsdA=="},
+		{name: "unterminatedScheme", encoded: "{SSHA"},
+		{name: "unknownScheme", encoded: "{SHA3}yrht1iYXEIkejLVu42JWkadd80RzYWx0c2FsdA=="},
+		{name: "missingSalt", encoded: "{SSHA}yrht1iYXEIkejLVu42JWkadd80Q="},
+		{name: "badBase64", encoded: "{SSHA}!!!"},
+		{name: "cryptDES", encoded: "{CRYPT}saHW9GdxihkGQ"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := New().Verify([]byte("password"), tt.encoded)
+			require.Error(t, err)
+		})
+	}
+}
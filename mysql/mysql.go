@@ -0,0 +1,141 @@
+// Package mysql provides a verify-only adapter for migrating MySQL account hashes.
+//
+// It understands the authentication_string values written by the
+// mysql_native_password and caching_sha2_password plugins. The former is an
+// unsalted double SHA-1; NeedsRehash reports true for both so the current
+// hasher re-derives them from the plaintext seen at login.
+package mysql
+
+import (
+	"crypto/sha1" // #nosec G505 -- required to verify mysql_native_password hashes
+	"encoding/hex"
+	"errors"
+	"strconv"
+	"strings"
+
+	"github.com/allisson/go-pwdhash/internal/shacrypt"
+	"github.com/allisson/go-pwdhash/internal/subtle"
+	"github.com/allisson/go-pwdhash/internal/zero"
+)
+
+const (
+	nativePrefix = "*"
+	nativeLength = 1 + 2*sha1.Size
+
+	cachingPrefix      = "$A$"
+	cachingSaltLength  = 20
+	cachingDigestLen   = 43
+	cachingLength      = len(cachingPrefix) + 4 + cachingSaltLength + cachingDigestLen
+	roundsMultiplier   = 1000
+	cachingRoundsChars = 3
+)
+
+var errInvalidHash = errors.New("invalid mysql hash")
+
+// Verifier checks mysql_native_password and caching_sha2_password hashes.
+//
+// caching_sha2_password salts are raw bytes, so the authentication_string
+// column must be passed through unmodified rather than re-encoded as text.
+type Verifier struct{}
+
+// New returns a MySQL Verifier.
+func New() *Verifier {
+	return &Verifier{}
+}
+
+// ID reports the scheme identifier.
+func (v *Verifier) ID() string {
+	return "mysql"
+}
+
+// Recognize reports whether encoded is in one of the supported MySQL formats.
+func (v *Verifier) Recognize(encoded string) bool {
+	switch {
+	case strings.HasPrefix(encoded, nativePrefix):
+		_, err := parseNative(encoded)
+		return err == nil
+	case strings.HasPrefix(encoded, cachingPrefix):
+		_, _, _, err := parseCaching(encoded)
+		return err == nil
+	}
+
+	return false
+}
+
+// Verify recomputes the plugin's digest and compares it in constant time.
+func (v *Verifier) Verify(password []byte, encoded string) (bool, error) {
+	defer zero.Bytes(password)
+
+	switch {
+	case strings.HasPrefix(encoded, nativePrefix):
+		want, err := parseNative(encoded)
+		if err != nil {
+			return false, err
+		}
+
+		stage1 := sha1.Sum(password)  // #nosec G401 -- legacy verification only
+		stage2 := sha1.Sum(stage1[:]) // #nosec G401 -- legacy verification only
+		zero.Bytes(stage1[:])
+
+		return subtle.ConstantTimeCompare(stage2[:], want), nil
+	case strings.HasPrefix(encoded, cachingPrefix):
+		rounds, salt, want, err := parseCaching(encoded)
+		if err != nil {
+			return false, err
+		}
+
+		got, err := shacrypt.SHA256(password, salt, rounds)
+		if err != nil {
+			return false, err
+		}
+
+		return subtle.ConstantTimeCompare([]byte(got), []byte(want)), nil
+	}
+
+	return false, errInvalidHash
+}
+
+// NeedsRehash always reports true for MySQL hashes.
+func (v *Verifier) NeedsRehash(encoded string) (bool, error) {
+	if !v.Recognize(encoded) {
+		return false, errInvalidHash
+	}
+
+	return true, nil
+}
+
+// parseNative handles *<40 hex>, the double SHA-1 of the password.
+func parseNative(encoded string) ([]byte, error) {
+	if len(encoded) != nativeLength {
+		return nil, errInvalidHash
+	}
+
+	want, err := hex.DecodeString(encoded[len(nativePrefix):])
+	if err != nil {
+		return nil, errInvalidHash
+	}
+
+	return want, nil
+}
+
+// parseCaching handles $A$<rounds/1000 as 3 hex digits>$<salt:20><digest:43>,
+// a sha256-crypt digest over the full 20-byte salt.
+func parseCaching(encoded string) (int, []byte, string, error) {
+	if len(encoded) != cachingLength {
+		return 0, nil, "", errInvalidHash
+	}
+
+	rest := encoded[len(cachingPrefix):]
+	if rest[cachingRoundsChars] != '$' {
+		return 0, nil, "", errInvalidHash
+	}
+
+	count, err := strconv.ParseUint(rest[:cachingRoundsChars], 16, 16)
+	if err != nil || count < 1 {
+		return 0, nil, "", errInvalidHash
+	}
+
+	rest = rest[cachingRoundsChars+1:]
+
+	return int(count) * roundsMultiplier, []byte(rest[:cachingSaltLength]), rest[cachingSaltLength:], nil
+}
@@ -0,0 +1,84 @@
+package mysql
+
+import (
+	"bytes"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/allisson/go-pwdhash/internal/shacrypt"
+)
+
+func TestVerifier_Verify(t *testing.T) {
+	tests := []struct {
+		name     string
+		password string
+		encoded  string
+	}{
+		{
+			name:     "nativePassword",
+			password: "password",
+			encoded:  "*2470C0C06DEE42FD1618BB99005ADCA2EC9D1E19",
+		},
+		{
+			name:     "nativePasswordLowercase",
+			password: "password",
+			encoded:  "*2470c0c06dee42fd1618bb99005adca2ec9d1e19",
+		},
+		{
+			name:     "cachingSHA2",
+			password: "hashcat",
+			encoded:  "$A$005$\xf9\xcc\x98\xce\x08\x89)$\xf5\n!;k\xc5q\xa2\xc1\x17x\xc5bTy95Y99eAME1dwEkHOA1ndHGBWz.1bxSSRkuTXFGV/",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			verifier := New()
+			require.True(t, verifier.Recognize(tt.encoded))
+
+			ok, err := verifier.Verify([]byte(tt.password), tt.encoded)
+			require.NoError(t, err)
+			require.True(t, ok)
+
+			ok, err = verifier.Verify([]byte("wrong"), tt.encoded)
+			require.NoError(t, err)
+			require.False(t, ok)
+
+			needs, err := verifier.NeedsRehash(tt.encoded)
+			require.NoError(t, err)
+			require.True(t, needs)
+		})
+	}
+}
+
+func TestVerifier_Errors(t *testing.T) {
+	tests := []struct {
+		name    string
+		encoded string
+	}{
+		{name: "unknownFormat", encoded: "$1$saltstri$qQY4WxjABChYG1ccLpfkz/"},
+		{name: "nativeTruncated", encoded: "*2470C0C06DEE42FD1618BB99005ADCA2EC9D1E"},
+		{name: "nativeNotHex", encoded: "*2470C0C06DEE42FD1618BB99005ADCA2EC9D1EZZ"},
+		{name: "cachingTruncated", encoded: "$A$005$saltsaltsaltsaltsalt"},
+		{name: "cachingBadRounds", encoded: "$A$0G5$saltsaltsaltsaltsaltbTy95Y99eAME1dwEkHOA1ndHGBWz.1bxSSRkuTXFGV/"},
+		{name: "cachingZeroRounds", encoded: "$A$000$saltsaltsaltsaltsaltbTy95Y99eAME1dwEkHOA1ndHGBWz.1bxSSRkuTXFGV/"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			verifier := New()
+			require.False(t, verifier.Recognize(tt.encoded))
+
+			_, err := verifier.Verify([]byte("password"), tt.encoded)
+			require.Error(t, err)
+		})
+	}
+}
+
+func TestVerifier_RejectsLongPasswords(t *testing.T) {
+	password := bytes.Repeat([]byte("a"), 64*1024)
+
+	_, err := New().Verify(password, "$A$005$\xf9\xcc\x98\xce\x08\x89)$\xf5\n!;k\xc5q\xa2\xc1\x17x\xc5bTy95Y99eAME1dwEkHOA1ndHGBWz.1bxSSRkuTXFGV/")
+	require.ErrorIs(t, err, shacrypt.ErrPasswordTooLong)
+}
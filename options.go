@@ -1,22 +1,36 @@
 package pwdhash
 
-import "github.com/allisson/go-pwdhash/argon2"
+import (
+	"crypto/fips140"
+
+	"github.com/allisson/go-pwdhash/argon2"
+)
 
 // config holds PasswordHasher construction settings.
 //
 // These defaults are internal helpers; prefer With* options for user-facing
 // configuration until the API stabilizes.
 type config struct {
-	current Hasher
+	current    Hasher
+	verifiers  []Verifier
+	validators []Validator
+	fips       bool
+	argon2     bool
 }
 
 // Option configures PasswordHasher construction.
 type Option func(*config)
 
-// defaultConfig initializes a config with the default Argon2id hasher.
+// defaultConfig initializes a config with verify-only support for legacy
+// argon2i and argon2d hashes and FIPS mode following crypto/fips140. The
+// current hasher is chosen by New once every option has been applied.
 func defaultConfig() *config {
 	return &config{
-		current: argon2.Default(),
+		verifiers: []Verifier{
+			argon2.NewLegacyVerifier(argon2.Argon2i),
+			argon2.NewLegacyVerifier(argon2.Argon2d),
+		},
+		fips: fips140.Enabled(),
 	}
 }
 
@@ -27,6 +41,23 @@ func WithHasher(h Hasher) Option {
 	}
 }
 
+// WithVerifier registers a verify-only scheme so its hashes can be checked and rehashed.
+func WithVerifier(v Verifier) Option {
+	return func(c *config) {
+		c.verifiers = append(c.verifiers, v)
+	}
+}
+
+// WithValidator checks every password passed to Hash, HashSecret, and
+// HashWithAD, returning the validator's error instead of a hash. Validators
+// run in registration order and the first error wins. Verify is unaffected,
+// so existing passwords keep working when the rules tighten.
+func WithValidator(v Validator) Option {
+	return func(c *config) {
+		c.validators = append(c.validators, v)
+	}
+}
+
 // WithPolicy selects a preset Argon2id configuration for the PasswordHasher.
 func WithPolicy(p Policy) Option {
 	return func(c *config) {
@@ -44,3 +75,25 @@ func WithPolicy(p Policy) Option {
 		}
 	}
 }
+
+// WithFIPS enables FIPS 140-3 mode even when the Go FIPS module is not active.
+//
+// In FIPS mode the default hasher is pbkdf2.NewHasher, any other current hasher
+// (including the Argon2id presets of WithPolicy) makes New fail with
+// ErrNotApproved, and verifiers for unapproved schemes such as Argon2,
+// bcrypt, scrypt, and crypt(3) are dropped, whichever option registered them.
+// Argon2 hashes are verified again when WithArgon2Migration is set.
+func WithFIPS() Option {
+	return func(c *config) {
+		c.fips = true
+	}
+}
+
+// WithArgon2Migration keeps verify-only Argon2 support in FIPS mode, so
+// existing Argon2 hashes still log users in and are reported by NeedsRehash
+// for replacement with PBKDF2.
+func WithArgon2Migration() Option {
+	return func(c *config) {
+		c.argon2 = true
+	}
+}
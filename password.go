@@ -2,15 +2,21 @@
 package pwdhash
 
 import (
+	"errors"
 	"fmt"
 
+	"github.com/allisson/go-pwdhash/argon2"
 	"github.com/allisson/go-pwdhash/internal/encoding"
+	"github.com/allisson/go-pwdhash/internal/zero"
 )
 
 // PasswordHasher manages password hashing operations via registered algorithms.
 type PasswordHasher struct {
-	current  Hasher
-	registry map[string]Hasher
+	current     Hasher
+	registry    map[string]Verifier
+	recognizers []Recognizer
+	validators  []Validator
+	fips        bool
 }
 
 // New constructs a PasswordHasher configured via the provided options.
@@ -21,46 +27,171 @@ func New(opts ...Option) (*PasswordHasher, error) {
 		opt(cfg)
 	}
 
-	reg := make(map[string]Hasher)
-	reg[cfg.current.ID()] = cfg.current
+	if cfg.fips {
+		if err := cfg.applyFIPS(); err != nil {
+			return nil, err
+		}
+	}
+
+	if cfg.current == nil {
+		cfg.current = argon2.Default()
+	}
+
+	reg := make(map[string]Verifier)
+	var recognizers []Recognizer
+	for _, v := range append(cfg.verifiers, cfg.current) {
+		reg[v.ID()] = v
+		if r, ok := v.(Recognizer); ok {
+			recognizers = append(recognizers, r)
+		}
+	}
 
 	return &PasswordHasher{
-		current:  cfg.current,
-		registry: reg,
+		current:     cfg.current,
+		registry:    reg,
+		recognizers: recognizers,
+		validators:  cfg.validators,
+		fips:        cfg.fips,
 	}, nil
 }
 
-// Hash encodes the provided password using the active hasher.
+// Hash encodes the provided password using the active hasher, after checking
+// it with the validators configured through WithValidator. The password is
+// zeroized even when a validator rejects it.
 func (p *PasswordHasher) Hash(password []byte) (string, error) {
+	defer zero.Bytes(password)
+
+	if err := p.validate(password); err != nil {
+		return "", err
+	}
+
+	return p.current.Hash(password)
+}
+
+// HashSecret encodes the secret using the active hasher. The secret is copied,
+// so it stays usable until the caller destroys it.
+func (p *PasswordHasher) HashSecret(secret *Secret) (string, error) {
+	password, err := secret.reveal()
+	if err != nil {
+		return "", err
+	}
+
+	defer zero.Bytes(password)
+
+	return p.Hash(password)
+}
+
+// rehashSecret encodes an already verified secret with the active hasher,
+// bypassing the Validator.
+func (p *PasswordHasher) rehashSecret(secret *Secret) (string, error) {
+	password, err := secret.reveal()
+	if err != nil {
+		return "", err
+	}
+
+	defer zero.Bytes(password)
+
 	return p.current.Hash(password)
 }
 
+// HashWithAD encodes the password bound to ad using the active hasher.
+func (p *PasswordHasher) HashWithAD(password, ad []byte) (string, error) {
+	defer zero.Bytes(password)
+
+	hasher, ok := p.current.(AssociatedDataHasher)
+	if !ok {
+		return "", ErrAssociatedDataUnsupported
+	}
+
+	if err := p.validate(password); err != nil {
+		return "", err
+	}
+
+	return hasher.HashWithAD(password, ad)
+}
+
 // Verify checks whether the encoded hash matches the provided password.
 func (p *PasswordHasher) Verify(password []byte, encoded string) (bool, error) {
-	parsed, err := encoding.Parse(encoded)
+	hasher, err := p.lookup(encoded)
 	if err != nil {
 		return false, err
 	}
 
-	hasher, ok := p.registry[parsed.Algorithm]
-	if !ok {
-		return false, fmt.Errorf("unknown hash algorithm: %s", parsed.Algorithm)
+	return hasher.Verify(password, encoded)
+}
+
+// VerifySecret checks whether the encoded hash matches the secret. The secret
+// is copied, so it stays usable until the caller destroys it.
+func (p *PasswordHasher) VerifySecret(secret *Secret, encoded string) (bool, error) {
+	password, err := secret.reveal()
+	if err != nil {
+		return false, err
 	}
 
-	return hasher.Verify(password, encoded)
+	defer zero.Bytes(password)
+
+	return p.Verify(password, encoded)
 }
 
-// NeedsRehash reports whether the encoded hash should be regenerated.
-func (p *PasswordHasher) NeedsRehash(encoded string) (bool, error) {
-	parsed, err := encoding.Parse(encoded)
+// VerifyWithAD checks whether the encoded hash matches the password and associated data.
+func (p *PasswordHasher) VerifyWithAD(password, ad []byte, encoded string) (bool, error) {
+	hasher, err := p.lookup(encoded)
 	if err != nil {
 		return false, err
 	}
 
-	hasher, ok := p.registry[parsed.Algorithm]
+	adVerifier, ok := hasher.(AssociatedDataVerifier)
 	if !ok {
+		return false, ErrAssociatedDataUnsupported
+	}
+
+	return adVerifier.VerifyWithAD(password, ad, encoded)
+}
+
+// NeedsRehash reports whether the encoded hash should be regenerated.
+func (p *PasswordHasher) NeedsRehash(encoded string) (bool, error) {
+	hasher, err := p.lookup(encoded)
+	if errors.Is(err, ErrUnknownAlgorithm) {
 		return true, nil
 	}
+	if err != nil {
+		return false, err
+	}
 
 	return hasher.NeedsRehash(encoded)
 }
+
+// validate runs the configured validators in order.
+func (p *PasswordHasher) validate(password []byte) error {
+	for _, v := range p.validators {
+		if err := v.Validate(password); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// lookup resolves the verifier responsible for encoded. PHC strings are routed
+// by algorithm identifier; anything else is offered to registered recognizers
+// in registration order.
+func (p *PasswordHasher) lookup(encoded string) (Verifier, error) {
+	parsed, err := encoding.Parse(encoded)
+	if err == nil {
+		if hasher, ok := p.registry[parsed.Algorithm]; ok {
+			return hasher, nil
+		}
+	}
+
+	for _, r := range p.recognizers {
+		if r.Recognize(encoded) {
+			return r, nil
+		}
+	}
+
+	if err != nil {
+		return nil, err
+	}
+
+	return nil, fmt.Errorf("%w: %s", ErrUnknownAlgorithm, parsed.Algorithm)
+}
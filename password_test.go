@@ -1,9 +1,22 @@
 package pwdhash
 
 import (
+	"context"
+	"strings"
 	"testing"
 
 	"github.com/allisson/go-pwdhash/argon2"
+	"github.com/allisson/go-pwdhash/aspnet"
+	"github.com/allisson/go-pwdhash/bcrypt"
+	"github.com/allisson/go-pwdhash/breach"
+	"github.com/allisson/go-pwdhash/crypt"
+	"github.com/allisson/go-pwdhash/ldap"
+	"github.com/allisson/go-pwdhash/mysql"
+	"github.com/allisson/go-pwdhash/pbkdf2"
+	"github.com/allisson/go-pwdhash/phpass"
+	"github.com/allisson/go-pwdhash/policy"
+	"github.com/allisson/go-pwdhash/postgres"
+	"github.com/allisson/go-pwdhash/wrap"
 	"github.com/stretchr/testify/require"
 )
 
@@ -37,7 +50,7 @@ func TestPasswordHasher_VerifyUnknownAlgorithm(t *testing.T) {
 	require.NoError(t, err)
 
 	ok, err := ph.Verify([]byte("pw"), "$argon2id$v=19$m=1,t=1,p=1$YWJj$ZGVm")
-	require.Error(t, err)
+	require.ErrorIs(t, err, ErrUnknownAlgorithm)
 	require.False(t, ok)
 }
 
@@ -73,6 +86,116 @@ func TestPasswordHasher_NeedsRehashDelegates(t *testing.T) {
 	require.True(t, needs)
 }
 
+func TestPasswordHasher_AssociatedDataUnsupported(t *testing.T) {
+	ph, err := New(WithHasher(&fakeHasher{id: "argon2id"}))
+	require.NoError(t, err)
+
+	_, err = ph.HashWithAD([]byte("pw"), []byte("user-1"))
+	require.ErrorIs(t, err, ErrAssociatedDataUnsupported)
+
+	_, err = ph.VerifyWithAD([]byte("pw"), []byte("user-1"), "$argon2id$v=19$m=1,t=1,p=1$YWJj$ZGVm")
+	require.ErrorIs(t, err, ErrAssociatedDataUnsupported)
+}
+
+func TestPasswordHasher_HashWithAD(t *testing.T) {
+	ph, err := New(WithHasher(NewEncryptingHasher(newTestArgon2(), newTestKeyring(t, 1))))
+	require.NoError(t, err)
+
+	encoded, err := ph.HashWithAD([]byte("pw"), []byte("user-1"))
+	require.NoError(t, err)
+
+	ok, err := ph.VerifyWithAD([]byte("pw"), []byte("user-1"), encoded)
+	require.NoError(t, err)
+	require.True(t, ok)
+
+	ok, err = ph.VerifyWithAD([]byte("pw"), []byte("user-2"), encoded)
+	require.NoError(t, err)
+	require.False(t, ok)
+}
+
+func TestPasswordHasher_VerifiesLegacyArgon2(t *testing.T) {
+	ph, err := New()
+	require.NoError(t, err)
+
+	legacy := "$argon2i$v=19$m=65536,t=2,p=1$c29tZXNhbHQ$wWKIMhR9lyDFvRz9YTZweHKfbftvj+qf+YFY4NeBbtA"
+
+	ok, err := ph.Verify([]byte("password"), legacy)
+	require.NoError(t, err)
+	require.True(t, ok)
+
+	needs, err := ph.NeedsRehash(legacy)
+	require.NoError(t, err)
+	require.True(t, needs)
+}
+
+func TestPasswordHasher_WithVerifier(t *testing.T) {
+	legacy := &fakeHasher{id: "legacy", verifyResult: true, rehashNeeded: true}
+	ph, err := New(WithVerifier(legacy))
+	require.NoError(t, err)
+
+	ok, err := ph.Verify([]byte("pw"), "$legacy$v=1$c=1$YWJj$ZGVm")
+	require.NoError(t, err)
+	require.True(t, ok)
+
+	needs, err := ph.NeedsRehash("$legacy$v=1$c=1$YWJj$ZGVm")
+	require.NoError(t, err)
+	require.True(t, needs)
+}
+
+func TestPasswordHasher_VerifiesBcrypt(t *testing.T) {
+	ph, err := New(WithVerifier(bcrypt.New()))
+	require.NoError(t, err)
+
+	legacy := "{bcrypt}$2a$10$dXJ3SW6G7P50lGmMkkmwe.20cQQubK3.HZWzG3YB1tlRy.fqvM/BG"
+
+	ok, err := ph.Verify([]byte("password"), legacy)
+	require.NoError(t, err)
+	require.True(t, ok)
+
+	needs, err := ph.NeedsRehash(legacy)
+	require.NoError(t, err)
+	require.True(t, needs)
+
+	_, err = ph.Verify([]byte("password"), "unknown-format")
+	require.Error(t, err)
+}
+
+func TestPasswordHasher_VerifiesMixedLegacyFormats(t *testing.T) {
+	ph, err := New(
+		WithVerifier(bcrypt.New()),
+		WithVerifier(pbkdf2.New()),
+		WithVerifier(crypt.New()),
+		WithVerifier(aspnet.New()),
+		WithVerifier(phpass.New()),
+		WithVerifier(mysql.New()),
+		WithVerifier(postgres.New()),
+		WithVerifier(ldap.New()),
+	)
+	require.NoError(t, err)
+
+	for _, encoded := range []string{
+		"$2b$04$p4wSFWnH/3cP53yyCWSnEuxvwVcro/yOPi/Vi6hez5ZvWA8q1d7WO",
+		"pbkdf2_sha256$1000$seasalt$YIWkt6M1JFXrHg5s0jZjBSc7C2Cz6QvchSJ0h8Y+i7c=",
+		"pbkdf2:sha256:5000$Xy3kQz9a$c49d83487b8fec99c4f293f4dae6283e1df7d414af324b3582ac49dcf5b1cf83",
+		"$6$saltstring$adDbXsJjcDlq2662QPgd.tkSOVmnG9Tt3oXl4HR60SusC3AGjirnDenVZp3DGwLwqy6iYKCzannhaX9DR72nN1",
+		"$y$j9T$F5Jx5fExrKuPp53xLKQ..1$tnSYvahCwPBHKZUspmcxMfb0.WiB9W.zEaKlOBL35rC",
+		"AQAAAAEAACcQAAAAEAABAgMEBQYHCAkKCwwNDg/rbIFTVZIgPAkrFY+NOQlnI2Km9dvQDZgoBEy6qLJS6Q==",
+		"$H$9saltsaltTPYWOFleH9nxJ26A2VSHl1",
+		"*2470C0C06DEE42FD1618BB99005ADCA2EC9D1E19",
+		"SCRAM-SHA-256$4096:MDEyMzQ1Njc4OWFiY2RlZg==$wjGCKoCIcEWiPxSG7t/wnb/YICMEFr1JZNZSKNje12g=:6NG/vkzOjK2oyl11qeNEBeKuOY3QQ4atswXYbIBO79Q=",
+		postgres.MD5Hash("md532e12f215ba27cb750c9e093ce4b5127", "postgres"),
+		"{SSHA}yrht1iYXEIkejLVu42JWkadd80RzYWx0c2FsdA==",
+	} {
+		ok, err := ph.Verify([]byte("password"), encoded)
+		require.NoError(t, err)
+		require.True(t, ok, encoded)
+
+		needs, err := ph.NeedsRehash(encoded)
+		require.NoError(t, err)
+		require.True(t, needs, encoded)
+	}
+}
+
 func TestPasswordHasher_WithPolicy(t *testing.T) {
 	t.Parallel()
 
@@ -133,3 +256,121 @@ func TestNewSetsDefaultHasher(t *testing.T) {
 	_, ok := ph.registry[argon2.Default().ID()]
 	require.True(t, ok)
 }
+
+func TestPasswordHasher_VerifiesWrappedHashes(t *testing.T) {
+	wrapper := wrap.New(newTestArgon2())
+	ph, err := New(WithHasher(newTestArgon2()), WithVerifier(wrapper))
+	require.NoError(t, err)
+
+	wrapped, err := wrapper.Wrap(wrap.InnerSHA1, "5baa61e4c9b93f3f0682250b6cf8331b7ee68fd8")
+	require.NoError(t, err)
+
+	ok, err := ph.Verify([]byte("password"), wrapped)
+	require.NoError(t, err)
+	require.True(t, ok)
+
+	needs, err := ph.NeedsRehash(wrapped)
+	require.NoError(t, err)
+	require.True(t, needs)
+}
+
+func TestPasswordHasher_VerifiesFrameworkFormats(t *testing.T) {
+	writer := newTestArgon2()
+	writer.Format = argon2.FormatDjango
+
+	ph, err := New(WithHasher(newTestArgon2()))
+	require.NoError(t, err)
+
+	django, err := writer.Hash([]byte("password"))
+	require.NoError(t, err)
+
+	for _, encoded := range []string{
+		django,
+		"{argon2}" + strings.TrimPrefix(django, "argon2"),
+		"argon2$argon2i$v=19$m=65536,t=2,p=1$c29tZXNhbHQ$wWKIMhR9lyDFvRz9YTZweHKfbftvj+qf+YFY4NeBbtA",
+	} {
+		ok, err := ph.Verify([]byte("password"), encoded)
+		require.NoError(t, err)
+		require.True(t, ok, encoded)
+	}
+
+	needs, err := ph.NeedsRehash(django)
+	require.NoError(t, err)
+	require.False(t, needs)
+}
+
+func TestPasswordHasher_WithValidator(t *testing.T) {
+	ph, err := New(WithHasher(newTestArgon2()), WithValidator(policy.New()))
+	require.NoError(t, err)
+
+	_, err = ph.Hash([]byte("password"))
+	var perr *policy.Error
+	require.ErrorAs(t, err, &perr)
+	require.True(t, perr.Has(policy.CodeBlocklisted))
+
+	_, err = ph.HashSecret(NewSecretString("short"))
+	require.ErrorAs(t, err, &perr)
+
+	encoded, err := ph.Hash([]byte("correct horse battery staple"))
+	require.NoError(t, err)
+
+	ok, err := ph.Verify([]byte("correct horse battery staple"), encoded)
+	require.NoError(t, err)
+	require.True(t, ok)
+
+	// Existing passwords that no longer pass the policy still verify and upgrade.
+	legacy := "$argon2i$v=19$m=65536,t=2,p=1$c29tZXNhbHQ$wWKIMhR9lyDFvRz9YTZweHKfbftvj+qf+YFY4NeBbtA"
+	store := NewMemoryStore()
+	require.NoError(t, store.UpdateHash(context.Background(), "alice", legacy))
+
+	auth, err := NewAuthenticator(ph, store)
+	require.NoError(t, err)
+
+	result, err := auth.Authenticate(context.Background(), "alice", NewSecretString("password"))
+	require.NoError(t, err)
+	require.True(t, result.OK())
+	require.True(t, result.Rehashed)
+	require.NoError(t, result.RehashErr)
+}
+
+func TestPasswordHasher_ZeroizesRejectedPasswords(t *testing.T) {
+	ph, err := New(WithHasher(newTestArgon2()), WithValidator(policy.New()))
+	require.NoError(t, err)
+
+	password := []byte("password")
+	_, err = ph.Hash(password)
+	require.Error(t, err)
+	require.Equal(t, make([]byte, len(password)), password)
+
+	password = []byte("short")
+	_, err = ph.HashWithAD(password, []byte("tenant"))
+	var perr *policy.Error
+	require.ErrorAs(t, err, &perr)
+	require.Equal(t, make([]byte, len(password)), password)
+}
+
+func TestPasswordHasher_RefusesBreachedPasswords(t *testing.T) {
+	bloom, err := breach.NewBloom(breach.KindSHA1, 1, 0.0001)
+	require.NoError(t, err)
+
+	digest, err := breach.KindSHA1.Digest([]byte("correct horse battery staple"))
+	require.NoError(t, err)
+	require.NoError(t, bloom.Add(digest))
+
+	ph, err := New(
+		WithHasher(newTestArgon2()),
+		WithValidator(policy.New()),
+		WithValidator(breach.NewValidator(bloom)),
+	)
+	require.NoError(t, err)
+
+	_, err = ph.Hash([]byte("correct horse battery staple"))
+	require.ErrorIs(t, err, breach.ErrBreached)
+
+	_, err = ph.Hash([]byte("short"))
+	var perr *policy.Error
+	require.ErrorAs(t, err, &perr)
+
+	_, err = ph.Hash([]byte("purple monkey dishwasher"))
+	require.NoError(t, err)
+}
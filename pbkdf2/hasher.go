@@ -0,0 +1,195 @@
+package pbkdf2
+
+import (
+	"crypto/pbkdf2"
+	"crypto/rand"
+	"fmt"
+	"strconv"
+
+	"github.com/allisson/go-pwdhash/internal/encoding"
+	"github.com/allisson/go-pwdhash/internal/subtle"
+	"github.com/allisson/go-pwdhash/internal/zero"
+)
+
+// Digest names the HMAC hash used by Hasher.
+type Digest string
+
+const (
+	// DigestSHA256 selects PBKDF2-HMAC-SHA256.
+	DigestSHA256 Digest = "sha256"
+	// DigestSHA512 selects PBKDF2-HMAC-SHA512.
+	DigestSHA512 Digest = "sha512"
+)
+
+// Hasher produces PBKDF2 hashes for deployments restricted to FIPS 140-3
+// approved algorithms, where Argon2id is unavailable.
+//
+// Output is a PHC string such as $pbkdf2-sha256$i=600000$<salt>$<hash>.
+// Validation enforces the SP 800-132 floors of a 128-bit salt, a 112-bit key,
+// and 1000 iterations.
+type Hasher struct {
+	Digest     Digest
+	Iterations int
+	SaltLength int
+	KeyLength  int
+}
+
+// NewHasher returns a PBKDF2-HMAC-SHA256 Hasher with 600000 iterations, as
+// recommended by OWASP.
+func NewHasher() *Hasher {
+	return &Hasher{
+		Digest:     DigestSHA256,
+		Iterations: 600_000,
+		SaltLength: 16,
+		KeyLength:  32,
+	}
+}
+
+// ID reports the PHC algorithm identifier.
+func (h *Hasher) ID() string {
+	return "pbkdf2-" + string(h.Digest)
+}
+
+// Recognize reports whether encoded is a PBKDF2 PHC string of any supported digest.
+func (h *Hasher) Recognize(encoded string) bool {
+	_, err := parsePHC(encoded)
+	return err == nil
+}
+
+// Hash derives a PBKDF2 key from a random salt, zeroizes inputs, and returns the PHC string.
+func (h *Hasher) Hash(password []byte) (string, error) {
+	if err := h.validate(); err != nil {
+		return "", err
+	}
+
+	defer zero.Bytes(password)
+
+	salt := make([]byte, h.SaltLength)
+	if _, err := rand.Read(salt); err != nil {
+		return "", err
+	}
+
+	defer zero.Bytes(salt)
+
+	key, err := pbkdf2.Key(digests[string(h.Digest)], string(password), salt, h.Iterations, h.KeyLength)
+	if err != nil {
+		return "", err
+	}
+
+	defer zero.Bytes(key)
+
+	enc := encoding.EncodedHash{
+		Algorithm: h.ID(),
+		Params: map[string]string{
+			"i": strconv.Itoa(h.Iterations),
+		},
+		Salt: salt,
+		Hash: key,
+	}
+
+	return enc.String(), nil
+}
+
+// Verify recomputes the PBKDF2 key and compares it in constant time.
+func (h *Hasher) Verify(password []byte, encoded string) (bool, error) {
+	defer zero.Bytes(password)
+
+	p, err := parsePHC(encoded)
+	if err != nil {
+		return false, err
+	}
+
+	defer zero.Bytes(p.want)
+
+	if p.iterations < 1 || p.iterations > MaxIterations {
+		return false, fmt.Errorf("pbkdf2 iterations out of range")
+	}
+	if len(p.want) < 1 || len(p.want) > MaxKeyLength {
+		return false, fmt.Errorf("pbkdf2 key length out of range")
+	}
+
+	key, err := pbkdf2.Key(p.digest, string(password), p.salt, p.iterations, len(p.want))
+	if err != nil {
+		return false, err
+	}
+
+	defer zero.Bytes(key)
+
+	return subtle.ConstantTimeCompare(key, p.want), nil
+}
+
+// NeedsRehash reports whether the digest, iteration count, salt, or key length
+// diverge from the current configuration.
+func (h *Hasher) NeedsRehash(encoded string) (bool, error) {
+	parsed, err := encoding.Parse(encoded)
+	if err != nil {
+		return false, err
+	}
+
+	if _, err := parsePHC(encoded); err != nil {
+		return false, err
+	}
+
+	if parsed.Algorithm != h.ID() {
+		return true, nil
+	}
+
+	if parsed.Params["i"] != strconv.Itoa(h.Iterations) {
+		return true, nil
+	}
+
+	if len(parsed.Salt) != h.SaltLength || len(parsed.Hash) != h.KeyLength {
+		return true, nil
+	}
+
+	return false, nil
+}
+
+func (h *Hasher) validate() error {
+	if _, ok := phcAlgorithms["pbkdf2-"+string(h.Digest)]; !ok {
+		return fmt.Errorf("unsupported pbkdf2 digest: %s", h.Digest)
+	}
+	if h.Iterations < MinIterations {
+		return fmt.Errorf("pbkdf2 iterations too low")
+	}
+	if h.Iterations > MaxIterations {
+		return fmt.Errorf("pbkdf2 iterations too high")
+	}
+	if h.SaltLength < MinSaltLength {
+		return fmt.Errorf("pbkdf2 salt too short")
+	}
+	if h.KeyLength < MinKeyLength {
+		return fmt.Errorf("pbkdf2 key length too low")
+	}
+	if h.KeyLength > MaxKeyLength {
+		return fmt.Errorf("pbkdf2 key length too high")
+	}
+	return nil
+}
+
+// phcAlgorithms maps the PHC identifiers written by Hasher to their digest names.
+var phcAlgorithms = map[string]string{
+	"pbkdf2-sha256": "sha256",
+	"pbkdf2-sha512": "sha512",
+}
+
+// parsePHC handles $pbkdf2-<digest>$i=<iterations>$<salt>$<hash>, with salt and
+// hash in unpadded standard base64.
+func parsePHC(encoded string) (*params, error) {
+	parsed, err := encoding.Parse(encoded)
+	if err != nil {
+		return nil, err
+	}
+
+	name, ok := phcAlgorithms[parsed.Algorithm]
+	if !ok || parsed.Version != 0 || len(parsed.Params) != 1 {
+		return nil, errInvalidHash
+	}
+
+	iterations, err := strconv.Atoi(parsed.Params["i"])
+	if err != nil {
+		return nil, err
+	}
+
+	return &params{digest: digests[name], iterations: iterations, salt: parsed.Salt, want: parsed.Hash}, nil
+}
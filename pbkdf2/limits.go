@@ -0,0 +1,9 @@
+package pbkdf2
+
+const (
+	MinIterations = 1000
+	MinSaltLength = 16
+	MinKeyLength  = 14
+	MaxIterations = 10_000_000
+	MaxKeyLength  = 128
+)
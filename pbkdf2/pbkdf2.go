@@ -0,0 +1,272 @@
+// Package pbkdf2 hashes and verifies PBKDF2 passwords.
+//
+// Hasher writes PHC strings for FIPS 140-3 deployments where Argon2id is
+// unavailable. Verifier only reads: it accepts the Django, passlib, Werkzeug,
+// Atlassian {PKCS5S2}, and Cisco type 8 encodings as well as Hasher's output,
+// and its NeedsRehash reports true so imported hashes move to the current
+// hasher.
+package pbkdf2
+
+import (
+	"crypto/pbkdf2"
+	"crypto/sha1" // #nosec G505 -- required to verify legacy PBKDF2-HMAC-SHA1 hashes
+	"crypto/sha256"
+	"crypto/sha512"
+	"encoding/base64"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"hash"
+	"strconv"
+	"strings"
+
+	"github.com/allisson/go-pwdhash/internal/encoding"
+	"github.com/allisson/go-pwdhash/internal/subtle"
+	"github.com/allisson/go-pwdhash/internal/zero"
+)
+
+const (
+	atlassianPrefix     = "{PKCS5S2}"
+	atlassianIterations = 10000
+	atlassianSaltLength = 16
+	atlassianKeyLength  = 32
+
+	ciscoPrefix     = "$8$"
+	ciscoIterations = 20000
+	ciscoSaltLength = 14
+	ciscoHashLength = 43
+)
+
+var errInvalidHash = errors.New("invalid pbkdf2 hash")
+
+// digests maps the hash names used across formats to their constructors.
+var digests = map[string]func() hash.Hash{
+	"sha1":   sha1.New,
+	"sha256": sha256.New,
+	"sha512": sha512.New,
+}
+
+// djangoPrefixes and passlibPrefixes map each format marker to its digest name.
+var (
+	djangoPrefixes = map[string]string{
+		"pbkdf2_sha1$":   "sha1",
+		"pbkdf2_sha256$": "sha256",
+	}
+	passlibPrefixes = map[string]string{
+		"$pbkdf2$":        "sha1",
+		"$pbkdf2-sha256$": "sha256",
+		"$pbkdf2-sha512$": "sha512",
+	}
+)
+
+// Verifier checks PBKDF2 hashes in the Django, passlib, Werkzeug, Atlassian,
+// and Cisco type 8 formats.
+type Verifier struct{}
+
+// New returns a PBKDF2 Verifier.
+func New() *Verifier {
+	return &Verifier{}
+}
+
+// ID reports the scheme identifier.
+func (v *Verifier) ID() string {
+	return "pbkdf2"
+}
+
+// Recognize reports whether encoded is in one of the supported PBKDF2 formats.
+func (v *Verifier) Recognize(encoded string) bool {
+	_, err := parse(encoded)
+	return err == nil
+}
+
+// Verify recomputes the PBKDF2 key and compares it in constant time.
+func (v *Verifier) Verify(password []byte, encoded string) (bool, error) {
+	defer zero.Bytes(password)
+
+	p, err := parse(encoded)
+	if err != nil {
+		return false, err
+	}
+
+	defer zero.Bytes(p.want)
+
+	if p.iterations < 1 || p.iterations > MaxIterations {
+		return false, fmt.Errorf("pbkdf2 iterations out of range")
+	}
+	if len(p.want) < 1 || len(p.want) > MaxKeyLength {
+		return false, fmt.Errorf("pbkdf2 key length out of range")
+	}
+
+	key, err := pbkdf2.Key(p.digest, string(password), p.salt, p.iterations, len(p.want))
+	if err != nil {
+		return false, err
+	}
+
+	defer zero.Bytes(key)
+
+	return subtle.ConstantTimeCompare(key, p.want), nil
+}
+
+// NeedsRehash always reports true for PBKDF2 hashes.
+func (v *Verifier) NeedsRehash(encoded string) (bool, error) {
+	if _, err := parse(encoded); err != nil {
+		return false, err
+	}
+
+	return true, nil
+}
+
+// params holds the decoded inputs for a single PBKDF2 computation.
+type params struct {
+	digest     func() hash.Hash
+	iterations int
+	salt       []byte
+	want       []byte
+}
+
+func parse(encoded string) (*params, error) {
+	if p, err := parsePHC(encoded); err == nil {
+		return p, nil
+	}
+
+	for prefix, name := range djangoPrefixes {
+		if strings.HasPrefix(encoded, prefix) {
+			return parseDjango(strings.TrimPrefix(encoded, prefix), name)
+		}
+	}
+
+	for prefix, name := range passlibPrefixes {
+		if strings.HasPrefix(encoded, prefix) {
+			return parsePasslib(strings.TrimPrefix(encoded, prefix), name)
+		}
+	}
+
+	switch {
+	case strings.HasPrefix(encoded, "pbkdf2:"):
+		return parseWerkzeug(strings.TrimPrefix(encoded, "pbkdf2:"))
+	case strings.HasPrefix(encoded, atlassianPrefix):
+		return parseAtlassian(strings.TrimPrefix(encoded, atlassianPrefix))
+	case strings.HasPrefix(encoded, ciscoPrefix):
+		return parseCisco(strings.TrimPrefix(encoded, ciscoPrefix))
+	}
+
+	return nil, errInvalidHash
+}
+
+// parseDjango handles pbkdf2_<digest>$<iterations>$<salt>$<base64 hash>, where
+// the salt is used verbatim.
+func parseDjango(rest, name string) (*params, error) {
+	parts := strings.Split(rest, "$")
+	if len(parts) != 3 {
+		return nil, errInvalidHash
+	}
+
+	iterations, err := strconv.Atoi(parts[0])
+	if err != nil {
+		return nil, err
+	}
+
+	want, err := base64.StdEncoding.DecodeString(parts[2])
+	if err != nil {
+		return nil, err
+	}
+
+	return &params{digest: digests[name], iterations: iterations, salt: []byte(parts[1]), want: want}, nil
+}
+
+// parsePasslib handles $pbkdf2[-<digest>]$<iterations>$<salt>$<hash>, with salt
+// and hash in passlib's adapted base64.
+func parsePasslib(rest, name string) (*params, error) {
+	parts := strings.Split(rest, "$")
+	if len(parts) != 3 {
+		return nil, errInvalidHash
+	}
+
+	iterations, err := strconv.Atoi(parts[0])
+	if err != nil {
+		return nil, err
+	}
+
+	salt, err := encoding.DecodeAdaptedBase64(parts[1])
+	if err != nil {
+		return nil, err
+	}
+
+	want, err := encoding.DecodeAdaptedBase64(parts[2])
+	if err != nil {
+		return nil, err
+	}
+
+	return &params{digest: digests[name], iterations: iterations, salt: salt, want: want}, nil
+}
+
+// parseWerkzeug handles pbkdf2:<digest>:<iterations>$<salt>$<hex hash>, where
+// the salt is used verbatim.
+func parseWerkzeug(rest string) (*params, error) {
+	parts := strings.Split(rest, "$")
+	if len(parts) != 3 {
+		return nil, errInvalidHash
+	}
+
+	name, iter, ok := strings.Cut(parts[0], ":")
+	if !ok {
+		return nil, errors.New("werkzeug pbkdf2 hash has no iteration count")
+	}
+
+	digest, ok := digests[name]
+	if !ok {
+		return nil, fmt.Errorf("unsupported pbkdf2 digest: %s", name)
+	}
+
+	iterations, err := strconv.Atoi(iter)
+	if err != nil {
+		return nil, err
+	}
+
+	want, err := hex.DecodeString(parts[2])
+	if err != nil {
+		return nil, err
+	}
+
+	return &params{digest: digest, iterations: iterations, salt: []byte(parts[1]), want: want}, nil
+}
+
+// parseAtlassian handles {PKCS5S2}<base64(16-byte salt || 32-byte key)> using
+// PBKDF2-HMAC-SHA1 with 10000 iterations.
+func parseAtlassian(rest string) (*params, error) {
+	raw, err := base64.StdEncoding.DecodeString(rest)
+	if err != nil {
+		return nil, err
+	}
+
+	if len(raw) != atlassianSaltLength+atlassianKeyLength {
+		return nil, errInvalidHash
+	}
+
+	return &params{
+		digest:     sha1.New,
+		iterations: atlassianIterations,
+		salt:       raw[:atlassianSaltLength],
+		want:       raw[atlassianSaltLength:],
+	}, nil
+}
+
+// parseCisco handles $8$<14-char salt>$<43-char hash> using PBKDF2-HMAC-SHA256
+// with 20000 iterations; the salt is used verbatim.
+func parseCisco(rest string) (*params, error) {
+	if len(rest) != ciscoSaltLength+1+ciscoHashLength || rest[ciscoSaltLength] != '$' {
+		return nil, errInvalidHash
+	}
+
+	want, err := encoding.CryptBase64.DecodeString(rest[ciscoSaltLength+1:])
+	if err != nil {
+		return nil, err
+	}
+
+	return &params{
+		digest:     sha256.New,
+		iterations: ciscoIterations,
+		salt:       []byte(rest[:ciscoSaltLength]),
+		want:       want,
+	}, nil
+}
@@ -0,0 +1,196 @@
+package pbkdf2
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestVerifier_Verify(t *testing.T) {
+	tests := []struct {
+		name     string
+		password string
+		encoded  string
+	}{
+		{
+			name:     "djangoSHA256",
+			password: "password",
+			encoded:  "pbkdf2_sha256$1000$seasalt$YIWkt6M1JFXrHg5s0jZjBSc7C2Cz6QvchSJ0h8Y+i7c=",
+		},
+		{
+			name:     "djangoSHA1",
+			password: "password",
+			encoded:  "pbkdf2_sha1$1000$seasalt$C8KvRfPW529R7JpDHEDOP35Xr0g=",
+		},
+		{
+			name:     "passlibSHA256",
+			password: "password",
+			encoded:  "$pbkdf2-sha256$2000$.vv8/f7/MDEyMzQ1Njc4OQ$.YR2Y.ERm7CczWi3vEh.re8S25oLKdvVTZTV/taQufo",
+		},
+		{
+			name:     "passlibSHA512",
+			password: "password",
+			encoded:  "$pbkdf2-sha512$2000$.vv8/f7/MDEyMzQ1Njc4OQ$CjOxWe5t71QeUGOmc93PwiYr7RSPYK7e4mg9FMGwXkDmsODZoS3c2w1BTC72FqxK8/k9lGusnTXt1csm9fw/tQ",
+		},
+		{
+			name:     "passlibSHA1",
+			password: "password",
+			encoded:  "$pbkdf2$2000$.vv8/f7/MDEyMzQ1Njc4OQ$3AhE02gJPbWAaPiFM8N7ivCmX54",
+		},
+		{
+			name:     "phcSHA256",
+			password: "password",
+			encoded:  "$pbkdf2-sha256$i=1000$AAECAwQFBgcICQoLDA0ODw$JeuGrMduQwGPGLmo+Qwv7UYtHHmeg9SK49fGkEamC2c",
+		},
+		{
+			name:     "phcSHA512",
+			password: "password",
+			encoded:  "$pbkdf2-sha512$i=1000$AAECAwQFBgcICQoLDA0ODw$x05AgND7tB/uWGjA/2D9dayuJjghWYfl/1T46uIRM5ta0a9uOHvBLdOnC7blqQEIFBxfCONToumEQ5pDM8Qtbg",
+		},
+		{
+			name:     "werkzeug",
+			password: "password",
+			encoded:  "pbkdf2:sha256:5000$Xy3kQz9a$c49d83487b8fec99c4f293f4dae6283e1df7d414af324b3582ac49dcf5b1cf83",
+		},
+		{
+			name:     "atlassian",
+			password: "password",
+			encoded:  "{PKCS5S2}AAECAwQFBgcICQoLDA0OD44+L3PD62OQqBq7yBAcA0OwF6ev//tatl4TTwkJ3Mos",
+		},
+		{
+			name:     "ciscoType8",
+			password: "hashcat",
+			encoded:  "$8$TnGX/fE4KGHOVU$pEhnEvxrvaynpi8j4f.EMHr6M.FzU8xnZnBr/tJdFWk",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			verifier := New()
+			require.True(t, verifier.Recognize(tt.encoded))
+
+			ok, err := verifier.Verify([]byte(tt.password), tt.encoded)
+			require.NoError(t, err)
+			require.True(t, ok)
+
+			ok, err = verifier.Verify([]byte("wrong"), tt.encoded)
+			require.NoError(t, err)
+			require.False(t, ok)
+
+			needs, err := verifier.NeedsRehash(tt.encoded)
+			require.NoError(t, err)
+			require.True(t, needs)
+		})
+	}
+}
+
+func TestVerifier_Errors(t *testing.T) {
+	tests := []struct {
+		name    string
+		encoded string
+	}{
+		{name: "unknownFormat", encoded: "$argon2id$v=19$m=65536,t=3,p=4$YWJj$ZGVm"},
+		{name: "djangoMissingParts", encoded: "pbkdf2_sha256$1000$seasalt"},
+		{name: "werkzeugWithoutIterations", encoded: "pbkdf2:sha256$salt$00"},
+		{name: "werkzeugUnknownDigest", encoded: "pbkdf2:md5:1000$salt$00"},
+		{name: "atlassianTruncated", encoded: "{PKCS5S2}AAECAwQFBgcICQoLDA0ODw"},
+		{name: "iterationCeiling", encoded: "pbkdf2_sha256$99999999$seasalt$YWJj"},
+		{name: "zeroIterations", encoded: "pbkdf2_sha256$0$seasalt$YWJj"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := New().Verify([]byte("password"), tt.encoded)
+			require.Error(t, err)
+		})
+	}
+}
+
+func newTestHasher() *Hasher {
+	return &Hasher{
+		Digest:     DigestSHA256,
+		Iterations: MinIterations,
+		SaltLength: MinSaltLength,
+		KeyLength:  32,
+	}
+}
+
+func TestHasher_HashAndVerify(t *testing.T) {
+	for _, digest := range []Digest{DigestSHA256, DigestSHA512} {
+		t.Run(string(digest), func(t *testing.T) {
+			hasher := newTestHasher()
+			hasher.Digest = digest
+
+			encoded, err := hasher.Hash([]byte("password"))
+			require.NoError(t, err)
+			require.Contains(t, encoded, "$pbkdf2-"+string(digest)+"$i=1000$")
+			require.True(t, hasher.Recognize(encoded))
+			require.True(t, New().Recognize(encoded))
+
+			ok, err := hasher.Verify([]byte("password"), encoded)
+			require.NoError(t, err)
+			require.True(t, ok)
+
+			ok, err = hasher.Verify([]byte("wrong"), encoded)
+			require.NoError(t, err)
+			require.False(t, ok)
+
+			needs, err := hasher.NeedsRehash(encoded)
+			require.NoError(t, err)
+			require.False(t, needs)
+		})
+	}
+}
+
+func TestHasher_NeedsRehash(t *testing.T) {
+	encoded, err := newTestHasher().Hash([]byte("password"))
+	require.NoError(t, err)
+
+	tests := []struct {
+		name   string
+		mutate func(h *Hasher)
+	}{
+		{name: "digest", mutate: func(h *Hasher) { h.Digest = DigestSHA512 }},
+		{name: "iterations", mutate: func(h *Hasher) { h.Iterations = 2 * MinIterations }},
+		{name: "saltLength", mutate: func(h *Hasher) { h.SaltLength = 32 }},
+		{name: "keyLength", mutate: func(h *Hasher) { h.KeyLength = 64 }},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			hasher := newTestHasher()
+			tt.mutate(hasher)
+
+			needs, err := hasher.NeedsRehash(encoded)
+			require.NoError(t, err)
+			require.True(t, needs)
+		})
+	}
+
+	_, err = newTestHasher().NeedsRehash("pbkdf2_sha256$1000$seasalt$YWJj")
+	require.Error(t, err)
+}
+
+func TestHasher_Validate(t *testing.T) {
+	tests := []struct {
+		name   string
+		mutate func(h *Hasher)
+	}{
+		{name: "unknownDigest", mutate: func(h *Hasher) { h.Digest = "sha1" }},
+		{name: "iterationsTooLow", mutate: func(h *Hasher) { h.Iterations = MinIterations - 1 }},
+		{name: "iterationsTooHigh", mutate: func(h *Hasher) { h.Iterations = MaxIterations + 1 }},
+		{name: "saltTooShort", mutate: func(h *Hasher) { h.SaltLength = MinSaltLength - 1 }},
+		{name: "keyTooShort", mutate: func(h *Hasher) { h.KeyLength = MinKeyLength - 1 }},
+		{name: "keyTooLong", mutate: func(h *Hasher) { h.KeyLength = MaxKeyLength + 1 }},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			hasher := newTestHasher()
+			tt.mutate(hasher)
+
+			_, err := hasher.Hash([]byte("password"))
+			require.Error(t, err)
+		})
+	}
+}
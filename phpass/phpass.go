@@ -0,0 +1,151 @@
+// Package phpass provides a verify-only adapter for migrating phpass hashes.
+//
+// It understands the portable $P$ hashes written by WordPress, the $H$ variant
+// used by phpBB, and Drupal 7's SHA-512 $S$ hashes, including the U$ prefix
+// Drupal applies to passwords carried over from Drupal 6. Portable hashes are
+// iterated MD5, which is why NeedsRehash always asks the current hasher to
+// replace them.
+package phpass
+
+import (
+	"crypto/md5" // #nosec G501 -- required to verify legacy phpass hashes
+	"crypto/sha512"
+	"encoding/hex"
+	"errors"
+	"hash"
+	"strings"
+
+	"github.com/allisson/go-pwdhash/internal/encoding"
+	"github.com/allisson/go-pwdhash/internal/subtle"
+	"github.com/allisson/go-pwdhash/internal/zero"
+)
+
+const (
+	settingLength = 12
+	saltLength    = 8
+	minCountLog2  = 7
+
+	// maxCountLog2 caps a single verification at 2^24 rounds, far above the
+	// 2^11 to 2^15 that phpBB, WordPress, and Drupal write by default.
+	maxCountLog2 = 24
+
+	// drupalLength is the stored length of Drupal hashes, which truncate the
+	// encoded SHA-512 digest.
+	drupalLength = 55
+
+	// drupalUpgradedPrefix marks Drupal 6 MD5 hashes rehashed by Drupal 7.
+	drupalUpgradedPrefix = "U"
+)
+
+const itoa64 = "./0123456789ABCDEFGHIJKLMNOPQRSTUVWXYZabcdefghijklmnopqrstuvwxyz"
+
+var errInvalidHash = errors.New("invalid phpass hash")
+
+// Verifier checks phpass $P$, $H$, and Drupal 7 $S$ hashes.
+type Verifier struct{}
+
+// New returns a phpass Verifier.
+func New() *Verifier {
+	return &Verifier{}
+}
+
+// ID reports the scheme identifier.
+func (v *Verifier) ID() string {
+	return "phpass"
+}
+
+// Recognize reports whether encoded is in one of the supported phpass formats.
+func (v *Verifier) Recognize(encoded string) bool {
+	_, err := parse(encoded)
+	return err == nil
+}
+
+// Verify recomputes the iterated digest and compares it in constant time.
+func (v *Verifier) Verify(password []byte, encoded string) (bool, error) {
+	defer zero.Bytes(password)
+
+	p, err := parse(encoded)
+	if err != nil {
+		return false, err
+	}
+
+	input := password
+	if p.upgraded {
+		sum := md5.Sum(password) // #nosec G401 -- Drupal 6 legacy input
+		input = []byte(hex.EncodeToString(sum[:]))
+		defer zero.Bytes(input)
+	}
+
+	h := p.newHash()
+	h.Write(p.salt)
+	h.Write(input)
+	digest := h.Sum(nil)
+	defer zero.Bytes(digest)
+
+	for range 1 << p.countLog2 {
+		h.Reset()
+		h.Write(digest)
+		h.Write(input)
+		digest = h.Sum(digest[:0])
+	}
+
+	got := encoding.EncodeHash64(digest)[:len(p.want)]
+
+	return subtle.ConstantTimeCompare([]byte(got), []byte(p.want)), nil
+}
+
+// NeedsRehash always reports true for phpass hashes.
+func (v *Verifier) NeedsRehash(encoded string) (bool, error) {
+	if _, err := parse(encoded); err != nil {
+		return false, err
+	}
+
+	return true, nil
+}
+
+// params holds the decoded inputs for a single phpass computation.
+type params struct {
+	newHash   func() hash.Hash
+	countLog2 int
+	salt      []byte
+	want      string
+	upgraded  bool
+}
+
+// parse handles <marker><count char><salt:8><encoded digest>, where the count
+// character is the base-2 logarithm of the iteration count.
+func parse(encoded string) (*params, error) {
+	p := &params{}
+	if strings.HasPrefix(encoded, drupalUpgradedPrefix+"$") {
+		p.upgraded = true
+		encoded = strings.TrimPrefix(encoded, drupalUpgradedPrefix)
+	}
+
+	if len(encoded) < settingLength {
+		return nil, errInvalidHash
+	}
+
+	wantLength := 0
+	switch encoded[:3] {
+	case "$P$", "$H$":
+		p.newHash, wantLength = md5.New, settingLength+22
+	case "$S$":
+		p.newHash, wantLength = sha512.New, drupalLength
+	default:
+		return nil, errInvalidHash
+	}
+
+	if len(encoded) != wantLength {
+		return nil, errInvalidHash
+	}
+
+	p.countLog2 = strings.IndexByte(itoa64, encoded[3])
+	if p.countLog2 < minCountLog2 || p.countLog2 > maxCountLog2 {
+		return nil, errInvalidHash
+	}
+
+	p.salt = []byte(encoded[4 : 4+saltLength])
+	p.want = encoded[settingLength:]
+
+	return p, nil
+}
@@ -0,0 +1,77 @@
+package phpass
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestVerifier_Verify(t *testing.T) {
+	tests := []struct {
+		name     string
+		password string
+		encoded  string
+	}{
+		{
+			name:     "wordpress",
+			password: "hashcat",
+			encoded:  "$P$984478476IagS59wHZvyQMArzfx58u.",
+		},
+		{
+			name:     "phpBB",
+			password: "password",
+			encoded:  "$H$9saltsaltTPYWOFleH9nxJ26A2VSHl1",
+		},
+		{
+			name:     "drupal7",
+			password: "hashcat",
+			encoded:  "$S$C33783772bRXEx1aCsvY.dqgaaSu76XmVlKrW9Qu8IQlvxHlmzLf",
+		},
+		{
+			name:     "drupal7Upgraded",
+			password: "password",
+			encoded:  "U$S$DsaltsaltwUZJPmieKbS5yEXIuMbHoS81BKBMexqWjbwzQLUxKqx",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			verifier := New()
+			require.True(t, verifier.Recognize(tt.encoded))
+
+			ok, err := verifier.Verify([]byte(tt.password), tt.encoded)
+			require.NoError(t, err)
+			require.True(t, ok)
+
+			ok, err = verifier.Verify([]byte("wrong"), tt.encoded)
+			require.NoError(t, err)
+			require.False(t, ok)
+
+			needs, err := verifier.NeedsRehash(tt.encoded)
+			require.NoError(t, err)
+			require.True(t, needs)
+		})
+	}
+}
+
+func TestVerifier_Errors(t *testing.T) {
+	tests := []struct {
+		name    string
+		encoded string
+	}{
+		{name: "unknownMarker", encoded: "$Q$984478476IagS59wHZvyQMArzfx58u."},
+		{name: "truncated", encoded: "$P$984478476IagS59wHZvyQ"},
+		{name: "countTooLow", encoded: "$P$484478476IagS59wHZvyQMArzfx58u."},
+		{name: "countTooHigh", encoded: "$P$Z84478476IagS59wHZvyQMArzfx58u."},
+		{name: "countAboveCap", encoded: "$P$N84478476IagS59wHZvyQMArzfx58u."},
+		{name: "drupalCountAboveCap", encoded: "$S$S33783772bRXEx1aCsvY.dqgaaSu76XmVlKrW9Qu8IQlvxHlmzLf"},
+		{name: "drupalFullDigest", encoded: "$S$C33783772bRXEx1aCsvY.dqgaaSu76XmVlKrW9Qu8IQlvxHlmzLfAAAA"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := New().Verify([]byte("password"), tt.encoded)
+			require.Error(t, err)
+		})
+	}
+}
@@ -0,0 +1,37 @@
+package policy
+
+// commonPasswords is a short built-in blocklist of the most frequently
+// breached passwords that pass the default length check. Pair it with a
+// larger list through WithBlocklist.
+var commonPasswords = []string{
+	"password",
+	"password1",
+	"password123",
+	"passw0rd",
+	"12345678",
+	"123456789",
+	"1234567890",
+	"87654321",
+	"11111111",
+	"00000000",
+	"qwertyuiop",
+	"qwerty123",
+	"1q2w3e4r",
+	"1qaz2wsx",
+	"zaq12wsx",
+	"iloveyou",
+	"sunshine",
+	"princess",
+	"football",
+	"baseball",
+	"superman",
+	"trustno1",
+	"welcome1",
+	"letmein1",
+	"abc12345",
+	"starwars",
+	"whatever",
+	"changeme",
+	"computer",
+	"michelle",
+}
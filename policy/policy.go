@@ -0,0 +1,301 @@
+// Package policy decides whether a new password is acceptable, following the
+// NIST SP 800-63B guidance on memorized secrets.
+//
+// A Validator checks the length in Unicode code points, rejects blocklisted
+// and context-specific passwords, and rejects repetitive or sequential
+// patterns. It imposes no composition rules. Every violation is reported as a
+// stable Code so applications can render their own localized messages.
+package policy
+
+import (
+	"fmt"
+	"strings"
+	"unicode"
+	"unicode/utf8"
+)
+
+// Code identifies a policy violation. Codes are stable and suitable as
+// translation keys.
+type Code string
+
+const (
+	CodeInvalidUTF8 Code = "invalid_utf8"
+	CodeTooShort    Code = "too_short"
+	CodeTooLong     Code = "too_long"
+	CodeBlocklisted Code = "blocklisted"
+	CodeContextWord Code = "context_word"
+	CodeRepetitive  Code = "repetitive"
+	CodeSequential  Code = "sequential"
+)
+
+// Violation is a single reason a password was rejected. Limit carries the
+// bound for CodeTooShort and CodeTooLong and is zero otherwise.
+type Violation struct {
+	Code  Code
+	Limit int
+}
+
+// String returns an English description of the violation.
+func (v Violation) String() string {
+	switch v.Code {
+	case CodeInvalidUTF8:
+		return "password is not valid UTF-8"
+	case CodeTooShort:
+		return fmt.Sprintf("password must be at least %d characters", v.Limit)
+	case CodeTooLong:
+		return fmt.Sprintf("password must be at most %d characters", v.Limit)
+	case CodeBlocklisted:
+		return "password is too common"
+	case CodeContextWord:
+		return "password must not contain your name, email, or the service name"
+	case CodeRepetitive:
+		return "password must not repeat characters or patterns"
+	case CodeSequential:
+		return "password must not contain sequences such as 1234 or abcd"
+	}
+
+	return string(v.Code)
+}
+
+// Error lists every violation found by Validate.
+type Error struct {
+	Violations []Violation
+}
+
+// Error implements error with the English descriptions joined by semicolons.
+func (e *Error) Error() string {
+	msgs := make([]string, 0, len(e.Violations))
+	for _, v := range e.Violations {
+		msgs = append(msgs, v.String())
+	}
+
+	return "unacceptable password: " + strings.Join(msgs, "; ")
+}
+
+// Has reports whether the error includes a violation with code.
+func (e *Error) Has(code Code) bool {
+	for _, v := range e.Violations {
+		if v.Code == code {
+			return true
+		}
+	}
+
+	return false
+}
+
+// Validator checks new passwords against a configurable NIST SP 800-63B policy.
+type Validator struct {
+	minLength   int
+	maxLength   int
+	blocklist   map[string]struct{}
+	context     []string
+	maxRepeat   int
+	maxSequence int
+}
+
+// Option configures a Validator.
+type Option func(*Validator)
+
+// WithLength sets the minimum and maximum length in code points.
+func WithLength(minLength, maxLength int) Option {
+	return func(v *Validator) {
+		v.minLength = minLength
+		v.maxLength = maxLength
+	}
+}
+
+// WithBlocklist adds passwords that are rejected outright, compared case-insensitively.
+func WithBlocklist(words ...string) Option {
+	return func(v *Validator) {
+		for _, w := range words {
+			v.blocklist[fold(w)] = struct{}{}
+		}
+	}
+}
+
+// WithContextWords adds words that no password may contain, such as the
+// service name. Per-user words are passed to ValidateFor instead.
+func WithContextWords(words ...string) Option {
+	return func(v *Validator) {
+		v.context = append(v.context, contextTokens(words)...)
+	}
+}
+
+// WithMaxRepeat sets the longest allowed run of one repeated character; zero disables the check.
+func WithMaxRepeat(n int) Option {
+	return func(v *Validator) {
+		v.maxRepeat = n
+	}
+}
+
+// WithMaxSequence sets the longest allowed run of consecutive characters,
+// ascending or descending; zero disables the check.
+func WithMaxSequence(n int) Option {
+	return func(v *Validator) {
+		v.maxSequence = n
+	}
+}
+
+// New returns a Validator requiring 8 to 64 code points, rejecting the
+// built-in list of common passwords, runs of more than 3 identical
+// characters, and sequences longer than 3. SP 800-63B asks for 15 code points
+// when the password is the only authentication factor; use WithLength for that.
+func New(opts ...Option) *Validator {
+	v := &Validator{
+		minLength:   8,
+		maxLength:   64,
+		blocklist:   make(map[string]struct{}, len(commonPasswords)),
+		maxRepeat:   3,
+		maxSequence: 3,
+	}
+
+	for _, w := range commonPasswords {
+		v.blocklist[w] = struct{}{}
+	}
+
+	for _, opt := range opts {
+		opt(v)
+	}
+
+	return v
+}
+
+// Validate checks password against the policy and returns an *Error listing
+// every violation, or nil.
+func (v *Validator) Validate(password []byte) error {
+	return v.ValidateFor(password)
+}
+
+// ValidateFor is Validate with additional context words for this password,
+// such as the username and email address. Each word is also split on
+// punctuation, so an email address contributes its local part and domain labels.
+func (v *Validator) ValidateFor(password []byte, words ...string) error {
+	if !utf8.Valid(password) {
+		return &Error{Violations: []Violation{{Code: CodeInvalidUTF8}}}
+	}
+
+	var violations []Violation
+	runes := []rune(string(password))
+
+	if len(runes) < v.minLength {
+		violations = append(violations, Violation{Code: CodeTooShort, Limit: v.minLength})
+	}
+	if v.maxLength > 0 && len(runes) > v.maxLength {
+		violations = append(violations, Violation{Code: CodeTooLong, Limit: v.maxLength})
+	}
+
+	folded := fold(string(password))
+
+	if _, ok := v.blocklist[folded]; ok {
+		violations = append(violations, Violation{Code: CodeBlocklisted})
+	}
+
+	for _, w := range append(contextTokens(words), v.context...) {
+		if strings.Contains(folded, w) {
+			violations = append(violations, Violation{Code: CodeContextWord})
+			break
+		}
+	}
+
+	if v.maxRepeat > 0 && (longestRepeat(runes) > v.maxRepeat || isRepeatedPattern(runes)) {
+		violations = append(violations, Violation{Code: CodeRepetitive})
+	}
+
+	if v.maxSequence > 0 && longestSequence([]rune(folded)) > v.maxSequence {
+		violations = append(violations, Violation{Code: CodeSequential})
+	}
+
+	if len(violations) == 0 {
+		return nil
+	}
+
+	return &Error{Violations: violations}
+}
+
+// fold lowercases s for case-insensitive comparisons.
+func fold(s string) string {
+	return strings.ToLower(strings.TrimSpace(s))
+}
+
+// contextTokens folds each word and splits it on anything other than letters
+// and digits, keeping the pieces of at least 3 code points.
+func contextTokens(words []string) []string {
+	var tokens []string
+	for _, w := range words {
+		w = fold(w)
+		if utf8.RuneCountInString(w) >= 3 {
+			tokens = append(tokens, w)
+		}
+
+		parts := strings.FieldsFunc(w, func(r rune) bool {
+			return !unicode.IsLetter(r) && !unicode.IsDigit(r)
+		})
+		for _, p := range parts {
+			if p != w && utf8.RuneCountInString(p) >= 3 {
+				tokens = append(tokens, p)
+			}
+		}
+	}
+
+	return tokens
+}
+
+// longestRepeat returns the length of the longest run of one code point.
+func longestRepeat(runes []rune) int {
+	longest, run := 0, 0
+	for i, r := range runes {
+		if i > 0 && r == runes[i-1] {
+			run++
+		} else {
+			run = 1
+		}
+		longest = max(longest, run)
+	}
+
+	return longest
+}
+
+// isRepeatedPattern reports whether runes consist entirely of one shorter
+// pattern repeated, such as "abcabcabc".
+func isRepeatedPattern(runes []rune) bool {
+	n := len(runes)
+	for period := 2; period <= n/2; period++ {
+		if n%period != 0 {
+			continue
+		}
+
+		repeated := true
+		for i := period; i < n; i++ {
+			if runes[i] != runes[i-period] {
+				repeated = false
+				break
+			}
+		}
+		if repeated {
+			return true
+		}
+	}
+
+	return false
+}
+
+// longestSequence returns the length of the longest run of code points that
+// each differ from the previous one by +1 or -1, such as "1234" or "dcba".
+func longestSequence(runes []rune) int {
+	longest := min(len(runes), 1)
+	run, step := 1, rune(0)
+	for i := 1; i < len(runes); i++ {
+		d := runes[i] - runes[i-1]
+		switch {
+		case (d == 1 || d == -1) && d == step:
+			run++
+		case d == 1 || d == -1:
+			run, step = 2, d
+		default:
+			run, step = 1, 0
+		}
+		longest = max(longest, run)
+	}
+
+	return longest
+}
@@ -0,0 +1,99 @@
+package policy
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestValidator_Accepts(t *testing.T) {
+	v := New(WithContextWords("ExampleCorp"))
+
+	for _, password := range []string{
+		"correct horse battery staple",
+		"Tr0ub4dor&3x",
+		"pässwörter-im-käfig",
+		"日本語のパスワードです",
+	} {
+		require.NoError(t, v.ValidateFor([]byte(password), "alice", "alice@mail.test"), password)
+	}
+}
+
+func TestValidator_Violations(t *testing.T) {
+	tests := []struct {
+		name     string
+		password string
+		words    []string
+		want     []Violation
+	}{
+		{name: "tooShort", password: "k7#q", want: []Violation{{Code: CodeTooShort, Limit: 8}}},
+		{name: "codePoints", password: "日本語の", want: []Violation{{Code: CodeTooShort, Limit: 8}}},
+		{name: "tooLong", password: longPassword(65), want: []Violation{{Code: CodeTooLong, Limit: 64}}},
+		{name: "blocklisted", password: "Sunshine", want: []Violation{{Code: CodeBlocklisted}}},
+		{name: "username", password: "xx-Alice-2024!", words: []string{"alice"}, want: []Violation{{Code: CodeContextWord}}},
+		{name: "emailLocalPart", password: "bob.smith rocks", words: []string{"bob.smith@mail.test"}, want: []Violation{{Code: CodeContextWord}}},
+		{name: "serviceName", password: "i-love-examplecorp", want: []Violation{{Code: CodeContextWord}}},
+		{name: "repeatedCharacter", password: "zzzzqx7#kp", want: []Violation{{Code: CodeRepetitive}}},
+		{name: "repeatedPattern", password: "k7#k7#k7#", want: []Violation{{Code: CodeRepetitive}}},
+		{name: "ascending", password: "x1234-qz#p", want: []Violation{{Code: CodeSequential}}},
+		{name: "descending", password: "xq#DCBA-pz", want: []Violation{{Code: CodeSequential}}},
+		{
+			name:     "several",
+			password: "aaaa",
+			want: []Violation{
+				{Code: CodeTooShort, Limit: 8},
+				{Code: CodeRepetitive},
+			},
+		},
+		{name: "invalidUTF8", password: "\xff\xfe\xfd\xfc\xfb\xfa\xf9\xf8", want: []Violation{{Code: CodeInvalidUTF8}}},
+	}
+
+	v := New(WithContextWords("ExampleCorp"))
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := v.ValidateFor([]byte(tt.password), tt.words...)
+
+			var perr *Error
+			require.True(t, errors.As(err, &perr))
+			require.Equal(t, tt.want, perr.Violations)
+			require.True(t, perr.Has(tt.want[0].Code))
+		})
+	}
+}
+
+func TestValidator_Options(t *testing.T) {
+	v := New(
+		WithLength(15, 0),
+		WithBlocklist("Hunter2-Hunter2-x"),
+		WithMaxRepeat(0),
+		WithMaxSequence(0),
+	)
+
+	require.NoError(t, v.Validate([]byte("aaaaaaaa-12345678")))
+	require.NoError(t, v.Validate([]byte(longPassword(200))))
+
+	err := v.Validate([]byte("only-14-chars!"))
+	require.EqualError(t, err, "unacceptable password: password must be at least 15 characters")
+
+	var perr *Error
+	require.True(t, errors.As(v.Validate([]byte("hunter2-hunter2-X")), &perr))
+	require.True(t, perr.Has(CodeBlocklisted))
+}
+
+func TestViolation_String(t *testing.T) {
+	require.Equal(t, "password must be at most 64 characters", Violation{Code: CodeTooLong, Limit: 64}.String())
+	require.Equal(t, "custom", Violation{Code: "custom"}.String())
+}
+
+// longPassword returns n code points without repeats or sequences.
+func longPassword(n int) string {
+	const pattern = "q7#Kp2!xZ9"
+	out := make([]byte, 0, n)
+	for i := 0; len(out) < n; i++ {
+		out = append(out, pattern[i%len(pattern)])
+	}
+	out[n-1] = '@'
+	return string(out)
+}
@@ -0,0 +1,5 @@
+package postgres
+
+const (
+	MaxIterations = 10_000_000
+)
@@ -0,0 +1,229 @@
+// Package postgres provides a verify-only adapter for migrating PostgreSQL role passwords.
+//
+// It understands the SCRAM-SHA-256 verifiers and md5 hashes stored in
+// pg_authid.rolpassword. md5 values are salted with nothing but the role name,
+// and NeedsRehash flags either kind for replacement by the current hasher.
+package postgres
+
+import (
+	"crypto/hmac"
+	"crypto/md5" // #nosec G501 -- required to verify legacy PostgreSQL md5 hashes
+	"crypto/pbkdf2"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+
+	"github.com/allisson/go-pwdhash/internal/subtle"
+	"github.com/allisson/go-pwdhash/internal/zero"
+)
+
+const (
+	scramPrefix  = "SCRAM-SHA-256$"
+	md5Prefix    = "md5"
+	md5Length    = len(md5Prefix) + 2*md5.Size
+	rolePrefix   = "$postgres-md5$"
+	clientKeyMsg = "Client Key"
+	serverKeyMsg = "Server Key"
+)
+
+var (
+	errInvalidHash = errors.New("invalid postgres hash")
+	errMissingRole = errors.New("postgres md5 hashes need the role name; store them with MD5Hash")
+)
+
+// Verifier checks PostgreSQL SCRAM-SHA-256 verifiers and md5 hashes.
+//
+// Passwords are used as given; PostgreSQL applies SASLprep to non-ASCII SCRAM
+// passwords, so such accounts only verify if the input is already normalized.
+type Verifier struct{}
+
+// New returns a PostgreSQL Verifier.
+func New() *Verifier {
+	return &Verifier{}
+}
+
+// MD5Hash pairs an md5 rolpassword with its role name, which PostgreSQL uses
+// as the salt, encoding them as $postgres-md5$<base64 role>$<hex digest>.
+func MD5Hash(rolpassword, role string) string {
+	return rolePrefix + base64.RawStdEncoding.EncodeToString([]byte(role)) + "$" + strings.TrimPrefix(rolpassword, md5Prefix)
+}
+
+// ID reports the scheme identifier.
+func (v *Verifier) ID() string {
+	return "postgres"
+}
+
+// Recognize reports whether encoded is in one of the supported PostgreSQL
+// formats, including bare md5 hashes that Verify rejects for lack of a role.
+func (v *Verifier) Recognize(encoded string) bool {
+	switch {
+	case strings.HasPrefix(encoded, scramPrefix):
+		_, err := parseSCRAM(encoded)
+		return err == nil
+	case strings.HasPrefix(encoded, rolePrefix):
+		_, _, err := parseMD5(encoded)
+		return err == nil
+	}
+
+	return isBareMD5(encoded)
+}
+
+// Verify recomputes the stored keys or digest and compares them in constant time.
+func (v *Verifier) Verify(password []byte, encoded string) (bool, error) {
+	defer zero.Bytes(password)
+
+	switch {
+	case strings.HasPrefix(encoded, scramPrefix):
+		p, err := parseSCRAM(encoded)
+		if err != nil {
+			return false, err
+		}
+
+		return verifySCRAM(password, p)
+	case strings.HasPrefix(encoded, rolePrefix):
+		role, want, err := parseMD5(encoded)
+		if err != nil {
+			return false, err
+		}
+
+		h := md5.New() // #nosec G401 -- legacy verification only
+		h.Write(password)
+		h.Write(role)
+		got := h.Sum(nil)
+		defer zero.Bytes(got)
+
+		return subtle.ConstantTimeCompare(got, want), nil
+	case isBareMD5(encoded):
+		return false, errMissingRole
+	}
+
+	return false, errInvalidHash
+}
+
+// NeedsRehash always reports true for PostgreSQL hashes.
+func (v *Verifier) NeedsRehash(encoded string) (bool, error) {
+	if !v.Recognize(encoded) {
+		return false, errInvalidHash
+	}
+
+	return true, nil
+}
+
+// scramParams holds the decoded fields of a SCRAM-SHA-256 verifier.
+type scramParams struct {
+	iterations int
+	salt       []byte
+	storedKey  []byte
+	serverKey  []byte
+}
+
+// parseSCRAM handles SCRAM-SHA-256$<iterations>:<salt>$<StoredKey>:<ServerKey>
+// with standard base64 salt and keys.
+func parseSCRAM(encoded string) (*scramParams, error) {
+	cost, keys, ok := strings.Cut(strings.TrimPrefix(encoded, scramPrefix), "$")
+	if !ok {
+		return nil, errInvalidHash
+	}
+
+	iterations, salt, ok := strings.Cut(cost, ":")
+	if !ok {
+		return nil, errInvalidHash
+	}
+
+	storedKey, serverKey, ok := strings.Cut(keys, ":")
+	if !ok {
+		return nil, errInvalidHash
+	}
+
+	p := &scramParams{}
+
+	count, err := strconv.Atoi(iterations)
+	if err != nil || count < 1 {
+		return nil, errInvalidHash
+	}
+	p.iterations = count
+
+	for _, field := range []struct {
+		dst   *[]byte
+		value string
+	}{
+		{&p.salt, salt},
+		{&p.storedKey, storedKey},
+		{&p.serverKey, serverKey},
+	} {
+		if *field.dst, err = base64.StdEncoding.DecodeString(field.value); err != nil {
+			return nil, errInvalidHash
+		}
+	}
+
+	if len(p.storedKey) != sha256.Size || len(p.serverKey) != sha256.Size {
+		return nil, errInvalidHash
+	}
+
+	return p, nil
+}
+
+// verifySCRAM derives SaltedPassword as in RFC 5802 and checks both the
+// StoredKey and the ServerKey.
+func verifySCRAM(password []byte, p *scramParams) (bool, error) {
+	if p.iterations > MaxIterations {
+		return false, fmt.Errorf("postgres iterations too high")
+	}
+
+	salted, err := pbkdf2.Key(sha256.New, string(password), p.salt, p.iterations, sha256.Size)
+	if err != nil {
+		return false, err
+	}
+
+	defer zero.Bytes(salted)
+
+	mac := hmac.New(sha256.New, salted)
+	mac.Write([]byte(clientKeyMsg))
+	clientKey := mac.Sum(nil)
+	defer zero.Bytes(clientKey)
+
+	storedKey := sha256.Sum256(clientKey)
+
+	mac = hmac.New(sha256.New, salted)
+	mac.Write([]byte(serverKeyMsg))
+	serverKey := mac.Sum(nil)
+
+	storedOK := subtle.ConstantTimeCompare(storedKey[:], p.storedKey)
+	serverOK := subtle.ConstantTimeCompare(serverKey, p.serverKey)
+
+	return storedOK && serverOK, nil
+}
+
+// parseMD5 handles the $postgres-md5$<base64 role>$<hex digest> form built by MD5Hash.
+func parseMD5(encoded string) ([]byte, []byte, error) {
+	encodedRole, digest, ok := strings.Cut(strings.TrimPrefix(encoded, rolePrefix), "$")
+	if !ok {
+		return nil, nil, errInvalidHash
+	}
+
+	role, err := base64.RawStdEncoding.DecodeString(encodedRole)
+	if err != nil || len(role) == 0 {
+		return nil, nil, errInvalidHash
+	}
+
+	want, err := hex.DecodeString(digest)
+	if err != nil || len(want) != md5.Size {
+		return nil, nil, errInvalidHash
+	}
+
+	return role, want, nil
+}
+
+// isBareMD5 reports whether encoded is an md5 rolpassword without its role.
+func isBareMD5(encoded string) bool {
+	if len(encoded) != md5Length || !strings.HasPrefix(encoded, md5Prefix) {
+		return false
+	}
+
+	_, err := hex.DecodeString(encoded[len(md5Prefix):])
+	return err == nil
+}
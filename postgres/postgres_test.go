@@ -0,0 +1,77 @@
+package postgres
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestVerifier_Verify(t *testing.T) {
+	tests := []struct {
+		name    string
+		encoded string
+	}{
+		{
+			name:    "scramSHA256",
+			encoded: "SCRAM-SHA-256$4096:MDEyMzQ1Njc4OWFiY2RlZg==$wjGCKoCIcEWiPxSG7t/wnb/YICMEFr1JZNZSKNje12g=:6NG/vkzOjK2oyl11qeNEBeKuOY3QQ4atswXYbIBO79Q=",
+		},
+		{
+			name:    "md5WithRole",
+			encoded: MD5Hash("md532e12f215ba27cb750c9e093ce4b5127", "postgres"),
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			verifier := New()
+			require.True(t, verifier.Recognize(tt.encoded))
+
+			ok, err := verifier.Verify([]byte("password"), tt.encoded)
+			require.NoError(t, err)
+			require.True(t, ok)
+
+			ok, err = verifier.Verify([]byte("wrong"), tt.encoded)
+			require.NoError(t, err)
+			require.False(t, ok)
+
+			needs, err := verifier.NeedsRehash(tt.encoded)
+			require.NoError(t, err)
+			require.True(t, needs)
+		})
+	}
+}
+
+func TestMD5Hash(t *testing.T) {
+	require.Equal(t,
+		"$postgres-md5$cG9zdGdyZXM$32e12f215ba27cb750c9e093ce4b5127",
+		MD5Hash("md532e12f215ba27cb750c9e093ce4b5127", "postgres"),
+	)
+
+	ok, err := New().Verify([]byte("password"), MD5Hash("md532e12f215ba27cb750c9e093ce4b5127", "someone-else"))
+	require.NoError(t, err)
+	require.False(t, ok)
+}
+
+func TestVerifier_Errors(t *testing.T) {
+	tests := []struct {
+		name    string
+		encoded string
+		wantErr error
+	}{
+		{name: "bareMD5", encoded: "md532e12f215ba27cb750c9e093ce4b5127", wantErr: errMissingRole},
+		{name: "unknownFormat", encoded: "*2470C0C06DEE42FD1618BB99005ADCA2EC9D1E19", wantErr: errInvalidHash},
+		{name: "scramMissingServerKey", encoded: "SCRAM-SHA-256$4096:MDEyMzQ1Njc4OWFiY2RlZg==$wjGCKoCIcEWiPxSG7t/wnb/YICMEFr1JZNZSKNje12g=", wantErr: errInvalidHash},
+		{name: "scramBadIterations", encoded: "SCRAM-SHA-256$0:MDEyMzQ1Njc4OWFiY2RlZg==$wjGCKoCIcEWiPxSG7t/wnb/YICMEFr1JZNZSKNje12g=:6NG/vkzOjK2oyl11qeNEBeKuOY3QQ4atswXYbIBO79Q=", wantErr: errInvalidHash},
+		{name: "md5BadDigest", encoded: "$postgres-md5$cG9zdGdyZXM$zz", wantErr: errInvalidHash},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := New().Verify([]byte("password"), tt.encoded)
+			require.ErrorIs(t, err, tt.wantErr)
+		})
+	}
+
+	_, err := New().Verify([]byte("password"), "SCRAM-SHA-256$99999999:MDEyMzQ1Njc4OWFiY2RlZg==$wjGCKoCIcEWiPxSG7t/wnb/YICMEFr1JZNZSKNje12g=:6NG/vkzOjK2oyl11qeNEBeKuOY3QQ4atswXYbIBO79Q=")
+	require.Error(t, err)
+}
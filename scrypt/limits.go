@@ -0,0 +1,9 @@
+package scrypt
+
+const (
+	MaxLogN   = 20
+	MaxR      = 32
+	MaxP      = 16
+	MaxMemory = 1024 * 1024 * 1024
+	MaxKeyLen = 128
+)
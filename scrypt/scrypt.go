@@ -0,0 +1,264 @@
+// Package scrypt provides a verify-only adapter for migrating scrypt hashes.
+//
+// It understands PHC and passlib $scrypt$ strings, Firebase Authentication
+// exports, and Cisco type 9 secrets. NeedsRehash reports true for all of
+// them, so the signer key given to WithFirebase can be retired once every
+// exported account has logged in again under the current hasher.
+package scrypt
+
+import (
+	"crypto/aes"
+	"crypto/cipher"
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+
+	xscrypt "golang.org/x/crypto/scrypt"
+
+	"github.com/allisson/go-pwdhash/internal/encoding"
+	"github.com/allisson/go-pwdhash/internal/subtle"
+	"github.com/allisson/go-pwdhash/internal/zero"
+)
+
+const (
+	phcPrefix      = "$scrypt$"
+	firebasePrefix = "$firebase-scrypt$"
+	ciscoPrefix    = "$9$"
+)
+
+// Cisco type 9 secrets use fixed scrypt parameters and a 14-character salt.
+const (
+	ciscoLogN       = 14
+	ciscoSaltLength = 14
+	ciscoHashLength = 43
+)
+
+var errInvalidHash = errors.New("invalid scrypt hash")
+
+// FirebaseParams holds the project-wide password hash parameters shown in the
+// Firebase console next to a user export.
+type FirebaseParams struct {
+	SignerKey     []byte
+	SaltSeparator []byte
+	Rounds        int
+	MemCost       int
+}
+
+// Option configures a Verifier.
+type Option func(*Verifier)
+
+// WithFirebase enables verification of Firebase Authentication exports.
+func WithFirebase(params FirebaseParams) Option {
+	return func(v *Verifier) {
+		v.firebase = &params
+	}
+}
+
+// Verifier checks scrypt hashes in the PHC, passlib, Firebase, and Cisco type 9 formats.
+type Verifier struct {
+	firebase *FirebaseParams
+}
+
+// New returns a scrypt Verifier configured via the provided options.
+func New(opts ...Option) *Verifier {
+	v := &Verifier{}
+	for _, opt := range opts {
+		opt(v)
+	}
+
+	return v
+}
+
+// FirebaseHash encodes the base64 passwordHash and salt fields of a Firebase
+// user export as $firebase-scrypt$<salt>$<hash> for storage and verification.
+func FirebaseHash(passwordHash, salt string) string {
+	return firebasePrefix + salt + "$" + passwordHash
+}
+
+// ID reports the scheme identifier.
+func (v *Verifier) ID() string {
+	return "scrypt"
+}
+
+// Recognize reports whether encoded is in one of the supported scrypt formats.
+func (v *Verifier) Recognize(encoded string) bool {
+	switch {
+	case strings.HasPrefix(encoded, phcPrefix):
+		return strings.Count(encoded, "$") == 4
+	case strings.HasPrefix(encoded, firebasePrefix):
+		return strings.Count(encoded, "$") == 3
+	case strings.HasPrefix(encoded, ciscoPrefix):
+		return len(encoded) == len(ciscoPrefix)+ciscoSaltLength+1+ciscoHashLength &&
+			encoded[len(ciscoPrefix)+ciscoSaltLength] == '$'
+	}
+
+	return false
+}
+
+// Verify recomputes the scrypt hash after enforcing parameter ceilings and compares it in constant time.
+func (v *Verifier) Verify(password []byte, encoded string) (bool, error) {
+	defer zero.Bytes(password)
+
+	switch {
+	case strings.HasPrefix(encoded, phcPrefix):
+		return v.verifyPHC(password, encoded)
+	case strings.HasPrefix(encoded, firebasePrefix):
+		return v.verifyFirebase(password, encoded)
+	case v.Recognize(encoded):
+		return v.verifyCisco(password, encoded)
+	}
+
+	return false, errInvalidHash
+}
+
+// NeedsRehash always reports true for scrypt hashes.
+func (v *Verifier) NeedsRehash(encoded string) (bool, error) {
+	if !v.Recognize(encoded) {
+		return false, errInvalidHash
+	}
+
+	return true, nil
+}
+
+// verifyPHC handles $scrypt$ln=<log2 N>,r=<r>,p=<p>$<salt>$<hash>, with salt and
+// hash in standard or passlib adapted base64.
+func (v *Verifier) verifyPHC(password []byte, encoded string) (bool, error) {
+	parts := strings.Split(encoded, "$")
+	if len(parts) != 5 {
+		return false, errInvalidHash
+	}
+
+	params := map[string]string{}
+	for _, kv := range strings.Split(parts[2], ",") {
+		k, val, ok := strings.Cut(kv, "=")
+		if !ok {
+			return false, errInvalidHash
+		}
+		params[k] = val
+	}
+
+	logN, err := strconv.Atoi(params["ln"])
+	if err != nil {
+		return false, err
+	}
+
+	r, err := strconv.Atoi(params["r"])
+	if err != nil {
+		return false, err
+	}
+
+	p, err := strconv.Atoi(params["p"])
+	if err != nil {
+		return false, err
+	}
+
+	salt, err := encoding.DecodeAdaptedBase64(parts[3])
+	if err != nil {
+		return false, err
+	}
+
+	want, err := encoding.DecodeAdaptedBase64(parts[4])
+	if err != nil {
+		return false, err
+	}
+
+	defer zero.Bytes(want)
+
+	return compute(password, salt, logN, r, p, want)
+}
+
+// verifyFirebase reproduces Firebase's modified scrypt: the derived key encrypts
+// the project signer key with AES-256-CTR and the ciphertext is the stored hash.
+func (v *Verifier) verifyFirebase(password []byte, encoded string) (bool, error) {
+	if v.firebase == nil {
+		return false, errors.New("scrypt verifier has no firebase parameters")
+	}
+
+	parts := strings.Split(strings.TrimPrefix(encoded, firebasePrefix), "$")
+	if len(parts) != 2 {
+		return false, errInvalidHash
+	}
+
+	salt, err := base64.StdEncoding.DecodeString(parts[0])
+	if err != nil {
+		return false, err
+	}
+
+	want, err := base64.StdEncoding.DecodeString(parts[1])
+	if err != nil {
+		return false, err
+	}
+
+	fb := v.firebase
+	if err := checkLimits(fb.MemCost, fb.Rounds, 1, 32); err != nil {
+		return false, err
+	}
+
+	saltSep := append(salt, fb.SaltSeparator...)
+	key, err := xscrypt.Key(password, saltSep, 1<<fb.MemCost, fb.Rounds, 1, 32)
+	if err != nil {
+		return false, err
+	}
+
+	defer zero.Bytes(key)
+
+	block, err := aes.NewCipher(key)
+	if err != nil {
+		return false, err
+	}
+
+	got := make([]byte, len(fb.SignerKey))
+	cipher.NewCTR(block, make([]byte, aes.BlockSize)).XORKeyStream(got, fb.SignerKey)
+
+	return subtle.ConstantTimeCompare(got, want), nil
+}
+
+// verifyCisco handles $9$<salt>$<hash>, where the salt is used verbatim and the
+// hash is encoded with the crypt alphabet.
+func (v *Verifier) verifyCisco(password []byte, encoded string) (bool, error) {
+	salt := encoded[len(ciscoPrefix) : len(ciscoPrefix)+ciscoSaltLength]
+
+	want, err := encoding.CryptBase64.DecodeString(encoded[len(ciscoPrefix)+ciscoSaltLength+1:])
+	if err != nil {
+		return false, err
+	}
+
+	return compute(password, []byte(salt), ciscoLogN, 1, 1, want)
+}
+
+func compute(password, salt []byte, logN, r, p int, want []byte) (bool, error) {
+	if err := checkLimits(logN, r, p, len(want)); err != nil {
+		return false, err
+	}
+
+	key, err := xscrypt.Key(password, salt, 1<<logN, r, p, len(want))
+	if err != nil {
+		return false, err
+	}
+
+	defer zero.Bytes(key)
+
+	return subtle.ConstantTimeCompare(key, want), nil
+}
+
+// checkLimits rejects stored parameters before any memory is allocated.
+func checkLimits(logN, r, p, keyLen int) error {
+	if logN < 1 || logN > MaxLogN {
+		return fmt.Errorf("scrypt cost out of range")
+	}
+	if r < 1 || r > MaxR {
+		return fmt.Errorf("scrypt block size out of range")
+	}
+	if p < 1 || p > MaxP {
+		return fmt.Errorf("scrypt parallelism out of range")
+	}
+	if keyLen < 1 || keyLen > MaxKeyLen {
+		return fmt.Errorf("scrypt key length out of range")
+	}
+	if 128*r*(1<<logN) > MaxMemory {
+		return fmt.Errorf("scrypt memory too high")
+	}
+	return nil
+}
@@ -0,0 +1,110 @@
+package scrypt
+
+import (
+	"encoding/base64"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func firebaseParams(t *testing.T) FirebaseParams {
+	t.Helper()
+
+	signerKey, err := base64.StdEncoding.DecodeString("jxspr8Ki0RYycVU8zykbdLGjFQ3McFUH0uiiTvC8pVMXAn210wjLNmdZJzxUECKbm0QsEmYUSDzZvpjeJ9WmXA==")
+	require.NoError(t, err)
+
+	return FirebaseParams{
+		SignerKey:     signerKey,
+		SaltSeparator: []byte{0x07},
+		Rounds:        8,
+		MemCost:       14,
+	}
+}
+
+func TestVerifier_Verify(t *testing.T) {
+	tests := []struct {
+		name     string
+		password string
+		encoded  string
+	}{
+		{
+			name:     "phc",
+			password: "correct horse",
+			encoded:  "$scrypt$ln=10,r=8,p=1$++++AQID+D77774JEBESEw$9mFyDpBW+6GPvWuw2258pUwNd9pg0RC47fnVy6N/kCk",
+		},
+		{
+			name:     "passlib",
+			password: "correct horse",
+			encoded:  "$scrypt$ln=10,r=8,p=1$....AQID.D77774JEBESEw$9mFyDpBW.6GPvWuw2258pUwNd9pg0RC47fnVy6N/kCk",
+		},
+		{
+			name:     "passlibDocs",
+			password: "password",
+			encoded:  "$scrypt$ln=16,r=8,p=1$aM15713r3Xsvxbi31lqr1Q$nFNh2CVHVjNldFVKDHDlm4CbdRSCdEBsjjJxD+iCs5E",
+		},
+		{
+			name:     "firebase",
+			password: "user1password",
+			encoded:  FirebaseHash("lSrfV15cpx95/sZS2W9c9Kp6i/LVgQNDNC/qzrCnh1SAyZvqmZqAjTdn3aoItz+VHjoZilo78198JAdRuid5lQ==", "42xEC+ixf3L2lw=="),
+		},
+		{
+			name:     "ciscoType9",
+			password: "hashcat",
+			encoded:  "$9$2MJBozw/9R3UsU$2lFhcKvpghcyw8deP25GOfyZaagyUOGBymkryvOdfo6",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			verifier := New(WithFirebase(firebaseParams(t)))
+			require.True(t, verifier.Recognize(tt.encoded))
+
+			ok, err := verifier.Verify([]byte(tt.password), tt.encoded)
+			require.NoError(t, err)
+			require.True(t, ok)
+
+			ok, err = verifier.Verify([]byte("wrong"), tt.encoded)
+			require.NoError(t, err)
+			require.False(t, ok)
+
+			needs, err := verifier.NeedsRehash(tt.encoded)
+			require.NoError(t, err)
+			require.True(t, needs)
+		})
+	}
+}
+
+func TestVerifier_EnforcesCeilings(t *testing.T) {
+	tests := []struct {
+		name    string
+		encoded string
+	}{
+		{name: "logN", encoded: "$scrypt$ln=30,r=8,p=1$YWJj$ZGVm"},
+		{name: "blockSize", encoded: "$scrypt$ln=10,r=64,p=1$YWJj$ZGVm"},
+		{name: "parallelism", encoded: "$scrypt$ln=10,r=8,p=64$YWJj$ZGVm"},
+		{name: "memory", encoded: "$scrypt$ln=20,r=16,p=1$YWJj$ZGVm"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := New().Verify([]byte("password"), tt.encoded)
+			require.Error(t, err)
+		})
+	}
+}
+
+func TestVerifier_Errors(t *testing.T) {
+	verifier := New()
+
+	_, err := verifier.Verify([]byte("password"), FirebaseHash("ZGVm", "YWJj"))
+	require.ErrorContains(t, err, "firebase")
+
+	_, err = verifier.Verify([]byte("password"), "$2b$04$p4wSFWnH/3cP53yyCWSnEuxvwVcro/yOPi/Vi6hez5ZvWA8q1d7WO")
+	require.Error(t, err)
+
+	_, err = verifier.Verify([]byte("password"), "$scrypt$ln=10,r=8$YWJj$ZGVm")
+	require.Error(t, err)
+
+	_, err = verifier.NeedsRehash("$9$short$hash")
+	require.Error(t, err)
+}
@@ -0,0 +1,115 @@
+package pwdhash
+
+import (
+	"fmt"
+	"io"
+	"log/slog"
+	"sync"
+
+	"github.com/allisson/go-pwdhash/internal/zero"
+)
+
+// MaxSecretLength bounds how many bytes ReadSecret accepts.
+const MaxSecretLength = 64 * 1024
+
+const redacted = "[REDACTED]"
+
+// Secret holds a password in a buffer owned by pwdhash.
+//
+// It prints as [REDACTED] under fmt, encoding/json, and log/slog, and keeps its
+// contents until Destroy zeroizes them; any later use returns
+// ErrSecretDestroyed. PasswordHasher.HashSecret and VerifySecret work on a
+// copy, so one Secret can be passed to several calls.
+type Secret struct {
+	mu        sync.Mutex
+	buf       []byte
+	destroyed bool
+}
+
+// NewSecret copies password into a new Secret. The caller's slice is left
+// untouched and may be wiped independently.
+func NewSecret(password []byte) *Secret {
+	return &Secret{buf: append([]byte{}, password...)}
+}
+
+// NewSecretString copies password into a new Secret.
+func NewSecretString(password string) *Secret {
+	return &Secret{buf: []byte(password)}
+}
+
+// ReadSecret reads r to EOF into a new Secret, rejecting input longer than
+// MaxSecretLength.
+func ReadSecret(r io.Reader) (*Secret, error) {
+	buf, err := io.ReadAll(io.LimitReader(r, MaxSecretLength+1))
+	if err != nil {
+		zero.Bytes(buf)
+		return nil, err
+	}
+
+	if len(buf) > MaxSecretLength {
+		zero.Bytes(buf)
+		return nil, fmt.Errorf("secret exceeds %d bytes", MaxSecretLength)
+	}
+
+	return &Secret{buf: buf}, nil
+}
+
+// Destroy zeroizes the secret. It is safe to call more than once.
+func (s *Secret) Destroy() {
+	if s == nil {
+		return
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	zero.Bytes(s.buf)
+	s.buf = nil
+	s.destroyed = true
+}
+
+// String implements fmt.Stringer without revealing the secret.
+func (s *Secret) String() string {
+	return redacted
+}
+
+// GoString implements fmt.GoStringer without revealing the secret.
+func (s *Secret) GoString() string {
+	return redacted
+}
+
+// Format implements fmt.Formatter so every verb prints the redaction marker.
+func (s *Secret) Format(f fmt.State, _ rune) {
+	_, _ = io.WriteString(f, redacted)
+}
+
+// MarshalJSON encodes the secret as the redaction marker.
+func (s *Secret) MarshalJSON() ([]byte, error) {
+	return []byte(`"` + redacted + `"`), nil
+}
+
+// MarshalText encodes the secret as the redaction marker.
+func (s *Secret) MarshalText() ([]byte, error) {
+	return []byte(redacted), nil
+}
+
+// LogValue implements slog.LogValuer so structured logs never carry the secret.
+func (s *Secret) LogValue() slog.Value {
+	return slog.StringValue(redacted)
+}
+
+// reveal returns a fresh copy of the secret for APIs that zeroize their input.
+func (s *Secret) reveal() ([]byte, error) {
+	if s == nil {
+		return nil, ErrSecretDestroyed
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if s.destroyed {
+		return nil, ErrSecretDestroyed
+	}
+
+	return append([]byte{}, s.buf...), nil
+}
@@ -0,0 +1,97 @@
+package pwdhash
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"log/slog"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestSecret_Constructors(t *testing.T) {
+	raw := []byte("s3cret")
+
+	fromReader, err := ReadSecret(strings.NewReader("s3cret"))
+	require.NoError(t, err)
+
+	for _, secret := range []*Secret{NewSecret(raw), NewSecretString("s3cret"), fromReader} {
+		got, err := secret.reveal()
+		require.NoError(t, err)
+		require.Equal(t, "s3cret", string(got))
+	}
+
+	_, err = ReadSecret(bytes.NewReader(make([]byte, MaxSecretLength+1)))
+	require.Error(t, err)
+
+	// NewSecret copies, so wiping the caller's slice leaves the secret intact.
+	secret := NewSecret(raw)
+	raw[0] = 0
+
+	got, err := secret.reveal()
+	require.NoError(t, err)
+	require.Equal(t, "s3cret", string(got))
+}
+
+func TestSecret_Redaction(t *testing.T) {
+	secret := NewSecretString("s3cret")
+
+	for _, verb := range []string{"%v", "%+v", "%#v", "%s", "%q", "%x", "%d"} {
+		require.Equal(t, "[REDACTED]", fmt.Sprintf(verb, secret), verb)
+	}
+
+	encoded, err := json.Marshal(struct {
+		Password *Secret `json:"password"`
+	}{secret})
+	require.NoError(t, err)
+	require.JSONEq(t, `{"password":"[REDACTED]"}`, string(encoded))
+
+	var buf bytes.Buffer
+	slog.New(slog.NewJSONHandler(&buf, nil)).Info("login", "password", secret)
+	require.Contains(t, buf.String(), `"password":"[REDACTED]"`)
+	require.NotContains(t, buf.String(), "s3cret")
+}
+
+func TestSecret_Destroy(t *testing.T) {
+	ph, err := New(WithHasher(newTestArgon2()))
+	require.NoError(t, err)
+
+	secret := NewSecretString("s3cret")
+	buf := secret.buf
+
+	secret.Destroy()
+	secret.Destroy()
+	require.Equal(t, make([]byte, len(buf)), buf)
+
+	_, err = ph.HashSecret(secret)
+	require.ErrorIs(t, err, ErrSecretDestroyed)
+
+	_, err = ph.VerifySecret(secret, "$argon2id$v=19$m=1,t=1,p=1$YWJj$ZGVm")
+	require.ErrorIs(t, err, ErrSecretDestroyed)
+
+	_, err = ph.HashSecret(nil)
+	require.ErrorIs(t, err, ErrSecretDestroyed)
+}
+
+func TestPasswordHasher_SecretIsReusable(t *testing.T) {
+	ph, err := New(WithHasher(newTestArgon2()))
+	require.NoError(t, err)
+
+	secret := NewSecretString("s3cret")
+	defer secret.Destroy()
+
+	encoded, err := ph.HashSecret(secret)
+	require.NoError(t, err)
+
+	for range 2 {
+		ok, err := ph.VerifySecret(secret, encoded)
+		require.NoError(t, err)
+		require.True(t, ok)
+	}
+
+	ok, err := ph.VerifySecret(NewSecretString("wrong"), encoded)
+	require.NoError(t, err)
+	require.False(t, ok)
+}
@@ -0,0 +1,143 @@
+package pwdhash
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"os"
+	"path/filepath"
+	"sync"
+)
+
+// Store loads and persists password hashes by user identifier.
+type Store interface {
+	// LoadHash returns the encoded hash for user, or ErrUserNotFound.
+	LoadHash(ctx context.Context, user string) (string, error)
+	// UpdateHash replaces the encoded hash for user.
+	UpdateHash(ctx context.Context, user, encoded string) error
+}
+
+// MemoryStore is a Store backed by a map, intended for tests and prototypes.
+type MemoryStore struct {
+	mu     sync.RWMutex
+	hashes map[string]string
+}
+
+// NewMemoryStore returns an empty MemoryStore.
+func NewMemoryStore() *MemoryStore {
+	return &MemoryStore{hashes: make(map[string]string)}
+}
+
+// LoadHash returns the encoded hash for user, or ErrUserNotFound.
+func (m *MemoryStore) LoadHash(_ context.Context, user string) (string, error) {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+
+	encoded, ok := m.hashes[user]
+	if !ok {
+		return "", ErrUserNotFound
+	}
+
+	return encoded, nil
+}
+
+// UpdateHash stores encoded for user, adding the user if needed.
+func (m *MemoryStore) UpdateHash(_ context.Context, user, encoded string) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	m.hashes[user] = encoded
+
+	return nil
+}
+
+// FileStore is a Store persisted as a JSON object mapping users to hashes.
+//
+// The file is read once by OpenFileStore and rewritten atomically, with mode
+// 0600, on every update. It suits small deployments and tooling; it does not
+// coordinate with other processes writing the same file.
+type FileStore struct {
+	mu     sync.RWMutex
+	path   string
+	hashes map[string]string
+}
+
+// OpenFileStore loads the store at path; a missing file yields an empty store.
+func OpenFileStore(path string) (*FileStore, error) {
+	hashes := make(map[string]string)
+
+	data, err := os.ReadFile(path) // #nosec G304 -- the path is chosen by the caller
+	switch {
+	case errors.Is(err, os.ErrNotExist):
+	case err != nil:
+		return nil, err
+	default:
+		if err := json.Unmarshal(data, &hashes); err != nil {
+			return nil, err
+		}
+	}
+
+	return &FileStore{path: path, hashes: hashes}, nil
+}
+
+// LoadHash returns the encoded hash for user, or ErrUserNotFound.
+func (f *FileStore) LoadHash(_ context.Context, user string) (string, error) {
+	f.mu.RLock()
+	defer f.mu.RUnlock()
+
+	encoded, ok := f.hashes[user]
+	if !ok {
+		return "", ErrUserNotFound
+	}
+
+	return encoded, nil
+}
+
+// UpdateHash stores encoded for user, adding the user if needed, and rewrites the file.
+func (f *FileStore) UpdateHash(_ context.Context, user, encoded string) error {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	previous, existed := f.hashes[user]
+	f.hashes[user] = encoded
+
+	if err := f.save(); err != nil {
+		if existed {
+			f.hashes[user] = previous
+		} else {
+			delete(f.hashes, user)
+		}
+		return err
+	}
+
+	return nil
+}
+
+// save writes the hashes to a temporary file and renames it over the store.
+func (f *FileStore) save() error {
+	data, err := json.MarshalIndent(f.hashes, "", "  ")
+	if err != nil {
+		return err
+	}
+
+	tmp, err := os.CreateTemp(filepath.Dir(f.path), filepath.Base(f.path)+".*")
+	if err != nil {
+		return err
+	}
+	// Removing fails harmlessly once the rename has succeeded.
+	defer func() { _ = os.Remove(tmp.Name()) }()
+
+	if _, err := tmp.Write(data); err != nil {
+		_ = tmp.Close()
+		return err
+	}
+	if err := tmp.Sync(); err != nil {
+		_ = tmp.Close()
+		return err
+	}
+	if err := tmp.Close(); err != nil {
+		return err
+	}
+
+	return os.Rename(tmp.Name(), f.path)
+}
@@ -0,0 +1,77 @@
+package pwdhash
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestStores(t *testing.T) {
+	fileStore, err := OpenFileStore(filepath.Join(t.TempDir(), "hashes.json"))
+	require.NoError(t, err)
+
+	tests := []struct {
+		name  string
+		store Store
+	}{
+		{name: "memory", store: NewMemoryStore()},
+		{name: "file", store: fileStore},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ctx := context.Background()
+
+			_, err := tt.store.LoadHash(ctx, "alice")
+			require.ErrorIs(t, err, ErrUserNotFound)
+
+			require.NoError(t, tt.store.UpdateHash(ctx, "alice", "hash-1"))
+			require.NoError(t, tt.store.UpdateHash(ctx, "alice", "hash-2"))
+
+			encoded, err := tt.store.LoadHash(ctx, "alice")
+			require.NoError(t, err)
+			require.Equal(t, "hash-2", encoded)
+		})
+	}
+}
+
+func TestFileStore_Persists(t *testing.T) {
+	ctx := context.Background()
+	path := filepath.Join(t.TempDir(), "hashes.json")
+
+	store, err := OpenFileStore(path)
+	require.NoError(t, err)
+	require.NoError(t, store.UpdateHash(ctx, "alice", "hash-1"))
+
+	info, err := os.Stat(path)
+	require.NoError(t, err)
+	require.Equal(t, os.FileMode(0o600), info.Mode().Perm())
+
+	reopened, err := OpenFileStore(path)
+	require.NoError(t, err)
+
+	encoded, err := reopened.LoadHash(ctx, "alice")
+	require.NoError(t, err)
+	require.Equal(t, "hash-1", encoded)
+}
+
+func TestFileStore_Errors(t *testing.T) {
+	dir := t.TempDir()
+
+	corrupt := filepath.Join(dir, "corrupt.json")
+	require.NoError(t, os.WriteFile(corrupt, []byte("{"), 0o600))
+
+	_, err := OpenFileStore(corrupt)
+	require.Error(t, err)
+
+	store, err := OpenFileStore(filepath.Join(dir, "missing", "hashes.json"))
+	require.NoError(t, err)
+
+	require.Error(t, store.UpdateHash(context.Background(), "alice", "hash-1"))
+
+	_, err = store.LoadHash(context.Background(), "alice")
+	require.ErrorIs(t, err, ErrUserNotFound)
+}
@@ -0,0 +1,201 @@
+package pwdhash
+
+import (
+	"database/sql/driver"
+	"encoding/json"
+	"fmt"
+	"log/slog"
+	"maps"
+	"strings"
+
+	"github.com/allisson/go-pwdhash/internal/encoding"
+)
+
+// HashInfo describes the decoded parameters of a stored PHC hash.
+type HashInfo struct {
+	Algorithm  string
+	Version    int
+	Params     map[string]string
+	SaltLength int
+	HashLength int
+}
+
+// StoredHash is a validated PHC string as kept in a database column or API
+// payload.
+//
+// It implements sql.Scanner, driver.Valuer, json.Marshaler, json.Unmarshaler,
+// encoding.TextMarshaler, and encoding.TextUnmarshaler, rejecting malformed
+// values with ErrInvalidHash where they enter the program. The zero value
+// stands for SQL NULL and JSON null. Printing a StoredHash through fmt or slog
+// redacts the salt and hash; Encoded returns the full string for Verify.
+//
+// Only PHC strings are accepted. Scan rows that still hold non-PHC legacy
+// formats, such as bcrypt, into a string until they have been rehashed.
+type StoredHash struct {
+	encoded string
+	info    HashInfo
+}
+
+// ParseStoredHash validates encoded as a PHC string.
+func ParseStoredHash(encoded string) (StoredHash, error) {
+	parsed, err := encoding.Parse(encoded)
+	if err != nil {
+		return StoredHash{}, fmt.Errorf("%w: %v", ErrInvalidHash, err)
+	}
+
+	return StoredHash{
+		encoded: encoded,
+		info: HashInfo{
+			Algorithm:  parsed.Algorithm,
+			Version:    parsed.Version,
+			Params:     parsed.Params,
+			SaltLength: len(parsed.Salt),
+			HashLength: len(parsed.Hash),
+		},
+	}, nil
+}
+
+// Encoded returns the full PHC string.
+func (h StoredHash) Encoded() string {
+	return h.encoded
+}
+
+// Info returns the decoded parameters.
+func (h StoredHash) Info() HashInfo {
+	info := h.info
+	info.Params = maps.Clone(h.info.Params)
+	return info
+}
+
+// IsZero reports whether h holds no hash.
+func (h StoredHash) IsZero() bool {
+	return h.encoded == ""
+}
+
+// String renders the PHC string with the salt and hash replaced by [REDACTED].
+func (h StoredHash) String() string {
+	return redactHash(h.encoded)
+}
+
+// GoString implements fmt.GoStringer with the same redaction as String.
+func (h StoredHash) GoString() string {
+	return h.String()
+}
+
+// LogValue implements slog.LogValuer with the same redaction as String.
+func (h StoredHash) LogValue() slog.Value {
+	return slog.StringValue(h.String())
+}
+
+// Scan implements sql.Scanner for string and []byte columns; NULL yields the zero value.
+func (h *StoredHash) Scan(src any) error {
+	switch v := src.(type) {
+	case nil:
+		*h = StoredHash{}
+		return nil
+	case string:
+		return h.set(v)
+	case []byte:
+		return h.set(string(v))
+	}
+
+	return fmt.Errorf("%w: cannot scan %T", ErrInvalidHash, src)
+}
+
+// Value implements driver.Valuer; the zero value is stored as NULL.
+func (h StoredHash) Value() (driver.Value, error) {
+	if h.IsZero() {
+		return nil, nil
+	}
+
+	return h.encoded, nil
+}
+
+// MarshalJSON encodes the full PHC string, or null for the zero value.
+func (h StoredHash) MarshalJSON() ([]byte, error) {
+	if h.IsZero() {
+		return []byte("null"), nil
+	}
+
+	return json.Marshal(h.encoded)
+}
+
+// UnmarshalJSON decodes and validates a PHC string; null yields the zero value.
+func (h *StoredHash) UnmarshalJSON(data []byte) error {
+	if string(data) == "null" {
+		*h = StoredHash{}
+		return nil
+	}
+
+	var encoded string
+	if err := json.Unmarshal(data, &encoded); err != nil {
+		return err
+	}
+
+	return h.set(encoded)
+}
+
+// MarshalText encodes the full PHC string.
+func (h StoredHash) MarshalText() ([]byte, error) {
+	return []byte(h.encoded), nil
+}
+
+// UnmarshalText decodes and validates a PHC string; empty input yields the zero value.
+func (h *StoredHash) UnmarshalText(text []byte) error {
+	if len(text) == 0 {
+		*h = StoredHash{}
+		return nil
+	}
+
+	return h.set(string(text))
+}
+
+func (h *StoredHash) set(encoded string) error {
+	parsed, err := ParseStoredHash(encoded)
+	if err != nil {
+		return err
+	}
+
+	*h = parsed
+
+	return nil
+}
+
+// redactHash renders encoded with its salt and hash replaced by [REDACTED].
+// PHC strings keep their algorithm, version, and parameters exactly as
+// stored, and nothing after them; any other format keeps only its scheme
+// marker, as in {SSHA}[REDACTED] or pbkdf2_sha256$[REDACTED].
+func redactHash(encoded string) string {
+	if encoded == "" {
+		return ""
+	}
+
+	if _, err := encoding.Parse(encoded); err == nil {
+		// Mirror encoding.Parse: unversioned strings have one fewer
+		// header segment.
+		parts := strings.Split(encoded, "$")
+		header := 4
+		if len(parts) == 5 && strings.Contains(parts[2], "=") {
+			header = 3
+		}
+
+		return strings.Join(parts[:header], "$") + "$" + redacted + "$" + redacted
+	}
+
+	switch {
+	case strings.HasPrefix(encoded, "{"):
+		if end := strings.IndexByte(encoded, '}'); end > 0 {
+			return encoded[:end+1] + redacted
+		}
+	case strings.HasPrefix(encoded, "$"):
+		if scheme, _, ok := strings.Cut(encoded[1:], "$"); ok {
+			return "$" + scheme + "$" + redacted
+		}
+	default:
+		if scheme, _, ok := strings.Cut(encoded, "$"); ok {
+			return scheme + "$" + redacted
+		}
+	}
+
+	return redacted
+}
@@ -0,0 +1,148 @@
+package pwdhash
+
+import (
+	"database/sql/driver"
+	"encoding/json"
+	"fmt"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+const testStoredHash = "$argon2id$v=19$m=65536,p=4,t=3$c29tZXNhbHQ$ZGVyaXZlZGtleQ"
+
+func TestParseStoredHash(t *testing.T) {
+	h, err := ParseStoredHash(testStoredHash)
+	require.NoError(t, err)
+	require.Equal(t, testStoredHash, h.Encoded())
+	require.Equal(t, HashInfo{
+		Algorithm:  "argon2id",
+		Version:    19,
+		Params:     map[string]string{"m": "65536", "p": "4", "t": "3"},
+		SaltLength: 8,
+		HashLength: 10,
+	}, h.Info())
+
+	info := h.Info()
+	info.Params["m"] = "1"
+	require.Equal(t, "65536", h.Info().Params["m"])
+
+	for _, encoded := range []string{"", "plaintext", "$2b$04$p4wSFWnH/3cP53yyCWSnEuxvwVcro/yOPi/Vi6hez5ZvWA8q1d7WO", "$argon2id$v=19$m=1$!!$ZGVm"} {
+		_, err := ParseStoredHash(encoded)
+		require.ErrorIs(t, err, ErrInvalidHash, encoded)
+	}
+}
+
+func TestStoredHash_Redaction(t *testing.T) {
+	h, err := ParseStoredHash(testStoredHash)
+	require.NoError(t, err)
+
+	want := "$argon2id$v=19$m=65536,p=4,t=3$[REDACTED]$[REDACTED]"
+	for _, verb := range []string{"%v", "%s", "%+v", "%#v"} {
+		require.Equal(t, want, fmt.Sprintf(verb, h), verb)
+	}
+	require.Equal(t, want, h.LogValue().String())
+	require.Empty(t, StoredHash{}.String())
+
+	// Only the header survives, even with trailing segments after the hash.
+	h, err = ParseStoredHash(testStoredHash + "$ZXh0cmE")
+	require.NoError(t, err)
+	require.Equal(t, want, h.String())
+
+	h, err = ParseStoredHash("$argon2i$m=65536,t=2,p=1$c29tZXNhbHQ$ZGVyaXZlZGtleQ")
+	require.NoError(t, err)
+	require.Equal(t, "$argon2i$m=65536,t=2,p=1$[REDACTED]$[REDACTED]", h.String())
+}
+
+func TestRedactHash(t *testing.T) {
+	tests := []struct {
+		name    string
+		encoded string
+		want    string
+	}{
+		{name: "aead", encoded: "$aead$v=1$a=aes256gcm,k=2$bm9uY2Vub25jZQ$Y2lwaGVydGV4dA", want: "$aead$v=1$a=aes256gcm,k=2$[REDACTED]$[REDACTED]"},
+		{name: "ldap", encoded: "{SSHA}W6ph5Mm5Pz8GgiULbPgzG37mj9g9o5Ki", want: "{SSHA}[REDACTED]"},
+		{name: "firebase", encoded: "$firebase-scrypt$c2FsdA$aGFzaA", want: "$firebase-scrypt$[REDACTED]"},
+		{name: "django", encoded: "pbkdf2_sha256$260000$salt$aGFzaA==", want: "pbkdf2_sha256$[REDACTED]"},
+		{name: "shaCrypt", encoded: "$6$rounds=5000$saltstring$adDbXsJjcDlq2662QPgd", want: "$6$rounds=5000$[REDACTED]$[REDACTED]"},
+		{name: "bcrypt", encoded: "$2b$04$p4wSFWnH/3cP53yyCWSnEuxvwVcro/yOPi/Vi6hez5ZvWA8q1d7WO", want: "$2b$[REDACTED]"},
+		{name: "mysqlNative", encoded: "*2470C0C06DEE42FD1618BB99005ADCA2EC9D1E19", want: "[REDACTED]"},
+		{name: "unterminatedScheme", encoded: "{SSHA", want: "[REDACTED]"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			require.Equal(t, tt.want, redactHash(tt.encoded))
+		})
+	}
+}
+
+func TestStoredHash_SQL(t *testing.T) {
+	tests := []struct {
+		name    string
+		src     any
+		want    string
+		wantErr bool
+	}{
+		{name: "string", src: testStoredHash, want: testStoredHash},
+		{name: "bytes", src: []byte(testStoredHash), want: testStoredHash},
+		{name: "null", src: nil, want: ""},
+		{name: "corrupt", src: "$argon2id$garbage", wantErr: true},
+		{name: "unsupportedType", src: 42, wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var h StoredHash
+			err := h.Scan(tt.src)
+			if tt.wantErr {
+				require.ErrorIs(t, err, ErrInvalidHash)
+				return
+			}
+
+			require.NoError(t, err)
+			require.Equal(t, tt.want, h.Encoded())
+
+			value, err := h.Value()
+			require.NoError(t, err)
+			if tt.want == "" {
+				require.Nil(t, value)
+				return
+			}
+			require.Equal(t, driver.Value(tt.want), value)
+		})
+	}
+}
+
+func TestStoredHash_JSONAndText(t *testing.T) {
+	type row struct {
+		Hash StoredHash `json:"hash"`
+	}
+
+	h, err := ParseStoredHash(testStoredHash)
+	require.NoError(t, err)
+
+	encoded, err := json.Marshal(row{Hash: h})
+	require.NoError(t, err)
+	require.JSONEq(t, `{"hash":"`+testStoredHash+`"}`, string(encoded))
+
+	var decoded row
+	require.NoError(t, json.Unmarshal(encoded, &decoded))
+	require.Equal(t, h, decoded.Hash)
+
+	encoded, err = json.Marshal(row{})
+	require.NoError(t, err)
+	require.JSONEq(t, `{"hash":null}`, string(encoded))
+	require.NoError(t, json.Unmarshal(encoded, &decoded))
+	require.True(t, decoded.Hash.IsZero())
+
+	require.ErrorIs(t, json.Unmarshal([]byte(`{"hash":"nope"}`), &decoded), ErrInvalidHash)
+
+	text, err := h.MarshalText()
+	require.NoError(t, err)
+
+	var fromText StoredHash
+	require.NoError(t, fromText.UnmarshalText(text))
+	require.Equal(t, h, fromText)
+	require.ErrorIs(t, fromText.UnmarshalText([]byte("nope")), ErrInvalidHash)
+}
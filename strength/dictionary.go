@@ -0,0 +1,100 @@
+package strength
+
+import (
+	"embed"
+	"strings"
+	"sync"
+)
+
+// Dictionary names, reported in Match.Dictionary.
+const (
+	DictionaryPasswords  = "passwords"
+	DictionaryEnglish    = "english"
+	DictionaryNames      = "names"
+	DictionarySurnames   = "surnames"
+	DictionaryUserInputs = "user_inputs"
+)
+
+// The frequency lists are those shipped with zxcvbn (MIT licensed, by
+// Dropbox), as packaged by zxcvbn-go: passwords from leaked corpora, English
+// words by subtitle frequency, and US census first names and the 10,000 most
+// common surnames. Each file holds one lowercase word per line, most
+// frequent first.
+//
+//go:embed data/*.txt
+var dictionaryFiles embed.FS
+
+// rankedDictionaries maps each built-in dictionary to word ranks starting at
+// 1. First names interleave the male and female lists.
+var rankedDictionaries = sync.OnceValue(func() map[string]map[string]int {
+	return map[string]map[string]int{
+		DictionaryPasswords: rank(wordsOf("passwords")),
+		DictionaryEnglish:   rank(wordsOf("english")),
+		DictionaryNames:     rank(interleave(wordsOf("male_names"), wordsOf("female_names"))),
+		DictionarySurnames:  rank(wordsOf("surnames")),
+	}
+})
+
+func wordsOf(name string) []string {
+	data, err := dictionaryFiles.ReadFile("data/" + name + ".txt")
+	if err != nil {
+		panic(err) // embedded at build time
+	}
+
+	return strings.Fields(string(data))
+}
+
+// interleave merges frequency lists so that each word's position reflects its
+// rank within its own list.
+func interleave(lists ...[]string) []string {
+	var out []string
+	for i := 0; ; i++ {
+		done := true
+		for _, l := range lists {
+			if i < len(l) {
+				out = append(out, l[i])
+				done = false
+			}
+		}
+		if done {
+			return out
+		}
+	}
+}
+
+// rank assigns each word its first position in words, starting at 1.
+func rank(words []string) map[string]int {
+	ranks := make(map[string]int, len(words))
+	for i, w := range words {
+		w = strings.ToLower(w)
+		if _, ok := ranks[w]; !ok {
+			ranks[w] = i + 1
+		}
+	}
+
+	return ranks
+}
+
+// l33tTable maps substituted characters to the letters they may stand for.
+var l33tTable = map[rune][]rune{
+	'4': {'a'},
+	'@': {'a'},
+	'8': {'b'},
+	'(': {'c'},
+	'{': {'c'},
+	'[': {'c'},
+	'<': {'c'},
+	'3': {'e'},
+	'6': {'g'},
+	'9': {'g'},
+	'1': {'i', 'l'},
+	'!': {'i'},
+	'|': {'i', 'l'},
+	'0': {'o'},
+	'$': {'s'},
+	'5': {'s'},
+	'+': {'t'},
+	'7': {'t'},
+	'%': {'x'},
+	'2': {'z'},
+}
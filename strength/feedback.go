@@ -0,0 +1,133 @@
+package strength
+
+import (
+	"unicode"
+)
+
+const (
+	suggestFewWords    = "Use a few words, avoid common phrases."
+	suggestNoSymbols   = "No need for symbols, digits, or uppercase letters."
+	suggestAddWord     = "Add another word or two. Uncommon words are better."
+	suggestLongerWalk  = "Use a longer keyboard pattern with more turns."
+	suggestNoRepeats   = "Avoid repeated words and characters."
+	suggestNoSequences = "Avoid sequences."
+	suggestNoDates     = "Avoid dates and years that are associated with you."
+	suggestNoReversed  = "Reversed words aren't much harder to guess."
+	suggestNoL33t      = "Predictable substitutions like '@' instead of 'a' don't help very much."
+	suggestNoCaps      = "Capitalization doesn't help very much."
+	suggestNoAllCaps   = "All-uppercase is almost as easy to guess as all-lowercase."
+)
+
+// feedback explains the longest match of a weak password. Passwords scoring
+// 3 or more get none.
+func feedback(score int, sequence []Match) Feedback {
+	if len(sequence) == 0 {
+		return Feedback{Suggestions: []string{suggestFewWords, suggestNoSymbols}}
+	}
+	if score > 2 {
+		return Feedback{}
+	}
+
+	longest := sequence[0]
+	for _, m := range sequence[1:] {
+		if m.J-m.I > longest.J-longest.I {
+			longest = m
+		}
+	}
+
+	f := matchFeedback(longest, len(sequence) == 1)
+	f.Suggestions = append([]string{suggestAddWord}, f.Suggestions...)
+
+	return f
+}
+
+func matchFeedback(m Match, sole bool) Feedback {
+	switch m.Pattern {
+	case PatternDictionary:
+		return dictionaryFeedback(m, sole)
+	case PatternSpatial:
+		warning := "Short keyboard patterns are easy to guess."
+		if m.Turns == 1 {
+			warning = "Straight rows of keys are easy to guess."
+		}
+		return Feedback{Warning: warning, Suggestions: []string{suggestLongerWalk}}
+	case PatternRepeat:
+		warning := `Repeats like "abcabcabc" are only slightly harder to guess than "abc".`
+		if len([]rune(m.BaseToken)) == 1 {
+			warning = `Repeats like "aaa" are easy to guess.`
+		}
+		return Feedback{Warning: warning, Suggestions: []string{suggestNoRepeats}}
+	case PatternSequence:
+		return Feedback{
+			Warning:     "Sequences like abc or 6543 are easy to guess.",
+			Suggestions: []string{suggestNoSequences},
+		}
+	case PatternYear:
+		return Feedback{
+			Warning:     "Recent years are easy to guess.",
+			Suggestions: []string{suggestNoDates},
+		}
+	case PatternDate:
+		return Feedback{
+			Warning:     "Dates are often easy to guess.",
+			Suggestions: []string{suggestNoDates},
+		}
+	}
+
+	return Feedback{}
+}
+
+func dictionaryFeedback(m Match, sole bool) Feedback {
+	var f Feedback
+	switch m.Dictionary {
+	case DictionaryPasswords:
+		switch {
+		case sole && !m.L33t && !m.Reversed && m.Rank <= 10:
+			f.Warning = "This is a top-10 common password."
+		case sole && !m.L33t && !m.Reversed && m.Rank <= 100:
+			f.Warning = "This is a top-100 common password."
+		case sole:
+			f.Warning = "This is a very common password."
+		default:
+			f.Warning = "This is similar to a commonly used password."
+		}
+	case DictionaryEnglish:
+		if sole {
+			f.Warning = "A word by itself is easy to guess."
+		}
+	case DictionaryNames, DictionarySurnames:
+		if sole {
+			f.Warning = "Names and surnames by themselves are easy to guess."
+		} else {
+			f.Warning = "Common names and surnames are easy to guess."
+		}
+	case DictionaryUserInputs:
+		f.Warning = "Avoid words tied to your account, such as your name or email."
+	}
+
+	runes := []rune(m.Token)
+	switch {
+	case allUpper(runes):
+		f.Suggestions = append(f.Suggestions, suggestNoAllCaps)
+	case unicode.IsUpper(runes[0]):
+		f.Suggestions = append(f.Suggestions, suggestNoCaps)
+	}
+	if m.Reversed && len(runes) >= 4 {
+		f.Suggestions = append(f.Suggestions, suggestNoReversed)
+	}
+	if m.L33t {
+		f.Suggestions = append(f.Suggestions, suggestNoL33t)
+	}
+
+	return f
+}
+
+func allUpper(runes []rune) bool {
+	for _, r := range runes {
+		if unicode.IsLetter(r) && !unicode.IsUpper(r) {
+			return false
+		}
+	}
+
+	return true
+}
@@ -0,0 +1,285 @@
+package strength
+
+import (
+	"math"
+	"strings"
+	"unicode"
+)
+
+const (
+	// minYearSpace is the smallest range of years an attacker is assumed to try.
+	minYearSpace = 20
+	// growthPenalty is added per extra match so short decompositions win ties.
+	growthPenalty = 10000
+	// bruteforceCardinality is the assumed alphabet size for unmatched runes.
+	bruteforceCardinality = 10
+	minSingleCharGuesses  = 10
+	minMultiCharGuesses   = 50
+)
+
+// guesses estimates how many attempts an attacker needs to hit m.
+func (e *Estimator) guesses(m Match, passwordLength int) float64 {
+	var g float64
+	switch m.Pattern {
+	case PatternBruteforce:
+		g = math.Pow(bruteforceCardinality, float64(m.J-m.I+1))
+		if m.I == m.J {
+			return math.Max(g, minSingleCharGuesses+1)
+		}
+		return math.Max(g, minMultiCharGuesses+1)
+	case PatternDictionary:
+		g = float64(m.Rank) * uppercaseVariations(m.Token) * l33tVariations(m)
+		if m.Reversed {
+			g *= 2
+		}
+	case PatternSpatial:
+		g = spatialGuesses(m)
+	case PatternRepeat:
+		g = m.Guesses
+	case PatternSequence:
+		g = sequenceGuesses(m.Token)
+	case PatternYear:
+		g = e.yearSpace(m.Year)
+	case PatternDate:
+		g = 365 * e.yearSpace(m.Year)
+		if strings.ContainsAny(m.Token, "/-._ ") {
+			g *= 4
+		}
+	}
+
+	if m.J-m.I+1 < passwordLength {
+		if m.I == m.J {
+			return math.Max(g, minSingleCharGuesses)
+		}
+		return math.Max(g, minMultiCharGuesses)
+	}
+
+	return math.Max(g, 1)
+}
+
+func (e *Estimator) yearSpace(year int) float64 {
+	return math.Max(math.Abs(float64(year-e.now().Year())), minYearSpace)
+}
+
+// uppercaseVariations counts the capitalizations an attacker tries to reach token.
+func uppercaseVariations(token string) float64 {
+	var upper, lower int
+	for _, r := range token {
+		switch {
+		case unicode.IsUpper(r):
+			upper++
+		case unicode.IsLower(r):
+			lower++
+		}
+	}
+
+	runes := []rune(token)
+	switch {
+	case upper == 0:
+		return 1
+	case lower == 0,
+		upper == 1 && unicode.IsUpper(runes[0]),
+		upper == 1 && unicode.IsUpper(runes[len(runes)-1]):
+		return 2
+	}
+
+	return variations(upper, lower)
+}
+
+// l33tVariations counts the substitution choices behind a l33t match.
+func l33tVariations(m Match) float64 {
+	if !m.L33t {
+		return 1
+	}
+
+	counts := make(map[rune]int)
+	for _, r := range strings.ToLower(m.Token) {
+		counts[r]++
+	}
+
+	result := 1.0
+	for sub, letters := range l33tTable {
+		subbed := counts[sub]
+		if subbed == 0 {
+			continue
+		}
+		unsubbed := 0
+		for _, letter := range letters {
+			unsubbed = max(unsubbed, counts[letter])
+		}
+		if unsubbed == 0 {
+			result *= 2
+			continue
+		}
+		result *= variations(subbed, unsubbed)
+	}
+
+	return result
+}
+
+// variations sums C(a+b, i) for i from 1 to min(a, b).
+func variations(a, b int) float64 {
+	total := 0.0
+	for i := 1; i <= min(a, b); i++ {
+		total += binomial(a+b, i)
+	}
+
+	return math.Max(total, 1)
+}
+
+func spatialGuesses(m Match) float64 {
+	length := len([]rune(m.Token))
+
+	g := 0.0
+	for i := 2; i <= length; i++ {
+		for j := 1; j <= min(m.Turns, i-1); j++ {
+			g += binomial(i-1, j-1) * keyboardStarts * math.Pow(keyboardDegree, float64(j))
+		}
+	}
+
+	var shifted, unshifted int
+	for _, r := range m.Token {
+		if keyPositions[r].shifted {
+			shifted++
+		} else {
+			unshifted++
+		}
+	}
+	switch {
+	case shifted == 0:
+	case unshifted == 0:
+		g *= 2
+	default:
+		g *= variations(shifted, unshifted)
+	}
+
+	return g
+}
+
+func sequenceGuesses(token string) float64 {
+	runes := []rune(token)
+
+	var base float64
+	switch first := runes[0]; {
+	case strings.ContainsRune("aAzZ019", first):
+		base = 4
+	case unicode.IsDigit(first):
+		base = 10
+	default:
+		base = 26
+	}
+
+	if runes[1] < runes[0] {
+		base *= 2
+	}
+
+	return base * float64(len(runes))
+}
+
+func binomial(n, k int) float64 {
+	if k < 0 || k > n {
+		return 0
+	}
+
+	r := 1.0
+	for i := 1; i <= k; i++ {
+		r *= float64(n-k+i) / float64(i)
+	}
+
+	return r
+}
+
+// step is the best decomposition of a password prefix into a given number of matches.
+type step struct {
+	match Match
+	pi    float64
+	g     float64
+}
+
+// mostGuessable finds the decomposition of password into matches and
+// bruteforce gaps with the fewest total guesses, in the manner of zxcvbn.
+func (e *Estimator) mostGuessable(password []rune, matches []Match) Result {
+	n := len(password)
+	if n == 0 {
+		return Result{Guesses: 1}
+	}
+
+	byEnd := make([][]Match, n)
+	for _, m := range matches {
+		byEnd[m.J] = append(byEnd[m.J], m)
+	}
+
+	optimal := make([]map[int]step, n)
+	for k := range optimal {
+		optimal[k] = make(map[int]step)
+	}
+
+	update := func(m Match, l int) {
+		k := m.J
+		pi := e.guesses(m, n)
+		if l > 1 {
+			pi *= optimal[m.I-1][l-1].pi
+		}
+		g := factorial(l)*pi + math.Pow(growthPenalty, float64(l-1))
+
+		for other, s := range optimal[k] {
+			if other <= l && s.g <= g {
+				return
+			}
+		}
+
+		m.Guesses = e.guesses(m, n)
+		optimal[k][l] = step{match: m, pi: pi, g: g}
+	}
+
+	bruteforce := func(i, j int) Match {
+		return Match{Pattern: PatternBruteforce, I: i, J: j, Token: string(password[i : j+1])}
+	}
+
+	for k := 0; k < n; k++ {
+		for _, m := range byEnd[k] {
+			if m.I == 0 {
+				update(m, 1)
+				continue
+			}
+			for l := range optimal[m.I-1] {
+				update(m, l+1)
+			}
+		}
+
+		update(bruteforce(0, k), 1)
+		for i := 1; i <= k; i++ {
+			for l, s := range optimal[i-1] {
+				if s.match.Pattern == PatternBruteforce {
+					continue
+				}
+				update(bruteforce(i, k), l+1)
+			}
+		}
+	}
+
+	bestL, best := 0, math.Inf(1)
+	for l, s := range optimal[n-1] {
+		if s.g < best || s.g == best && l < bestL {
+			bestL, best = l, s.g
+		}
+	}
+
+	sequence := make([]Match, bestL)
+	for k, l := n-1, bestL; l > 0; l-- {
+		s := optimal[k][l]
+		sequence[l-1] = s.match
+		k = s.match.I - 1
+	}
+
+	return Result{Guesses: best, Sequence: sequence}
+}
+
+func factorial(n int) float64 {
+	f := 1.0
+	for i := 2; i <= n; i++ {
+		f *= float64(i)
+	}
+
+	return f
+}
@@ -0,0 +1,92 @@
+package strength
+
+import "math"
+
+// keyboardRows describes a slanted QWERTY layout: each row's unshifted and
+// shifted characters and the horizontal offset of its first key.
+var keyboardRows = []struct {
+	lower, upper string
+	offset       float64
+}{
+	{"`1234567890-=", "~!@#$%^&*()_+", 0},
+	{"qwertyuiop[]\\", "QWERTYUIOP{}|", 1.5},
+	{"asdfghjkl;'", "ASDFGHJKL:\"", 1.75},
+	{"zxcvbnm,./", "ZXCVBNM<>?", 2.25},
+}
+
+type keyPosition struct {
+	row     int
+	x       float64
+	shifted bool
+}
+
+var (
+	keyPositions = buildKeyPositions()
+	// keyboardStarts and keyboardDegree feed the spatial guess estimate:
+	// the number of keys and the average number of neighbors per key.
+	keyboardStarts, keyboardDegree = keyboardStats()
+)
+
+func buildKeyPositions() map[rune]keyPosition {
+	positions := make(map[rune]keyPosition)
+	for r, row := range keyboardRows {
+		lower, upper := []rune(row.lower), []rune(row.upper)
+		for i := range lower {
+			x := row.offset + float64(i)
+			positions[lower[i]] = keyPosition{row: r, x: x}
+			positions[upper[i]] = keyPosition{row: r, x: x, shifted: true}
+		}
+	}
+
+	return positions
+}
+
+// direction returns a non-zero identifier for the step from a to b when the
+// keys are adjacent, and zero otherwise.
+func direction(a, b rune) int {
+	pa, ok := keyPositions[a]
+	if !ok {
+		return 0
+	}
+	pb, ok := keyPositions[b]
+	if !ok {
+		return 0
+	}
+
+	dr := pb.row - pa.row
+	dx := pb.x - pa.x
+
+	switch {
+	case dr == 0 && dx == 1:
+		return 1
+	case dr == 0 && dx == -1:
+		return 2
+	case dr == -1 && dx < 0 && dx >= -0.75:
+		return 3
+	case dr == -1 && dx > 0 && dx <= 0.75:
+		return 4
+	case dr == 1 && dx < 0 && dx >= -0.75:
+		return 5
+	case dr == 1 && dx > 0 && dx <= 0.75:
+		return 6
+	}
+
+	return 0
+}
+
+func keyboardStats() (float64, float64) {
+	var keys, edges int
+	for a, pa := range keyPositions {
+		if pa.shifted {
+			continue
+		}
+		keys++
+		for b, pb := range keyPositions {
+			if !pb.shifted && direction(a, b) != 0 {
+				edges++
+			}
+		}
+	}
+
+	return float64(keys), math.Max(float64(edges)/float64(keys), 1)
+}
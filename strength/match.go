@@ -0,0 +1,383 @@
+package strength
+
+import (
+	"slices"
+	"strconv"
+	"strings"
+	"unicode"
+)
+
+// Pattern classifies a Match.
+type Pattern string
+
+const (
+	PatternDictionary Pattern = "dictionary"
+	PatternSpatial    Pattern = "spatial"
+	PatternRepeat     Pattern = "repeat"
+	PatternSequence   Pattern = "sequence"
+	PatternDate       Pattern = "date"
+	PatternYear       Pattern = "year"
+	PatternBruteforce Pattern = "bruteforce"
+)
+
+// Match is one guessable piece of a password, covering runes I through J
+// inclusive.
+type Match struct {
+	Pattern Pattern
+	I, J    int
+	Token   string
+	Guesses float64
+
+	// Dictionary matches.
+	Dictionary string
+	Rank       int
+	Reversed   bool
+	L33t       bool
+
+	// Spatial matches.
+	Turns int
+
+	// Repeat matches.
+	BaseToken string
+	Repeats   int
+
+	// Date and year matches.
+	Year int
+}
+
+// omnimatch runs every matcher over password.
+func (e *Estimator) omnimatch(password []rune, dictionaries map[string]map[string]int) []Match {
+	var matches []Match
+	matches = append(matches, dictionaryMatches(password, dictionaries)...)
+	matches = append(matches, reverseDictionaryMatches(password, dictionaries)...)
+	matches = append(matches, l33tMatches(password, dictionaries)...)
+	matches = append(matches, spatialMatches(password)...)
+	matches = append(matches, e.repeatMatches(password, dictionaries)...)
+	matches = append(matches, sequenceMatches(password)...)
+	matches = append(matches, dateMatches(password)...)
+
+	return matches
+}
+
+func dictionaryMatches(password []rune, dictionaries map[string]map[string]int) []Match {
+	lower := []rune(strings.ToLower(string(password)))
+	if len(lower) != len(password) {
+		return nil
+	}
+
+	var matches []Match
+	for name, ranks := range dictionaries {
+		for i := range lower {
+			for j := i + 1; j < len(lower); j++ {
+				word := string(lower[i : j+1])
+				if r, ok := ranks[word]; ok {
+					matches = append(matches, Match{
+						Pattern:    PatternDictionary,
+						I:          i,
+						J:          j,
+						Token:      string(password[i : j+1]),
+						Dictionary: name,
+						Rank:       r,
+					})
+				}
+			}
+		}
+	}
+
+	return matches
+}
+
+func reverseDictionaryMatches(password []rune, dictionaries map[string]map[string]int) []Match {
+	reversed := slices.Clone(password)
+	slices.Reverse(reversed)
+
+	var matches []Match
+	for _, m := range dictionaryMatches(reversed, dictionaries) {
+		n := len(password)
+		m.I, m.J = n-1-m.J, n-1-m.I
+		m.Token = string(password[m.I : m.J+1])
+		m.Reversed = true
+		matches = append(matches, m)
+	}
+
+	return matches
+}
+
+// l33tMatches de-substitutes password under every consistent mapping of its
+// l33t characters and keeps dictionary matches that relied on a substitution.
+func l33tMatches(password []rune, dictionaries map[string]map[string]int) []Match {
+	var subs []rune
+	for _, r := range password {
+		if _, ok := l33tTable[r]; ok && !slices.Contains(subs, r) {
+			subs = append(subs, r)
+		}
+	}
+	if len(subs) == 0 || len(subs) > 8 {
+		return nil
+	}
+
+	var matches []Match
+	var walk func(k int, mapping map[rune]rune)
+	walk = func(k int, mapping map[rune]rune) {
+		if k < len(subs) {
+			for _, letter := range l33tTable[subs[k]] {
+				mapping[subs[k]] = letter
+				walk(k+1, mapping)
+			}
+			return
+		}
+
+		translated := slices.Clone(password)
+		for i, r := range translated {
+			if letter, ok := mapping[r]; ok {
+				translated[i] = letter
+			}
+		}
+
+		for _, m := range dictionaryMatches(translated, dictionaries) {
+			token := password[m.I : m.J+1]
+			if m.I == m.J || !slices.ContainsFunc(token, func(r rune) bool { _, ok := mapping[r]; return ok }) {
+				continue
+			}
+			m.Token = string(token)
+			m.L33t = true
+			matches = append(matches, m)
+		}
+	}
+	walk(0, make(map[rune]rune))
+
+	return matches
+}
+
+func spatialMatches(password []rune) []Match {
+	var matches []Match
+	for i := 0; i < len(password)-2; {
+		j, turns, last := i, 0, 0
+		for j+1 < len(password) {
+			d := direction(password[j], password[j+1])
+			if d == 0 {
+				break
+			}
+			if d != last {
+				turns++
+				last = d
+			}
+			j++
+		}
+
+		if j-i+1 >= 3 {
+			matches = append(matches, Match{
+				Pattern: PatternSpatial,
+				I:       i,
+				J:       j,
+				Token:   string(password[i : j+1]),
+				Turns:   turns,
+			})
+			i = j
+			continue
+		}
+		i++
+	}
+
+	return matches
+}
+
+// repeatMatches finds runs made of one base token repeated, such as "aaa"
+// or "abcabc", preferring the longest run at each position.
+func (e *Estimator) repeatMatches(password []rune, dictionaries map[string]map[string]int) []Match {
+	var matches []Match
+	for i := 0; i < len(password); {
+		bestEnd, bestBase := -1, 0
+		for base := 1; i+2*base <= len(password); base++ {
+			end := i + base
+			for end+base <= len(password) && slices.Equal(password[end:end+base], password[i:i+base]) {
+				end += base
+			}
+			if end-i >= 2*base && end-1 > bestEnd {
+				bestEnd, bestBase = end-1, base
+			}
+		}
+
+		if bestEnd < 0 {
+			i++
+			continue
+		}
+
+		base := password[i : i+bestBase]
+		repeats := (bestEnd - i + 1) / bestBase
+		baseGuesses := e.mostGuessable(base, e.omnimatch(base, dictionaries)).Guesses
+		matches = append(matches, Match{
+			Pattern:   PatternRepeat,
+			I:         i,
+			J:         bestEnd,
+			Token:     string(password[i : bestEnd+1]),
+			BaseToken: string(base),
+			Repeats:   repeats,
+			Guesses:   baseGuesses * float64(repeats),
+		})
+		i = bestEnd + 1
+	}
+
+	return matches
+}
+
+// sequenceMatches finds runs of at least three characters of one class
+// whose code points step by a constant delta of 1 to 5, such as "abc",
+// "2468", or "zyx".
+func sequenceMatches(password []rune) []Match {
+	var matches []Match
+	for i := 0; i < len(password)-2; {
+		delta := password[i+1] - password[i]
+		if delta == 0 || delta > 5 || delta < -5 || charClass(password[i]) == 0 || charClass(password[i]) != charClass(password[i+1]) {
+			i++
+			continue
+		}
+
+		j := i + 1
+		for j+1 < len(password) && password[j+1]-password[j] == delta && charClass(password[j+1]) == charClass(password[i]) {
+			j++
+		}
+
+		if j-i+1 >= 3 {
+			matches = append(matches, Match{
+				Pattern: PatternSequence,
+				I:       i,
+				J:       j,
+				Token:   string(password[i : j+1]),
+			})
+			i = j
+			continue
+		}
+		i++
+	}
+
+	return matches
+}
+
+func charClass(r rune) int {
+	switch {
+	case unicode.IsLower(r):
+		return 1
+	case unicode.IsUpper(r):
+		return 2
+	case unicode.IsDigit(r):
+		return 3
+	}
+
+	return 0
+}
+
+// dateMatches finds four-digit years and day-month-year dates written with
+// or without a consistent separator.
+func dateMatches(password []rune) []Match {
+	var matches []Match
+	for i := range password {
+		for j := i + 3; j < len(password) && j-i < 10; j++ {
+			token := string(password[i : j+1])
+
+			if j-i == 3 {
+				if y, err := strconv.Atoi(token); err == nil && y >= 1900 && y <= 2050 {
+					matches = append(matches, Match{Pattern: PatternYear, I: i, J: j, Token: token, Year: y})
+				}
+			}
+
+			if year, ok := parseDate(token); ok {
+				matches = append(matches, Match{Pattern: PatternDate, I: i, J: j, Token: token, Year: year})
+			}
+		}
+	}
+
+	return matches
+}
+
+// parseDate reports the year of token if it reads as a valid date in
+// day-month-year, month-day-year, or year-month-day order.
+func parseDate(token string) (int, bool) {
+	if sep := strings.IndexAny(token, "/-._ "); sep >= 0 {
+		parts := strings.Split(token, string(token[sep]))
+		if len(parts) != 3 || !allDigits(strings.Join(parts, "")) {
+			return 0, false
+		}
+		return validDate(parts)
+	}
+
+	if len(token) < 4 || len(token) > 8 || !allDigits(token) {
+		return 0, false
+	}
+
+	for _, parts := range splitCandidates(token) {
+		if year, ok := validDate(parts); ok {
+			return year, true
+		}
+	}
+
+	return 0, false
+}
+
+func allDigits(s string) bool {
+	return s != "" && strings.Trim(s, "0123456789") == ""
+}
+
+// splitCandidates enumerates the ways to cut a separator-free digit string
+// into three date parts with a two- or four-digit year at either end.
+func splitCandidates(token string) [][]string {
+	var out [][]string
+	n := len(token)
+	for _, ylen := range []int{4, 2} {
+		if n-ylen < 2 || n-ylen > 4 {
+			continue
+		}
+		// Year last, then year first.
+		rest := token[:n-ylen]
+		for cut := 1; cut < len(rest); cut++ {
+			out = append(out, []string{rest[:cut], rest[cut:], token[n-ylen:]})
+		}
+		rest = token[ylen:]
+		for cut := 1; cut < len(rest); cut++ {
+			out = append(out, []string{token[:ylen], rest[:cut], rest[cut:]})
+		}
+	}
+
+	return out
+}
+
+// validDate interprets parts as a date with the year first or last.
+func validDate(parts []string) (int, bool) {
+	nums := make([]int, 3)
+	for i, p := range parts {
+		if p == "" || len(p) > 4 {
+			return 0, false
+		}
+		n, err := strconv.Atoi(p)
+		if err != nil {
+			return 0, false
+		}
+		nums[i] = n
+	}
+
+	orders := [][3]int{{2, 0, 1}, {2, 1, 0}, {0, 1, 2}} // year, month, day indexes
+	for _, o := range orders {
+		year, month, day := nums[o[0]], nums[o[1]], nums[o[2]]
+		if len(parts[o[0]]) == 2 {
+			year = twoDigitYear(year)
+		} else if len(parts[o[0]]) != 4 {
+			continue
+		}
+		if len(parts[o[1]]) > 2 || len(parts[o[2]]) > 2 {
+			continue
+		}
+		if year >= 1900 && year <= 2050 && month >= 1 && month <= 12 && day >= 1 && day <= 31 {
+			return year, true
+		}
+	}
+
+	return 0, false
+}
+
+func twoDigitYear(y int) int {
+	if y > 50 {
+		return 1900 + y
+	}
+
+	return 2000 + y
+}
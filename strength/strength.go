@@ -0,0 +1,284 @@
+// Package strength estimates how hard a password is to guess, in the manner
+// of zxcvbn: it splits the password into dictionary words, keyboard walks,
+// repeats, sequences, dates and l33t substitutions, finds the cheapest way
+// for an attacker to enumerate them, and reports a 0-4 score, the estimated
+// number of guesses, and English feedback.
+//
+// Crack-time estimates are tied to the Argon2id cost the password will be
+// stored under. Offline attackers are described by their Argon2 throughput,
+// so raising the policy from PolicyInteractive to PolicySensitive lengthens
+// the offline estimates by the same factor it slows down each guess.
+package strength
+
+import (
+	"errors"
+	"fmt"
+	"math"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/allisson/go-pwdhash"
+	"github.com/allisson/go-pwdhash/argon2"
+)
+
+// MaxLength is the number of runes analyzed; the rest of a longer password
+// is ignored, which only underestimates its strength.
+const MaxLength = 100
+
+// ErrTooWeak indicates that a password scored below the Validator minimum.
+var ErrTooWeak = errors.New("password is too easy to guess")
+
+// Score thresholds, in guesses.
+var scoreThresholds = []float64{1e3, 1e6, 1e8, 1e10}
+
+// Attacker describes how fast an adversary can try guesses.
+type Attacker struct {
+	// Name identifies the scenario in CrackTime.Scenario.
+	Name string
+	// GuessesPerSecond is a fixed guess rate, for attackers limited by the
+	// login endpoint rather than by the hash.
+	GuessesPerSecond float64
+	// Throughput, when positive, is the attacker's Argon2 throughput in KiB of
+	// memory filled per second. Their guess rate is then Throughput divided by
+	// Memory × Iterations of the configured cost.
+	Throughput float64
+}
+
+// Default attacker scenarios. The offline throughput corresponds to roughly
+// a thousand Argon2id guesses per second at PolicyInteractive on one
+// high-end GPU.
+var (
+	OnlineThrottled   = Attacker{Name: "online_throttled", GuessesPerSecond: 100.0 / 3600}
+	OnlineUnthrottled = Attacker{Name: "online_unthrottled", GuessesPerSecond: 10}
+	OfflineGPU        = Attacker{Name: "offline_gpu", Throughput: 2e8}
+	OfflineCluster    = Attacker{Name: "offline_cluster", Throughput: 2e11}
+)
+
+// CrackTime is the expected time for one attacker to find the password.
+type CrackTime struct {
+	Scenario string
+	Seconds  float64
+	Display  string
+}
+
+// Feedback explains a weak score. Both fields are empty for strong passwords.
+type Feedback struct {
+	Warning     string
+	Suggestions []string
+}
+
+// Result is the outcome of Estimate.
+type Result struct {
+	// Score is 0 (guessable within a thousand attempts) to 4 (more than ten
+	// billion).
+	Score        int
+	Guesses      float64
+	GuessesLog10 float64
+	CrackTimes   []CrackTime
+	Feedback     Feedback
+	// Sequence is the cheapest decomposition of the password found.
+	Sequence []Match
+}
+
+// Option configures an Estimator.
+type Option func(*Estimator)
+
+// WithPolicy sets the hashing cost to the preset used by pwdhash.WithPolicy.
+func WithPolicy(p pwdhash.Policy) Option {
+	return func(e *Estimator) {
+		params, err := argon2.ParamsForPolicy(int(p))
+		if err != nil {
+			panic(err) // invalid policy is a programming bug
+		}
+
+		e.params = params
+	}
+}
+
+// WithParams sets the hashing cost for a custom Argon2 configuration.
+func WithParams(params argon2.PolicyParams) Option {
+	return func(e *Estimator) {
+		e.params = params
+	}
+}
+
+// WithUserInputs adds words, such as the site name, that every estimate
+// treats as known to the attacker.
+func WithUserInputs(words ...string) Option {
+	return func(e *Estimator) {
+		e.userInputs = append(e.userInputs, words...)
+	}
+}
+
+// WithAttackers replaces the default attacker scenarios.
+func WithAttackers(attackers ...Attacker) Option {
+	return func(e *Estimator) {
+		e.attackers = attackers
+	}
+}
+
+// Estimator scores passwords. It is safe for concurrent use.
+type Estimator struct {
+	params     argon2.PolicyParams
+	attackers  []Attacker
+	userInputs []string
+	now        func() time.Time
+}
+
+// New returns an Estimator for PolicyInteractive hashing cost and the
+// default attackers.
+func New(opts ...Option) *Estimator {
+	e := &Estimator{
+		attackers: []Attacker{OnlineThrottled, OnlineUnthrottled, OfflineGPU, OfflineCluster},
+		now:       time.Now,
+	}
+	WithPolicy(pwdhash.PolicyInteractive)(e)
+
+	for _, opt := range opts {
+		opt(e)
+	}
+
+	return e
+}
+
+// Estimate scores password. userInputs are per-user words, such as the
+// username or email, that the attacker is assumed to know.
+func (e *Estimator) Estimate(password string, userInputs ...string) Result {
+	runes := []rune(password)
+	if len(runes) > MaxLength {
+		runes = runes[:MaxLength]
+	}
+
+	builtin := rankedDictionaries()
+	dictionaries := builtin
+	if inputs := e.inputs(userInputs); len(inputs) > 0 {
+		dictionaries = make(map[string]map[string]int, len(builtin)+1)
+		for name, ranks := range builtin {
+			dictionaries[name] = ranks
+		}
+		dictionaries[DictionaryUserInputs] = rank(inputs)
+	}
+
+	result := e.mostGuessable(runes, e.omnimatch(runes, dictionaries))
+	result.GuessesLog10 = math.Log10(result.Guesses)
+	result.Score = score(result.Guesses)
+	result.CrackTimes = e.crackTimes(result.Guesses)
+	result.Feedback = feedback(result.Score, result.Sequence)
+
+	return result
+}
+
+func (e *Estimator) inputs(extra []string) []string {
+	var inputs []string
+	for _, w := range slices.Concat(e.userInputs, extra) {
+		w = strings.ToLower(w)
+		if w != "" {
+			inputs = append(inputs, w)
+		}
+		inputs = append(inputs, strings.FieldsFunc(w, isSeparator)...)
+	}
+
+	return inputs
+}
+
+func isSeparator(r rune) bool {
+	return strings.ContainsRune(" @._-+", r)
+}
+
+func score(guesses float64) int {
+	for i, t := range scoreThresholds {
+		if guesses < t+5 {
+			return i
+		}
+	}
+
+	return len(scoreThresholds)
+}
+
+// crackTimes converts an average-case guess count, half the search space,
+// into a time for each attacker.
+func (e *Estimator) crackTimes(guesses float64) []CrackTime {
+	cost := float64(e.params.Memory) * float64(e.params.Iterations)
+
+	times := make([]CrackTime, 0, len(e.attackers))
+	for _, a := range e.attackers {
+		rate := a.GuessesPerSecond
+		if a.Throughput > 0 && cost > 0 {
+			rate = a.Throughput / cost
+		}
+
+		seconds := math.Inf(1)
+		if rate > 0 {
+			seconds = guesses / 2 / rate
+		}
+		times = append(times, CrackTime{Scenario: a.Name, Seconds: seconds, Display: display(seconds)})
+	}
+
+	return times
+}
+
+// display renders seconds as a rough English duration.
+func display(seconds float64) string {
+	units := []struct {
+		name    string
+		seconds float64
+	}{
+		{"second", 1},
+		{"minute", 60},
+		{"hour", 3600},
+		{"day", 86400},
+		{"month", 86400 * 31},
+		{"year", 86400 * 365},
+	}
+
+	if seconds < 1 {
+		return "less than a second"
+	}
+	if seconds >= 100*units[len(units)-1].seconds {
+		return "centuries"
+	}
+
+	u := units[0]
+	for _, next := range units[1:] {
+		if seconds < next.seconds {
+			break
+		}
+		u = next
+	}
+
+	n := int(math.Round(seconds / u.seconds))
+	if n == 1 {
+		return fmt.Sprintf("1 %s", u.name)
+	}
+
+	return fmt.Sprintf("%d %ss", n, u.name)
+}
+
+// Validator adapts an Estimator to the pwdhash.Validator interface, so
+// pwdhash.WithValidator refuses passwords scoring below a minimum.
+type Validator struct {
+	estimator *Estimator
+	minScore  int
+}
+
+// NewValidator returns a Validator that rejects passwords scoring below
+// minScore under estimator.
+func NewValidator(estimator *Estimator, minScore int) *Validator {
+	return &Validator{estimator: estimator, minScore: minScore}
+}
+
+// Validate returns an error wrapping ErrTooWeak, with the feedback warning
+// when there is one, if password scores below the minimum.
+func (v *Validator) Validate(password []byte) error {
+	result := v.estimator.Estimate(string(password))
+	if result.Score >= v.minScore {
+		return nil
+	}
+
+	if result.Feedback.Warning != "" {
+		return fmt.Errorf("%w: %s", ErrTooWeak, result.Feedback.Warning)
+	}
+
+	return ErrTooWeak
+}
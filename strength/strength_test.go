@@ -0,0 +1,207 @@
+package strength
+
+import (
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/allisson/go-pwdhash"
+	"github.com/allisson/go-pwdhash/argon2"
+)
+
+func newTestEstimator(opts ...Option) *Estimator {
+	e := New(opts...)
+	e.now = func() time.Time { return time.Date(2026, 1, 1, 0, 0, 0, 0, time.UTC) }
+	return e
+}
+
+func TestEstimator_Patterns(t *testing.T) {
+	e := newTestEstimator()
+
+	tests := []struct {
+		password string
+		pattern  Pattern
+		maxScore int
+	}{
+		{"password", PatternDictionary, 0},
+		{"drowssap", PatternDictionary, 0},
+		{"P@ssw0rd", PatternDictionary, 0},
+		{"Sunshine", PatternDictionary, 0},
+		{"zxcvbnm", PatternDictionary, 0},
+		{"wsxcde34", PatternSpatial, 2},
+		{"aaaaaaaa", PatternRepeat, 0},
+		{"abcabcabc", PatternRepeat, 0},
+		{"abcdefg", PatternSequence, 0},
+		{"97531", PatternSequence, 0},
+		{"1987", PatternYear, 0},
+		{"12/05/1987", PatternDate, 1},
+		{"19870512", PatternDate, 1},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.password, func(t *testing.T) {
+			r := e.Estimate(tt.password)
+			require.LessOrEqual(t, r.Score, tt.maxScore)
+			require.Len(t, r.Sequence, 1)
+			require.Equal(t, tt.pattern, r.Sequence[0].Pattern)
+			require.Equal(t, tt.password, r.Sequence[0].Token)
+			require.NotEmpty(t, r.Feedback.Warning)
+			require.NotEmpty(t, r.Feedback.Suggestions)
+		})
+	}
+}
+
+func TestEstimator_WellKnownWeakPasswords(t *testing.T) {
+	e := newTestEstimator()
+
+	tests := []struct {
+		password string
+		maxScore int
+	}{
+		{"liverpool", 0},
+		{"baseball", 0},
+		{"iloveyou", 0},
+		{"password1", 0},
+		{"P@ssw0rd", 0},
+		{"pokemon123", 1},
+		{"Passw0rd!", 1},
+		{"Football1", 1},
+		{"welcome123", 1},
+		{"Qwerty123!", 1},
+		{"Dragon2020", 1},
+		{"monkey123", 1},
+		{"smith1990", 1},
+		{"Michael!", 1},
+		{"Tr0ub4dour&3", 2},
+		{"Summer2024!", 2},
+	}
+
+	for _, tt := range tests {
+		r := e.Estimate(tt.password)
+		require.LessOrEqual(t, r.Score, tt.maxScore, tt.password)
+	}
+}
+
+func TestEstimator_StrongPasswords(t *testing.T) {
+	e := newTestEstimator()
+
+	for _, password := range []string{
+		"correct horse battery staple",
+		"x7$Kq!p2Lm#9vR",
+		"purple-monkey-dishwasher-42",
+	} {
+		r := e.Estimate(password)
+		require.Equal(t, 4, r.Score, password)
+		require.Empty(t, r.Feedback.Warning, password)
+		require.Empty(t, r.Feedback.Suggestions, password)
+	}
+}
+
+func TestEstimator_Decomposition(t *testing.T) {
+	r := newTestEstimator().Estimate("jennifer1987")
+
+	require.Len(t, r.Sequence, 2)
+	require.Equal(t, PatternDictionary, r.Sequence[0].Pattern)
+	require.Equal(t, PatternYear, r.Sequence[1].Pattern)
+	require.Equal(t, 1987, r.Sequence[1].Year)
+	require.InDelta(t, r.GuessesLog10, 4.18, 0.5)
+}
+
+func TestEstimator_UserInputs(t *testing.T) {
+	e := newTestEstimator(WithUserInputs("ExampleCorp"))
+
+	without := e.Estimate("zebulonquark")
+	with := e.Estimate("zebulonquark", "zebulon.quark@mail.test")
+	require.Less(t, with.Guesses, without.Guesses)
+	require.Equal(t, DictionaryUserInputs, with.Sequence[0].Dictionary)
+
+	r := e.Estimate("examplecorp")
+	require.Equal(t, 0, r.Score)
+	require.Equal(t, DictionaryUserInputs, r.Sequence[0].Dictionary)
+}
+
+func TestEstimator_CrackTimesFollowPolicy(t *testing.T) {
+	interactive := newTestEstimator().Estimate("jennifer1987")
+	sensitive := newTestEstimator(WithPolicy(pwdhash.PolicySensitive)).Estimate("jennifer1987")
+
+	require.Equal(t, interactive.Guesses, sensitive.Guesses)
+	require.Len(t, sensitive.CrackTimes, 4)
+
+	for i, ct := range sensitive.CrackTimes {
+		base := interactive.CrackTimes[i]
+		require.Equal(t, base.Scenario, ct.Scenario)
+		if strings.HasPrefix(ct.Scenario, "offline") {
+			// 256 MiB × 5 passes against 64 MiB × 3 passes.
+			require.InDelta(t, 20.0/3, ct.Seconds/base.Seconds, 1e-9)
+		} else {
+			require.Equal(t, base.Seconds, ct.Seconds)
+		}
+	}
+
+	custom := newTestEstimator(
+		WithParams(argon2.PolicyParams{Memory: 1024, Iterations: 1}),
+		WithAttackers(Attacker{Name: "lab", Throughput: 2048}),
+	).Estimate("jennifer1987")
+	require.Len(t, custom.CrackTimes, 1)
+	require.Equal(t, "lab", custom.CrackTimes[0].Scenario)
+	require.InDelta(t, custom.Guesses/4, custom.CrackTimes[0].Seconds, 1e-9)
+}
+
+func TestEstimator_MaxLength(t *testing.T) {
+	e := newTestEstimator()
+
+	r := e.Estimate(strings.Repeat("a", 10*MaxLength))
+	require.Equal(t, MaxLength-1, r.Sequence[0].J)
+}
+
+func TestEstimator_Empty(t *testing.T) {
+	r := newTestEstimator().Estimate("")
+
+	require.Equal(t, 0, r.Score)
+	require.Equal(t, 1.0, r.Guesses)
+	require.Empty(t, r.Sequence)
+	require.NotEmpty(t, r.Feedback.Suggestions)
+}
+
+func TestWithPolicy_InvalidPanics(t *testing.T) {
+	require.Panics(t, func() { New(WithPolicy(pwdhash.Policy(99))) })
+}
+
+func TestDisplay(t *testing.T) {
+	tests := []struct {
+		seconds float64
+		want    string
+	}{
+		{0.5, "less than a second"},
+		{1, "1 second"},
+		{45, "45 seconds"},
+		{90, "2 minutes"},
+		{3 * 3600, "3 hours"},
+		{86400, "1 day"},
+		{86400 * 62, "2 months"},
+		{86400 * 365 * 12, "12 years"},
+		{86400 * 365 * 500, "centuries"},
+	}
+
+	for _, tt := range tests {
+		require.Equal(t, tt.want, display(tt.seconds), tt.seconds)
+	}
+}
+
+func TestValidator(t *testing.T) {
+	v := NewValidator(newTestEstimator(), 3)
+
+	require.NoError(t, v.Validate([]byte("correct horse battery staple")))
+
+	err := v.Validate([]byte("password1"))
+	require.ErrorIs(t, err, ErrTooWeak)
+	require.Contains(t, err.Error(), "common password")
+
+	hasher, err := pwdhash.New(pwdhash.WithValidator(v))
+	require.NoError(t, err)
+
+	_, err = hasher.Hash([]byte("qwerty123"))
+	require.ErrorIs(t, err, ErrTooWeak)
+}
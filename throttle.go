@@ -0,0 +1,598 @@
+package pwdhash
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"slices"
+	"sync"
+	"time"
+)
+
+// Throttle scopes reported by ThrottledError.
+const (
+	ScopeAccount = "account"
+	ScopeSource  = "source"
+)
+
+// ReservationTimeout bounds how long an attempt reserved by Limiter.Begin
+// counts as pending. Reservations that were never finished, because the
+// process died or the caller forgot, are treated as abandoned after it.
+const ReservationTimeout = time.Minute
+
+// AttemptState is the failure history kept for one throttling key. Pending
+// holds the reservation times of attempts begun by Limiter.Begin that have
+// not finished yet.
+type AttemptState struct {
+	Failures    int
+	LastFailure time.Time
+	Pending     []time.Time
+}
+
+// pending returns how many reservations in s have not been abandoned at now.
+func (s AttemptState) pending(now time.Time) int {
+	n := 0
+	for _, reserved := range s.Pending {
+		if now.Sub(reserved) < ReservationTimeout {
+			n++
+		}
+	}
+
+	return n
+}
+
+// CounterStore keeps attempt counters for Limiter. Reserve, Release, and
+// RecordFailure must each be atomic per key, so that concurrent attempts
+// observe each other's reservations. Reservations older than
+// ReservationTimeout may be dropped at any time.
+type CounterStore interface {
+	// Load returns the state for key, or the zero state if none is recorded.
+	Load(ctx context.Context, key string) (AttemptState, error)
+	// Reserve adds a pending attempt reserved at now and returns the state
+	// before it, first dropping abandoned reservations and forgetting the
+	// failures that limits.Expired reports as expired.
+	Reserve(ctx context.Context, key string, now time.Time, limits ThrottleLimits) (AttemptState, error)
+	// Release removes the pending attempt reserved at reserved without
+	// recording a failure.
+	Release(ctx context.Context, key string, reserved time.Time) error
+	// RecordFailure turns the pending attempt reserved at reserved into a
+	// failure at now, first forgetting expired failures, and returns the new
+	// state.
+	RecordFailure(ctx context.Context, key string, reserved, now time.Time, limits ThrottleLimits) (AttemptState, error)
+	// Reset forgets everything recorded for key, including pending attempts.
+	Reset(ctx context.Context, key string) error
+}
+
+// memorySweepInterval is how often MemoryCounterStore evicts expired keys.
+const memorySweepInterval = time.Minute
+
+// MemoryCounterStore is a CounterStore for a single process. Keys are
+// evicted once their failures have expired and their reservations have been
+// finished or abandoned, so unknown usernames and sources do not accumulate.
+type MemoryCounterStore struct {
+	mu        sync.Mutex
+	states    map[string]memoryEntry
+	lastSweep time.Time
+}
+
+// memoryEntry is a stored state and the time after which it holds nothing.
+type memoryEntry struct {
+	state   AttemptState
+	expires time.Time
+}
+
+// NewMemoryCounterStore returns an empty MemoryCounterStore.
+func NewMemoryCounterStore() *MemoryCounterStore {
+	return &MemoryCounterStore{states: make(map[string]memoryEntry)}
+}
+
+// Load returns the state for key, or the zero state if none is recorded.
+func (m *MemoryCounterStore) Load(_ context.Context, key string) (AttemptState, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	state := m.states[key].state
+	state.Pending = slices.Clone(state.Pending)
+
+	return state, nil
+}
+
+// Reserve adds a pending attempt and returns the state before it.
+func (m *MemoryCounterStore) Reserve(_ context.Context, key string, now time.Time, limits ThrottleLimits) (AttemptState, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	m.sweep(now)
+
+	state := m.states[key].state
+	state.Pending = livePending(state.Pending, now)
+	if limits.Expired(state, now) {
+		state.Failures = 0
+		state.LastFailure = time.Time{}
+	}
+
+	prior := state
+	prior.Pending = slices.Clone(state.Pending)
+
+	state.Pending = append(state.Pending, now)
+	m.put(key, state, limits)
+
+	return prior, nil
+}
+
+// Release removes the pending attempt reserved at reserved.
+func (m *MemoryCounterStore) Release(_ context.Context, key string, reserved time.Time) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	entry, ok := m.states[key]
+	if !ok {
+		return nil
+	}
+
+	entry.state.Pending = removeReservation(entry.state.Pending, reserved)
+	if len(entry.state.Pending) == 0 {
+		entry.state.Pending = nil
+	}
+	if entry.state.Failures == 0 && entry.state.Pending == nil {
+		delete(m.states, key)
+		return nil
+	}
+
+	m.states[key] = entry
+
+	return nil
+}
+
+// RecordFailure turns the pending attempt reserved at reserved into a
+// failure at now.
+func (m *MemoryCounterStore) RecordFailure(_ context.Context, key string, reserved, now time.Time, limits ThrottleLimits) (AttemptState, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	m.sweep(now)
+
+	state := m.states[key].state
+	state.Pending = livePending(removeReservation(state.Pending, reserved), now)
+	if limits.Expired(state, now) {
+		state.Failures = 0
+	}
+
+	state.Failures++
+	state.LastFailure = now
+	m.put(key, state, limits)
+
+	state.Pending = slices.Clone(state.Pending)
+
+	return state, nil
+}
+
+// Reset forgets everything recorded for key.
+func (m *MemoryCounterStore) Reset(_ context.Context, key string) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	delete(m.states, key)
+
+	return nil
+}
+
+// put saves state until its failures expire under limits and its
+// reservations are abandoned, dropping keys that hold nothing.
+func (m *MemoryCounterStore) put(key string, state AttemptState, limits ThrottleLimits) {
+	if len(state.Pending) == 0 {
+		state.Pending = nil
+	}
+	if state.Failures == 0 && state.Pending == nil {
+		delete(m.states, key)
+		return
+	}
+
+	expires := state.LastFailure.Add(max(limits.Window, limits.Lockout))
+	for _, reserved := range state.Pending {
+		if end := reserved.Add(ReservationTimeout); end.After(expires) {
+			expires = end
+		}
+	}
+
+	m.states[key] = memoryEntry{state: state, expires: expires}
+}
+
+// sweep evicts expired keys, at most once per memorySweepInterval.
+func (m *MemoryCounterStore) sweep(now time.Time) {
+	if now.Sub(m.lastSweep) < memorySweepInterval {
+		return
+	}
+
+	for key, entry := range m.states {
+		if !now.Before(entry.expires) {
+			delete(m.states, key)
+		}
+	}
+	m.lastSweep = now
+}
+
+// livePending returns the reservations in pending that have not been
+// abandoned at now.
+func livePending(pending []time.Time, now time.Time) []time.Time {
+	var live []time.Time
+	for _, reserved := range pending {
+		if now.Sub(reserved) < ReservationTimeout {
+			live = append(live, reserved)
+		}
+	}
+
+	return live
+}
+
+// removeReservation returns pending without one reservation made at reserved.
+func removeReservation(pending []time.Time, reserved time.Time) []time.Time {
+	i := slices.IndexFunc(pending, reserved.Equal)
+	if i < 0 {
+		return pending
+	}
+
+	return slices.Delete(slices.Clone(pending), i, i+1)
+}
+
+// ThrottleLimits configures backoff and lockout for one scope.
+//
+// After k consecutive failures the next attempt waits BaseDelay*2^(k-1),
+// capped at MaxDelay. Once MaxFailures is reached attempts are refused for
+// Lockout, even if Lockout is longer than Window. Otherwise failures older
+// than Window are forgotten. A zero MaxFailures disables the lockout.
+type ThrottleLimits struct {
+	BaseDelay   time.Duration
+	MaxDelay    time.Duration
+	MaxFailures int
+	Lockout     time.Duration
+	Window      time.Duration
+}
+
+// Expired reports whether the failures in state are forgotten at now: the
+// last one is older than Window and no lockout is in force.
+func (t ThrottleLimits) Expired(state AttemptState, now time.Time) bool {
+	if _, locked := t.lockedUntil(state, now); locked {
+		return false
+	}
+
+	return now.Sub(state.LastFailure) >= t.Window
+}
+
+// lockedUntil returns the end of the lockout in force at now, if any.
+func (t ThrottleLimits) lockedUntil(state AttemptState, now time.Time) (time.Time, bool) {
+	if t.MaxFailures <= 0 || state.Failures < t.MaxFailures {
+		return time.Time{}, false
+	}
+
+	until := state.LastFailure.Add(t.Lockout)
+
+	return until, now.Before(until)
+}
+
+// ThrottledError reports an attempt refused by Limiter. It matches
+// ErrThrottled under errors.Is.
+type ThrottledError struct {
+	Scope      string
+	RetryAfter time.Duration
+	Locked     bool
+}
+
+// Error implements error.
+func (e *ThrottledError) Error() string {
+	if e.Locked {
+		return fmt.Sprintf("%s locked out, retry after %s", e.Scope, e.RetryAfter)
+	}
+
+	return fmt.Sprintf("%s throttled, retry after %s", e.Scope, e.RetryAfter)
+}
+
+// Is reports whether target is ErrThrottled.
+func (e *ThrottledError) Is(target error) bool {
+	return target == ErrThrottled
+}
+
+// LimiterOption configures a Limiter.
+type LimiterOption func(*Limiter)
+
+// WithAccountLimits overrides the limits applied per user.
+func WithAccountLimits(limits ThrottleLimits) LimiterOption {
+	return func(l *Limiter) {
+		l.account = limits
+	}
+}
+
+// WithSourceLimits overrides the limits applied per source key, such as a client IP.
+func WithSourceLimits(limits ThrottleLimits) LimiterOption {
+	return func(l *Limiter) {
+		l.source = limits
+	}
+}
+
+// Limiter throttles password attempts per account and per source before any
+// hashing work runs.
+//
+// Successful attempts reset the account counter only, so a valid login from
+// a source does not clear failures that source made against other accounts.
+type Limiter struct {
+	store   CounterStore
+	account ThrottleLimits
+	source  ThrottleLimits
+	now     func() time.Time
+}
+
+// NewLimiter returns a Limiter backed by store. By default an account backs
+// off from 1s up to 5m and locks for 15m after 10 failures; a source backs off
+// from 100ms up to 1m and locks for 15m after 100 failures; both forget
+// failures after an hour.
+func NewLimiter(store CounterStore, opts ...LimiterOption) *Limiter {
+	l := &Limiter{
+		store: store,
+		account: ThrottleLimits{
+			BaseDelay:   time.Second,
+			MaxDelay:    5 * time.Minute,
+			MaxFailures: 10,
+			Lockout:     15 * time.Minute,
+			Window:      time.Hour,
+		},
+		source: ThrottleLimits{
+			BaseDelay:   100 * time.Millisecond,
+			MaxDelay:    time.Minute,
+			MaxFailures: 100,
+			Lockout:     15 * time.Minute,
+			Window:      time.Hour,
+		},
+		now: time.Now,
+	}
+
+	for _, opt := range opts {
+		opt(l)
+	}
+
+	return l
+}
+
+// Check reports whether user or source could attempt a login now, returning
+// a *ThrottledError if not. It reserves nothing, so an attempt made after it
+// races with concurrent ones; use Begin, Guard, or Authenticate to enforce
+// the limits. An empty source skips the per-source check.
+func (l *Limiter) Check(ctx context.Context, user, source string) error {
+	now := l.now()
+
+	if err := l.peek(ctx, ScopeAccount, user, l.account, now); err != nil {
+		return err
+	}
+
+	return l.peek(ctx, ScopeSource, source, l.source, now)
+}
+
+// Begin reserves an attempt for user and source, or returns a
+// *ThrottledError if either may not attempt a login now. The reservation is
+// atomic in the CounterStore and counts against the lockout until the
+// attempt finishes or ReservationTimeout passes, so concurrent attempts
+// cannot all pass before their failures are recorded. Finish the returned
+// Attempt with Done or Cancel. An empty source skips the per-source limits.
+func (l *Limiter) Begin(ctx context.Context, user, source string) (*Attempt, error) {
+	now := l.now()
+
+	if err := l.reserve(ctx, ScopeAccount, user, l.account, now); err != nil {
+		return nil, err
+	}
+
+	if err := l.reserve(ctx, ScopeSource, source, l.source, now); err != nil {
+		if releaseErr := l.release(context.WithoutCancel(ctx), ScopeAccount, user, now); releaseErr != nil {
+			return nil, errors.Join(err, releaseErr)
+		}
+		return nil, err
+	}
+
+	return &Attempt{limiter: l, user: user, source: source, reserved: now}, nil
+}
+
+// Attempt is a login attempt reserved by Limiter.Begin. Finish it with
+// exactly one call to Done or Cancel.
+type Attempt struct {
+	limiter  *Limiter
+	user     string
+	source   string
+	reserved time.Time
+}
+
+// Done records the outcome of the attempt: a failure counts against both
+// user and source, and a success resets user. The counters are updated even
+// if ctx has been cancelled, so the reservation is never left behind.
+func (a *Attempt) Done(ctx context.Context, success bool) error {
+	l := a.limiter
+	ctx = context.WithoutCancel(ctx)
+
+	if success {
+		var err error
+		if a.user != "" {
+			err = l.store.Reset(ctx, counterKey(ScopeAccount, a.user))
+		}
+		return errors.Join(err, l.release(ctx, ScopeSource, a.source, a.reserved))
+	}
+
+	now := l.now()
+
+	var errs []error
+	if a.user != "" {
+		_, err := l.store.RecordFailure(ctx, counterKey(ScopeAccount, a.user), a.reserved, now, l.account)
+		errs = append(errs, err)
+	}
+	if a.source != "" {
+		_, err := l.store.RecordFailure(ctx, counterKey(ScopeSource, a.source), a.reserved, now, l.source)
+		errs = append(errs, err)
+	}
+
+	return errors.Join(errs...)
+}
+
+// Cancel releases the attempt without counting it, for attempts that could
+// not be verified. It runs even if ctx has been cancelled.
+func (a *Attempt) Cancel(ctx context.Context) error {
+	ctx = context.WithoutCancel(ctx)
+
+	return errors.Join(
+		a.limiter.release(ctx, ScopeAccount, a.user, a.reserved),
+		a.limiter.release(ctx, ScopeSource, a.source, a.reserved),
+	)
+}
+
+// Guard runs verify, typically a PasswordHasher.Verify call, only if Begin
+// allows the attempt, and records its result. Errors from verify are
+// returned without counting as a failure, and a panic in verify releases the
+// attempt before it propagates.
+func (l *Limiter) Guard(ctx context.Context, user, source string, verify func() (bool, error)) (bool, error) {
+	attempt, err := l.Begin(ctx, user, source)
+	if err != nil {
+		return false, err
+	}
+
+	finished := false
+	defer func() {
+		if !finished {
+			_ = attempt.Cancel(ctx)
+		}
+	}()
+
+	ok, err := verify()
+	finished = true
+	if err != nil {
+		if cancelErr := attempt.Cancel(ctx); cancelErr != nil {
+			return false, errors.Join(err, cancelErr)
+		}
+		return false, err
+	}
+
+	if err := attempt.Done(ctx, ok); err != nil {
+		return ok, err
+	}
+
+	return ok, nil
+}
+
+// Authenticate runs auth.Authenticate behind Begin and records its result.
+// Unknown users count as failures, so throttling does not reveal which
+// accounts exist.
+func (l *Limiter) Authenticate(ctx context.Context, auth *Authenticator, user, source string, password *Secret) (AuthResult, error) {
+	attempt, err := l.Begin(ctx, user, source)
+	if err != nil {
+		return AuthResult{}, err
+	}
+
+	finished := false
+	defer func() {
+		if !finished {
+			_ = attempt.Cancel(ctx)
+		}
+	}()
+
+	result, err := auth.Authenticate(ctx, user, password)
+	finished = true
+	if err != nil {
+		if cancelErr := attempt.Cancel(ctx); cancelErr != nil {
+			return result, errors.Join(err, cancelErr)
+		}
+		return result, err
+	}
+
+	if err := attempt.Done(ctx, result.OK()); err != nil {
+		return result, err
+	}
+
+	return result, nil
+}
+
+func (l *Limiter) peek(ctx context.Context, scope, key string, limits ThrottleLimits, now time.Time) error {
+	if key == "" {
+		return nil
+	}
+
+	state, err := l.store.Load(ctx, counterKey(scope, key))
+	if err != nil {
+		return err
+	}
+
+	return check(scope, limits, state, now)
+}
+
+// reserve adds a pending attempt for key and takes it back if the state
+// before it refuses the attempt.
+func (l *Limiter) reserve(ctx context.Context, scope, key string, limits ThrottleLimits, now time.Time) error {
+	if key == "" {
+		return nil
+	}
+
+	state, err := l.store.Reserve(ctx, counterKey(scope, key), now, limits)
+	if err != nil {
+		return err
+	}
+
+	if err := check(scope, limits, state, now); err != nil {
+		if releaseErr := l.release(context.WithoutCancel(ctx), scope, key, now); releaseErr != nil {
+			return errors.Join(err, releaseErr)
+		}
+		return err
+	}
+
+	return nil
+}
+
+func (l *Limiter) release(ctx context.Context, scope, key string, reserved time.Time) error {
+	if key == "" {
+		return nil
+	}
+
+	return l.store.Release(ctx, counterKey(scope, key), reserved)
+}
+
+// check returns a *ThrottledError if state does not allow another attempt
+// at now. Abandoned reservations are ignored.
+func check(scope string, limits ThrottleLimits, state AttemptState, now time.Time) error {
+	if until, locked := limits.lockedUntil(state, now); locked {
+		return &ThrottledError{Scope: scope, RetryAfter: until.Sub(now), Locked: true}
+	}
+
+	if limits.Expired(state, now) {
+		state.Failures = 0
+	}
+
+	pending := state.pending(now)
+
+	// Attempts still in flight may all fail, so together with the recorded
+	// failures they may not exceed the lockout threshold.
+	if limits.MaxFailures > 0 && pending > 0 && state.Failures+pending >= limits.MaxFailures {
+		return &ThrottledError{Scope: scope, RetryAfter: backoff(limits, state.Failures+pending)}
+	}
+
+	if state.Failures == 0 {
+		return nil
+	}
+
+	delay := backoff(limits, state.Failures)
+	if until := state.LastFailure.Add(delay); now.Before(until) {
+		return &ThrottledError{Scope: scope, RetryAfter: until.Sub(now)}
+	}
+
+	// Once backing off, only one attempt at a time may take the next slot.
+	if pending > 0 && delay > 0 {
+		return &ThrottledError{Scope: scope, RetryAfter: delay}
+	}
+
+	return nil
+}
+
+// backoff returns BaseDelay doubled for every failure after the first, capped at MaxDelay.
+func backoff(limits ThrottleLimits, failures int) time.Duration {
+	delay := limits.BaseDelay
+	for i := 1; i < failures && delay < limits.MaxDelay; i++ {
+		delay *= 2
+	}
+
+	return min(delay, limits.MaxDelay)
+}
+
+func counterKey(scope, key string) string {
+	return scope + ":" + key
+}
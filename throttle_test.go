@@ -0,0 +1,330 @@
+package pwdhash
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+func newTestLimiter(clock *time.Time) *Limiter {
+	limiter := NewLimiter(NewMemoryCounterStore(),
+		WithAccountLimits(ThrottleLimits{
+			BaseDelay:   time.Second,
+			MaxDelay:    4 * time.Second,
+			MaxFailures: 5,
+			Lockout:     time.Minute,
+			Window:      time.Hour,
+		}),
+		WithSourceLimits(ThrottleLimits{
+			MaxFailures: 3,
+			Lockout:     time.Minute,
+			Window:      time.Hour,
+		}),
+	)
+	limiter.now = func() time.Time { return *clock }
+
+	return limiter
+}
+
+func requireThrottled(t *testing.T, err error, scope string, retryAfter time.Duration, locked bool) {
+	t.Helper()
+
+	require.ErrorIs(t, err, ErrThrottled)
+
+	var throttled *ThrottledError
+	require.True(t, errors.As(err, &throttled))
+	require.Equal(t, scope, throttled.Scope)
+	require.Equal(t, retryAfter, throttled.RetryAfter)
+	require.Equal(t, locked, throttled.Locked)
+}
+
+// attempt runs one attempt through Begin and records its outcome.
+func attempt(t *testing.T, limiter *Limiter, user, source string, success bool) {
+	t.Helper()
+
+	a, err := limiter.Begin(context.Background(), user, source)
+	require.NoError(t, err)
+	require.NoError(t, a.Done(context.Background(), success))
+}
+
+func TestLimiter_BackoffAndLockout(t *testing.T) {
+	ctx := context.Background()
+	clock := time.Unix(1_700_000_000, 0)
+	limiter := newTestLimiter(&clock)
+
+	// Delays double from 1s and cap at 4s until the fifth failure locks the account.
+	for _, delay := range []time.Duration{time.Second, 2 * time.Second, 4 * time.Second, 4 * time.Second} {
+		attempt(t, limiter, "alice", "", false)
+
+		requireThrottled(t, limiter.Check(ctx, "alice", ""), ScopeAccount, delay, false)
+		_, err := limiter.Begin(ctx, "alice", "")
+		requireThrottled(t, err, ScopeAccount, delay, false)
+		clock = clock.Add(delay)
+	}
+
+	attempt(t, limiter, "alice", "", false)
+	requireThrottled(t, limiter.Check(ctx, "alice", ""), ScopeAccount, time.Minute, true)
+
+	clock = clock.Add(time.Minute)
+	require.NoError(t, limiter.Check(ctx, "alice", ""))
+
+	attempt(t, limiter, "alice", "", true)
+	attempt(t, limiter, "alice", "", false)
+	requireThrottled(t, limiter.Check(ctx, "alice", ""), ScopeAccount, time.Second, false)
+
+	// Failures outside the window are forgotten.
+	clock = clock.Add(time.Hour)
+	require.NoError(t, limiter.Check(ctx, "alice", ""))
+}
+
+func TestLimiter_LockoutOutlastsWindow(t *testing.T) {
+	ctx := context.Background()
+	clock := time.Unix(1_700_000_000, 0)
+	limiter := NewLimiter(NewMemoryCounterStore(), WithAccountLimits(ThrottleLimits{
+		MaxFailures: 2,
+		Lockout:     time.Hour,
+		Window:      time.Minute,
+	}))
+	limiter.now = func() time.Time { return clock }
+
+	attempt(t, limiter, "alice", "", false)
+	attempt(t, limiter, "alice", "", false)
+
+	clock = clock.Add(2 * time.Minute)
+	requireThrottled(t, limiter.Check(ctx, "alice", ""), ScopeAccount, 58*time.Minute, true)
+	_, err := limiter.Begin(ctx, "alice", "")
+	requireThrottled(t, err, ScopeAccount, 58*time.Minute, true)
+
+	// Once the lockout ends the failures are past the window and forgotten.
+	clock = clock.Add(58 * time.Minute)
+	attempt(t, limiter, "alice", "", false)
+	require.NoError(t, limiter.Check(ctx, "alice", ""))
+}
+
+func TestLimiter_ConcurrentAttempts(t *testing.T) {
+	ctx := context.Background()
+	clock := time.Unix(1_700_000_000, 0)
+	limiter := newTestLimiter(&clock)
+
+	const attempts = 20
+
+	var verified, refused atomic.Int32
+	release := make(chan struct{})
+	errs := make(chan error, attempts)
+
+	for range attempts {
+		go func() {
+			_, err := limiter.Guard(ctx, "alice", "", func() (bool, error) {
+				verified.Add(1)
+				<-release
+				return false, nil
+			})
+			if errors.Is(err, ErrThrottled) {
+				refused.Add(1)
+				err = nil
+			}
+			errs <- err
+		}()
+	}
+
+	// Every attempt is either refused or blocked in verify before any
+	// failure is recorded, so only reservations can hold them back.
+	require.Eventually(t, func() bool {
+		return verified.Load()+refused.Load() == attempts
+	}, 5*time.Second, time.Millisecond)
+	close(release)
+
+	for range attempts {
+		require.NoError(t, <-errs)
+	}
+
+	require.Equal(t, int32(5), verified.Load())
+	requireThrottled(t, limiter.Check(ctx, "alice", ""), ScopeAccount, time.Minute, true)
+
+	state, err := limiter.store.Load(ctx, counterKey(ScopeAccount, "alice"))
+	require.NoError(t, err)
+	require.Equal(t, AttemptState{Failures: 5, LastFailure: clock}, state)
+}
+
+func TestLimiter_SourceScope(t *testing.T) {
+	ctx := context.Background()
+	clock := time.Unix(1_700_000_000, 0)
+	limiter := newTestLimiter(&clock)
+
+	attempt(t, limiter, "alice", "203.0.113.7", false)
+	attempt(t, limiter, "bob", "203.0.113.7", false)
+
+	// A success resets the account, not the source.
+	attempt(t, limiter, "carol", "203.0.113.7", true)
+	attempt(t, limiter, "dave", "203.0.113.7", false)
+
+	requireThrottled(t, limiter.Check(ctx, "eve", "203.0.113.7"), ScopeSource, time.Minute, true)
+	require.NoError(t, limiter.Check(ctx, "eve", "198.51.100.1"))
+
+	// A refused source releases the account it had reserved.
+	_, err := limiter.Begin(ctx, "eve", "203.0.113.7")
+	requireThrottled(t, err, ScopeSource, time.Minute, true)
+	state, err := limiter.store.Load(ctx, counterKey(ScopeAccount, "eve"))
+	require.NoError(t, err)
+	require.Zero(t, state)
+}
+
+func TestLimiter_GuardSkipsWorkWhenThrottled(t *testing.T) {
+	ctx := context.Background()
+	clock := time.Unix(1_700_000_000, 0)
+	limiter := newTestLimiter(&clock)
+
+	calls := 0
+	verify := func() (bool, error) {
+		calls++
+		return false, nil
+	}
+
+	ok, err := limiter.Guard(ctx, "alice", "", verify)
+	require.NoError(t, err)
+	require.False(t, ok)
+
+	_, err = limiter.Guard(ctx, "alice", "", verify)
+	require.ErrorIs(t, err, ErrThrottled)
+	require.Equal(t, 1, calls)
+
+	// Verification errors are not counted as failures.
+	_, err = limiter.Guard(ctx, "bob", "", func() (bool, error) { return false, ErrInvalidHash })
+	require.ErrorIs(t, err, ErrInvalidHash)
+	state, err := limiter.store.Load(ctx, counterKey(ScopeAccount, "bob"))
+	require.NoError(t, err)
+	require.Zero(t, state)
+}
+
+func TestLimiter_Authenticate(t *testing.T) {
+	ctx := context.Background()
+	clock := time.Unix(1_700_000_000, 0)
+	limiter := newTestLimiter(&clock)
+
+	ph, err := New(WithHasher(newTestArgon2()))
+	require.NoError(t, err)
+
+	store := NewMemoryStore()
+	encoded, err := ph.Hash([]byte("password"))
+	require.NoError(t, err)
+	require.NoError(t, store.UpdateHash(ctx, "alice", encoded))
+
+	auth, err := NewAuthenticator(ph, store)
+	require.NoError(t, err)
+
+	result, err := limiter.Authenticate(ctx, auth, "mallory", "", NewSecretString("guess"))
+	require.NoError(t, err)
+	require.Equal(t, OutcomeUnknownUser, result.Outcome)
+	requireThrottled(t, limiter.Check(ctx, "mallory", ""), ScopeAccount, time.Second, false)
+
+	result, err = limiter.Authenticate(ctx, auth, "alice", "", NewSecretString("wrong"))
+	require.NoError(t, err)
+	require.Equal(t, OutcomeInvalidPassword, result.Outcome)
+
+	_, err = limiter.Authenticate(ctx, auth, "alice", "", NewSecretString("password"))
+	require.ErrorIs(t, err, ErrThrottled)
+
+	clock = clock.Add(time.Second)
+	result, err = limiter.Authenticate(ctx, auth, "alice", "", NewSecretString("password"))
+	require.NoError(t, err)
+	require.True(t, result.OK())
+	require.NoError(t, limiter.Check(ctx, "alice", ""))
+}
+
+func TestLimiter_GuardReleasesOnPanic(t *testing.T) {
+	ctx := context.Background()
+	clock := time.Unix(1_700_000_000, 0)
+	limiter := newTestLimiter(&clock)
+
+	require.Panics(t, func() {
+		_, _ = limiter.Guard(ctx, "alice", "203.0.113.7", func() (bool, error) {
+			panic("boom")
+		})
+	})
+
+	for _, key := range []string{counterKey(ScopeAccount, "alice"), counterKey(ScopeSource, "203.0.113.7")} {
+		state, err := limiter.store.Load(ctx, key)
+		require.NoError(t, err)
+		require.Zero(t, state, key)
+	}
+}
+
+func TestLimiter_AbandonedReservationsExpire(t *testing.T) {
+	ctx := context.Background()
+	clock := time.Unix(1_700_000_000, 0)
+	limiter := newTestLimiter(&clock)
+
+	// Reservations that are never finished fill the lockout budget...
+	for range 5 {
+		_, err := limiter.Begin(ctx, "alice", "")
+		require.NoError(t, err)
+	}
+	_, err := limiter.Begin(ctx, "alice", "")
+	require.ErrorIs(t, err, ErrThrottled)
+
+	// ...until they are treated as abandoned.
+	clock = clock.Add(ReservationTimeout)
+	require.NoError(t, limiter.Check(ctx, "alice", ""))
+	attempt(t, limiter, "alice", "", true)
+
+	state, err := limiter.store.Load(ctx, counterKey(ScopeAccount, "alice"))
+	require.NoError(t, err)
+	require.Zero(t, state)
+}
+
+// cancelAwareStore refuses to release reservations once ctx is done, as a
+// networked CounterStore would.
+type cancelAwareStore struct {
+	*MemoryCounterStore
+}
+
+func (s cancelAwareStore) Release(ctx context.Context, key string, reserved time.Time) error {
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+
+	return s.MemoryCounterStore.Release(ctx, key, reserved)
+}
+
+func TestAttempt_CancelAfterContextCancelled(t *testing.T) {
+	store := cancelAwareStore{NewMemoryCounterStore()}
+	limiter := NewLimiter(store)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	a, err := limiter.Begin(ctx, "alice", "203.0.113.7")
+	require.NoError(t, err)
+
+	cancel()
+	require.NoError(t, a.Cancel(ctx))
+	require.Empty(t, store.states)
+}
+
+func TestMemoryCounterStore_EvictsExpiredKeys(t *testing.T) {
+	ctx := context.Background()
+	clock := time.Unix(1_700_000_000, 0)
+	limiter := newTestLimiter(&clock)
+	store := limiter.store.(*MemoryCounterStore)
+
+	for i := range 100 {
+		attempt(t, limiter, fmt.Sprintf("user%d", i), "", false)
+	}
+	require.Len(t, store.states, 100)
+
+	// Failures still inside the window are kept.
+	clock = clock.Add(30 * time.Minute)
+	attempt(t, limiter, "alice", "", false)
+	require.Len(t, store.states, 101)
+
+	clock = clock.Add(45 * time.Minute)
+	attempt(t, limiter, "bob", "", false)
+	require.Len(t, store.states, 2)
+
+	state, err := store.Load(ctx, counterKey(ScopeAccount, "alice"))
+	require.NoError(t, err)
+	require.Equal(t, 1, state.Failures)
+}
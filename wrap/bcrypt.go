@@ -0,0 +1,89 @@
+package wrap
+
+import (
+	"encoding/base64"
+	"strconv"
+	"strings"
+
+	"golang.org/x/crypto/blowfish"
+
+	"github.com/allisson/go-pwdhash/internal/zero"
+)
+
+const (
+	bcryptSaltChars   = 22
+	bcryptDigestChars = 31
+	bcryptMaxKey      = 72
+	bcryptMinCost     = 4
+	bcryptMaxCost     = 16
+)
+
+// bcryptBase64 is the unpadded base64 variant used by bcrypt.
+var bcryptBase64 = base64.NewEncoding("./ABCDEFGHIJKLMNOPQRSTUVWXYZabcdefghijklmnopqrstuvwxyz0123456789").
+	WithPadding(base64.NoPadding)
+
+// bcryptMagic is the plaintext bcrypt encrypts 64 times to produce its digest.
+var bcryptMagic = []byte("OrpheanBeholderScryDoubt")
+
+// bcryptSetting is the variant, cost, and salt of a bcrypt hash.
+type bcryptSetting struct {
+	variant string
+	cost    int
+	salt    string
+}
+
+// splitBcrypt separates $2<v>$<cost>$<salt><digest> into its setting and digest.
+func splitBcrypt(encoded string) (*bcryptSetting, string, error) {
+	parts := strings.Split(encoded, "$")
+	if len(parts) != 4 || parts[0] != "" || !strings.HasPrefix(parts[1], "2") || len(parts[1]) > 2 {
+		return nil, "", errInvalidHash
+	}
+
+	cost, err := strconv.Atoi(parts[2])
+	if err != nil || cost < bcryptMinCost || cost > bcryptMaxCost {
+		return nil, "", errInvalidHash
+	}
+
+	body := parts[3]
+	if len(body) != bcryptSaltChars+bcryptDigestChars {
+		return nil, "", errInvalidHash
+	}
+
+	return &bcryptSetting{variant: parts[1], cost: cost, salt: body[:bcryptSaltChars]}, body[bcryptSaltChars:], nil
+}
+
+// digest runs bcrypt over password with the stored salt and cost and returns
+// the raw 23-byte digest.
+func (s *bcryptSetting) digest(password []byte) ([]byte, error) {
+	salt, err := bcryptBase64.DecodeString(s.salt)
+	if err != nil {
+		return nil, errInvalidHash
+	}
+
+	key := make([]byte, 0, len(password)+1)
+	key = append(key, password...)
+	key = append(key, 0)
+	if len(key) > bcryptMaxKey {
+		key = key[:bcryptMaxKey]
+	}
+	defer zero.Bytes(key)
+
+	c, err := blowfish.NewSaltedCipher(key, salt)
+	if err != nil {
+		return nil, err
+	}
+
+	for range 1 << s.cost {
+		blowfish.ExpandKey(key, c)
+		blowfish.ExpandKey(salt, c)
+	}
+
+	out := append([]byte(nil), bcryptMagic...)
+	for i := 0; i < len(out); i += blowfish.BlockSize {
+		for range 64 {
+			c.Encrypt(out[i:i+blowfish.BlockSize], out[i:i+blowfish.BlockSize])
+		}
+	}
+
+	return out[:len(out)-1], nil
+}
@@ -0,0 +1,209 @@
+// Package wrap upgrades weak legacy hashes to Argon2id without their passwords.
+//
+// Wrap runs Argon2id over the digest of an existing MD5, SHA-1, SHA-256, or
+// bcrypt hash, so a whole table can be hardened offline in bulk. The result is
+// a $argon2id-wrap$ PHC string that records the inner scheme; at login, Verify
+// applies the inner scheme to the password before Argon2id. NeedsRehash always
+// reports true so wrapped hashes are replaced with plain Argon2id on the next
+// successful login.
+package wrap
+
+import (
+	"crypto/md5"  // #nosec G501 -- required to wrap legacy MD5 hashes
+	"crypto/sha1" // #nosec G505 -- required to wrap legacy SHA-1 hashes
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"hash"
+	"strings"
+
+	"github.com/allisson/go-pwdhash/argon2"
+	"github.com/allisson/go-pwdhash/internal/encoding"
+	"github.com/allisson/go-pwdhash/internal/zero"
+)
+
+// Inner names the legacy scheme a wrapped hash was built from.
+type Inner string
+
+const (
+	// InnerMD5 wraps unsalted hex MD5 digests.
+	InnerMD5 Inner = "md5"
+	// InnerSHA1 wraps unsalted hex SHA-1 digests.
+	InnerSHA1 Inner = "sha1"
+	// InnerSHA256 wraps unsalted hex SHA-256 digests.
+	InnerSHA256 Inner = "sha256"
+	// InnerBcrypt wraps $2a$, $2b$, and $2y$ bcrypt hashes.
+	InnerBcrypt Inner = "bcrypt"
+)
+
+// Parameter names recorded alongside the Argon2id cost parameters.
+const (
+	paramInner         = "i"
+	paramBcryptVariant = "bv"
+	paramBcryptCost    = "bc"
+	paramBcryptSalt    = "bs"
+)
+
+var errInvalidHash = errors.New("invalid wrapped hash")
+
+// digests maps the unsalted inner schemes to their hash constructors.
+var digests = map[Inner]func() hash.Hash{
+	InnerMD5:    md5.New,
+	InnerSHA1:   sha1.New,
+	InnerSHA256: sha256.New,
+}
+
+// Wrapper wraps legacy hashes with Argon2id and verifies the result.
+type Wrapper struct {
+	Outer *argon2.Argon2idHasher
+}
+
+// New returns a Wrapper that applies outer over legacy digests.
+func New(outer *argon2.Argon2idHasher) *Wrapper {
+	return &Wrapper{Outer: outer}
+}
+
+// ID reports the PHC algorithm identifier.
+func (w *Wrapper) ID() string {
+	return "argon2id-wrap"
+}
+
+// Wrap derives an Argon2id hash over the digest stored in legacy, which must
+// be in the format named by inner. No password is needed.
+func (w *Wrapper) Wrap(inner Inner, legacy string) (string, error) {
+	value, params, err := unwrapLegacy(inner, legacy)
+	if err != nil {
+		return "", err
+	}
+
+	outer, err := w.outer().Hash(value)
+	if err != nil {
+		return "", err
+	}
+
+	parsed, err := encoding.Parse(outer)
+	if err != nil {
+		return "", err
+	}
+
+	parsed.Algorithm = w.ID()
+	for k, v := range params {
+		parsed.Params[k] = v
+	}
+
+	return parsed.String(), nil
+}
+
+// Verify applies the recorded inner scheme to password and checks the Argon2id layer.
+func (w *Wrapper) Verify(password []byte, encoded string) (bool, error) {
+	defer zero.Bytes(password)
+
+	parsed, err := encoding.Parse(encoded)
+	if err != nil {
+		return false, err
+	}
+
+	if parsed.Algorithm != w.ID() {
+		return false, nil
+	}
+
+	value, err := innerDigest(password, parsed.Params)
+	if err != nil {
+		return false, err
+	}
+
+	for _, k := range []string{paramInner, paramBcryptVariant, paramBcryptCost, paramBcryptSalt} {
+		delete(parsed.Params, k)
+	}
+	parsed.Algorithm = w.Outer.ID()
+
+	return w.outer().Verify(value, parsed.String())
+}
+
+// outer returns a copy of Outer that renders plain PHC strings, whatever
+// Format it was configured with, since the wrapped form is itself PHC. It
+// also drops Normalization: the inner value is a binary digest, not text.
+func (w *Wrapper) outer() *argon2.Argon2idHasher {
+	outer := *w.Outer
+	outer.Format = argon2.FormatPHC
+	outer.Normalization = argon2.NormalizationNone
+
+	return &outer
+}
+
+// NeedsRehash always reports true for wrapped hashes.
+func (w *Wrapper) NeedsRehash(encoded string) (bool, error) {
+	parsed, err := encoding.Parse(encoded)
+	if err != nil {
+		return false, err
+	}
+
+	if _, ok := parsed.Params[paramInner]; !ok {
+		return false, errInvalidHash
+	}
+
+	return true, nil
+}
+
+// unwrapLegacy extracts the raw digest of a legacy hash and the parameters
+// needed to recompute it from a password.
+func unwrapLegacy(inner Inner, legacy string) ([]byte, map[string]string, error) {
+	params := map[string]string{paramInner: string(inner)}
+
+	if inner == InnerBcrypt {
+		setting, digest, err := splitBcrypt(legacy)
+		if err != nil {
+			return nil, nil, err
+		}
+
+		raw, err := bcryptBase64.DecodeString(digest)
+		if err != nil {
+			return nil, nil, errInvalidHash
+		}
+
+		params[paramBcryptVariant] = setting.variant
+		params[paramBcryptCost] = fmt.Sprint(setting.cost)
+		params[paramBcryptSalt] = setting.salt
+
+		return raw, params, nil
+	}
+
+	newHash, ok := digests[inner]
+	if !ok {
+		return nil, nil, fmt.Errorf("unknown inner scheme: %s", inner)
+	}
+
+	raw, err := hex.DecodeString(strings.TrimSpace(legacy))
+	if err != nil || len(raw) != newHash().Size() {
+		return nil, nil, errInvalidHash
+	}
+
+	return raw, params, nil
+}
+
+// innerDigest recomputes the legacy digest of password from the recorded parameters.
+func innerDigest(password []byte, params map[string]string) ([]byte, error) {
+	inner := Inner(params[paramInner])
+
+	if inner == InnerBcrypt {
+		setting, _, err := splitBcrypt(fmt.Sprintf("$%s$%s$%s%s",
+			params[paramBcryptVariant], params[paramBcryptCost], params[paramBcryptSalt],
+			strings.Repeat(".", bcryptDigestChars)))
+		if err != nil {
+			return nil, err
+		}
+
+		return setting.digest(password)
+	}
+
+	newHash, ok := digests[inner]
+	if !ok {
+		return nil, fmt.Errorf("unknown inner scheme: %s", inner)
+	}
+
+	h := newHash()
+	h.Write(password)
+
+	return h.Sum(nil), nil
+}
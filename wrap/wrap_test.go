@@ -0,0 +1,136 @@
+package wrap
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/allisson/go-pwdhash/argon2"
+)
+
+func newTestWrapper() *Wrapper {
+	return New(&argon2.Argon2idHasher{
+		Memory:      argon2.MinMemory,
+		Iterations:  argon2.MinIterations,
+		Parallelism: 2,
+		SaltLength:  16,
+		KeyLength:   32,
+	})
+}
+
+func TestWrapper_WrapAndVerify(t *testing.T) {
+	tests := []struct {
+		name   string
+		inner  Inner
+		legacy string
+	}{
+		{name: "md5", inner: InnerMD5, legacy: "5f4dcc3b5aa765d61d8327deb882cf99"},
+		{name: "md5Uppercase", inner: InnerMD5, legacy: "5F4DCC3B5AA765D61D8327DEB882CF99"},
+		{name: "sha1", inner: InnerSHA1, legacy: "5baa61e4c9b93f3f0682250b6cf8331b7ee68fd8"},
+		{name: "sha256", inner: InnerSHA256, legacy: "5e884898da28047151d0e56f8dc6292773603d0d6aabbdd62a11ef721d1542d8"},
+		{name: "bcrypt", inner: InnerBcrypt, legacy: "$2b$04$p4wSFWnH/3cP53yyCWSnEuxvwVcro/yOPi/Vi6hez5ZvWA8q1d7WO"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			wrapper := newTestWrapper()
+
+			wrapped, err := wrapper.Wrap(tt.inner, tt.legacy)
+			require.NoError(t, err)
+			require.True(t, strings.HasPrefix(wrapped, "$argon2id-wrap$v=19$"))
+			require.Contains(t, wrapped, "i="+string(tt.inner))
+
+			ok, err := wrapper.Verify([]byte("password"), wrapped)
+			require.NoError(t, err)
+			require.True(t, ok)
+
+			ok, err = wrapper.Verify([]byte("wrong"), wrapped)
+			require.NoError(t, err)
+			require.False(t, ok)
+
+			needs, err := wrapper.NeedsRehash(wrapped)
+			require.NoError(t, err)
+			require.True(t, needs)
+		})
+	}
+}
+
+func TestWrapper_WrapErrors(t *testing.T) {
+	tests := []struct {
+		name   string
+		inner  Inner
+		legacy string
+	}{
+		{name: "unknownInner", inner: Inner("crc32"), legacy: "cbf43926"},
+		{name: "wrongDigestLength", inner: InnerSHA1, legacy: "5f4dcc3b5aa765d61d8327deb882cf99"},
+		{name: "notHex", inner: InnerMD5, legacy: "zzzzcc3b5aa765d61d8327deb882cf99"},
+		{name: "bcryptTruncated", inner: InnerBcrypt, legacy: "$2b$04$p4wSFWnH/3cP53yyCWSnEu"},
+		{name: "bcryptBadCost", inner: InnerBcrypt, legacy: "$2b$99$p4wSFWnH/3cP53yyCWSnEuxvwVcro/yOPi/Vi6hez5ZvWA8q1d7WO"},
+		{name: "bcryptExcessiveCost", inner: InnerBcrypt, legacy: "$2b$17$p4wSFWnH/3cP53yyCWSnEuxvwVcro/yOPi/Vi6hez5ZvWA8q1d7WO"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := newTestWrapper().Wrap(tt.inner, tt.legacy)
+			require.Error(t, err)
+		})
+	}
+}
+
+func TestWrapper_VerifyUnknownInner(t *testing.T) {
+	wrapper := newTestWrapper()
+
+	wrapped, err := wrapper.Wrap(InnerMD5, "5f4dcc3b5aa765d61d8327deb882cf99")
+	require.NoError(t, err)
+
+	_, err = wrapper.Verify([]byte("password"), strings.Replace(wrapped, "i=md5", "i=md4", 1))
+	require.Error(t, err)
+}
+
+func TestWrapper_OuterFormats(t *testing.T) {
+	formats := map[string]argon2.Format{
+		"php":      argon2.FormatPHP,
+		"django":   argon2.FormatDjango,
+		"spring":   argon2.FormatSpring,
+		"dovecot":  argon2.FormatDovecot,
+		"openldap": argon2.FormatOpenLDAP,
+	}
+
+	for name, format := range formats {
+		t.Run(name, func(t *testing.T) {
+			wrapper := newTestWrapper()
+			wrapper.Outer.Format = format
+
+			wrapped, err := wrapper.Wrap(InnerMD5, "5f4dcc3b5aa765d61d8327deb882cf99")
+			require.NoError(t, err)
+			require.True(t, strings.HasPrefix(wrapped, "$argon2id-wrap$v=19$"))
+
+			ok, err := wrapper.Verify([]byte("password"), wrapped)
+			require.NoError(t, err)
+			require.True(t, ok)
+			require.Equal(t, format, wrapper.Outer.Format)
+		})
+	}
+}
+
+func TestWrapper_IgnoresOuterNormalization(t *testing.T) {
+	for _, normalization := range []argon2.Normalization{argon2.NormalizationNFKC, argon2.NormalizationOpaqueString} {
+		wrapper := newTestWrapper()
+		wrapper.Outer.Normalization = normalization
+
+		// The SHA-256 digest of "password" holds bytes that OpaqueString
+		// rejects and NFKC would rewrite.
+		wrapped, err := wrapper.Wrap(InnerSHA256, "5e884898da28047151d0e56f8dc6292773603d0d6aabbdd62a11ef721d1542d8")
+		require.NoError(t, err)
+		require.NotContains(t, wrapped, "n=")
+
+		ok, err := wrapper.Verify([]byte("password"), wrapped)
+		require.NoError(t, err)
+		require.True(t, ok)
+
+		ok, err = newTestWrapper().Verify([]byte("password"), wrapped)
+		require.NoError(t, err)
+		require.True(t, ok)
+	}
+}